@@ -0,0 +1,33 @@
+// Command attackimport loads the MITRE ATT&CK Enterprise STIX bundle into the attack collection. Run it from the
+// backend directory so the .env file is found:
+//
+//	go run ./cmd/attackimport enterprise-attack.json
+//
+// Importing a newer release updates the stored tactics, techniques and mitigations in place.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"user-athentication-golang/controllers"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: attackimport enterprise-attack.json")
+	}
+
+	controllers.EnsureAttackIndex()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	count, version, err := controllers.ImportAttackBundle(ctx, os.Args[1])
+	if err != nil {
+		log.Fatalf("%s: %v", os.Args[1], err)
+	}
+	log.Printf("%s: imported %d entries of ATT&CK %s", os.Args[1], count, version)
+}
@@ -0,0 +1,36 @@
+// Command nvdimport loads NVD CVE JSON 2.0 feed files into the cve collection. Run it from the backend directory
+// so the .env file is found, yearly feeds first and the "modified" feed afterwards:
+//
+//	go run ./cmd/nvdimport nvdcve-2.0-2024.json.gz nvdcve-2.0-modified.json.gz
+//
+// Records already stored with the same or a later last-modified date are left alone, so the modified feed can
+// be imported on a schedule without reloading the yearly ones.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"user-athentication-golang/controllers"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("usage: nvdimport FEED.json[.gz] ...")
+	}
+
+	controllers.EnsureCVEIndex()
+
+	for _, path := range os.Args[1:] {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+		stats, err := controllers.ImportCVEFeed(ctx, path)
+		cancel()
+		if err != nil {
+			log.Fatalf("%s: %v (after %d records)", path, err, stats.Read)
+		}
+		log.Printf("%s: read %d, inserted %d, updated %d, unchanged %d, skipped %d",
+			path, stats.Read, stats.Inserted, stats.Updated, stats.Unchanged, stats.Skipped)
+	}
+}
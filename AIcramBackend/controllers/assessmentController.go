@@ -188,6 +188,7 @@ func CreateAssessment() gin.HandlerFunc {
 			assessment.User_id = &userID
 		}
 
+		assessment.Matrix_version = nil
 		if assessment.Matrix_id != nil && *assessment.Matrix_id != "" {
 			var matrix models.Matrix
 			errMatrix := matrixCollection.FindOne(context.TODO(), bson.M{"matrix_id": assessment.Matrix_id}).Decode(&matrix)
@@ -200,6 +201,29 @@ func CreateAssessment() gin.HandlerFunc {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "matrix_error"})
 				return
 			}
+			matrixVersion, errVersion := recordMatrixVersion(ctx, matrix, matrix.User_id)
+			if errVersion != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "matrix_error"})
+				return
+			}
+			assessment.Matrix_version = &matrixVersion
+		}
+
+		if len(assessment.Asset_id) > 0 && !checkAssetIds(ctx, assessment.Asset_id, *assessment.User_id, assessment.Organization_id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "asset_error"})
+			return
+		}
+
+		if len(assessment.File) > 0 {
+			fileFilter := bson.M{"file_id": bson.M{"$in": assessment.File}}
+			if userType != "ADMIN" {
+				fileFilter["user_id"] = *assessment.User_id
+			}
+			fileCount, errFile := fileCollection.CountDocuments(ctx, fileFilter)
+			if errFile != nil || int(fileCount) != len(assessment.File) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "file_error"})
+				return
+			}
 		}
 
 		if assessment.Organization_id != nil && *assessment.Organization_id != "" {
@@ -278,6 +302,7 @@ func UpdateAssessment() gin.HandlerFunc {
 			return
 		}
 
+		updateData.Matrix_version = nil
 		if updateData.Matrix_id != nil && *updateData.Matrix_id != "" {
 			var matrix models.Matrix
 			errMatrix := matrixCollection.FindOne(context.TODO(), bson.M{"matrix_id": updateData.Matrix_id}).Decode(&matrix)
@@ -290,6 +315,39 @@ func UpdateAssessment() gin.HandlerFunc {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "matrix_error"})
 				return
 			}
+			matrixVersion, errVersion := recordMatrixVersion(ctx, matrix, matrix.User_id)
+			if errVersion != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "matrix_error"})
+				return
+			}
+			updateData.Matrix_version = &matrixVersion
+		}
+
+		if updateData.Asset_id != nil || updateData.Organization_id != nil {
+			assetIds := existingAssessment.Asset_id
+			if updateData.Asset_id != nil {
+				assetIds = updateData.Asset_id
+			}
+			organizationId := existingAssessment.Organization_id
+			if updateData.Organization_id != nil {
+				organizationId = updateData.Organization_id
+			}
+			if len(assetIds) > 0 && !checkAssetIds(ctx, assetIds, *existingAssessment.User_id, organizationId) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "asset_error"})
+				return
+			}
+		}
+
+		if len(updateData.File) > 0 {
+			fileFilter := bson.M{"file_id": bson.M{"$in": updateData.File}}
+			if userType != "ADMIN" {
+				fileFilter["user_id"] = *existingAssessment.User_id
+			}
+			fileCount, errFile := fileCollection.CountDocuments(ctx, fileFilter)
+			if errFile != nil || int(fileCount) != len(updateData.File) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "file_error"})
+				return
+			}
 		}
 
 		if updateData.Organization_id != nil && *updateData.Organization_id != "" {
@@ -321,6 +379,7 @@ func UpdateAssessment() gin.HandlerFunc {
 		}
 		if updateData.Matrix_id != nil {
 			update["matrix_id"] = updateData.Matrix_id
+			update["matrix_version"] = updateData.Matrix_version
 		}
 		if updateData.Organization_id != nil {
 			update["organization_id"] = updateData.Organization_id
@@ -334,6 +393,12 @@ func UpdateAssessment() gin.HandlerFunc {
 		if updateData.Threat != nil {
 			update["threat"] = updateData.Threat
 		}
+		if updateData.Asset_id != nil {
+			update["asset_id"] = updateData.Asset_id
+		}
+		if updateData.File != nil {
+			update["file"] = updateData.File
+		}
 		if updateData.Constraint != nil {
 			update["constraint"] = updateData.Constraint
 		}
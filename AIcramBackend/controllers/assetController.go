@@ -0,0 +1,750 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"user-athentication-golang/database"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var assetCollection *mongo.Collection = database.OpenCollection(database.Client, "asset")
+var assetValidate = validator.New()
+
+const maxAssetImport = 1000
+
+// moveOrganizationAssets moves the assets embedded in organization into the asset collection and clears the
+// embedded list. Embedded assets only had a name, value and criticality, so they become active system assets; names
+// the organization's inventory already has are not added twice.
+func moveOrganizationAssets(ctx context.Context, organization models.Organization) (int, error) {
+	names, err := assetCollection.Distinct(ctx, "name", bson.M{"organization_id": organization.Organization_id})
+	if err != nil {
+		return 0, err
+	}
+	existing := map[string]bool{}
+	for _, name := range names {
+		if text, ok := name.(string); ok {
+			existing[text] = true
+		}
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	assets := []interface{}{}
+	for i, embedded := range organization.Asset {
+		if embedded == nil {
+			continue
+		}
+		runes := []rune(strings.TrimSpace(textValue(embedded.Name)))
+		if len(runes) < 2 {
+			runes = []rune(fmt.Sprintf("Asset %d", i+1))
+		}
+		if len(runes) > 100 {
+			runes = runes[:100]
+		}
+		name := string(runes)
+		if existing[name] {
+			continue
+		}
+		existing[name] = true
+
+		status := 1
+		assetType := "system"
+		organizationId := organization.Organization_id
+		asset := models.Asset{
+			ID:              primitive.NewObjectID(),
+			User_id:         organization.User_id,
+			Organization_id: &organizationId,
+			Name:            &name,
+			Status:          &status,
+			Type:            &assetType,
+			Created_at:      now,
+			Updated_at:      now,
+		}
+		asset.Asset_id = asset.ID.Hex()
+		if embedded.Value != nil && *embedded.Value >= 0 {
+			asset.Value = embedded.Value
+		}
+		if embedded.Criticality != nil && *embedded.Criticality >= 1 && *embedded.Criticality <= 5 {
+			asset.Criticality = embedded.Criticality
+		}
+		assets = append(assets, asset)
+	}
+
+	if len(assets) > 0 {
+		if _, err := assetCollection.InsertMany(ctx, assets); err != nil {
+			return 0, err
+		}
+	}
+	_, err = organizationCollection.UpdateOne(ctx, bson.M{"organization_id": organization.Organization_id}, bson.M{"$unset": bson.M{"asset": ""}})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(assets), nil
+}
+
+// MigrateOrganizationAssets moves the assets still embedded in organizations into the asset collection.
+func MigrateOrganizationAssets() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	cursor, err := organizationCollection.Find(ctx, bson.M{"asset.0": bson.M{"$exists": true}})
+	if err != nil {
+		log.Printf("Failed to migrate organization assets: %v", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var organization models.Organization
+		if err := cursor.Decode(&organization); err != nil {
+			log.Printf("Failed to migrate organization assets: %v", err)
+			continue
+		}
+		moved, err := moveOrganizationAssets(ctx, organization)
+		if err != nil {
+			log.Printf("Failed to migrate assets of organization %s: %v", organization.Organization_id, err)
+			continue
+		}
+		migrated += moved
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated %d embedded organization assets to the asset collection", migrated)
+	}
+}
+
+func GetAssets() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
+		if err != nil || recordPerPage < 1 {
+			recordPerPage = 10
+		}
+
+		page, err1 := strconv.Atoi(c.Query("page"))
+		if err1 != nil || page < 1 {
+			page = 1
+		}
+
+		startIndex := (page - 1) * recordPerPage
+		if queryStartIndex, err := strconv.Atoi(c.Query("startIndex")); err == nil && queryStartIndex >= 0 {
+			startIndex = queryStartIndex
+		}
+
+		userId := c.GetString("uid")
+		userType := c.GetString("user_type")
+
+		matchCriteria := bson.D{}
+
+		if userType != "ADMIN" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "user_id", Value: userId}, bson.E{Key: "status", Value: 1})
+		} else if queryUserId := c.Query("user_id"); queryUserId != "" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "user_id", Value: queryUserId})
+		}
+
+		if organizationId := c.Query("organization_id"); organizationId != "" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "organization_id", Value: organizationId})
+		}
+		if assetType := c.Query("type"); assetType != "" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "type", Value: assetType})
+		}
+		if tag := c.Query("tag"); tag != "" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "tag", Value: tag})
+		}
+		if owner := c.Query("owner"); owner != "" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "owner", Value: owner})
+		}
+		if search := strings.TrimSpace(c.Query("search")); search != "" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "name", Value: primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}})
+		}
+
+		pipeline := mongo.Pipeline{
+			bson.D{{Key: "$match", Value: matchCriteria}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}}},
+			bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: nil}, {Key: "total_count", Value: bson.M{"$sum": 1}}, {Key: "data", Value: bson.M{"$push": "$$ROOT"}}}}},
+			bson.D{{Key: "$project", Value: bson.D{
+				{Key: "_id", Value: 0},
+				{Key: "total_count", Value: 1},
+				{Key: "asset_items", Value: bson.M{"$slice": bson.A{"$data", startIndex, recordPerPage}}},
+			}}},
+		}
+
+		result, err := assetCollection.Aggregate(ctx, pipeline)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing asset items"})
+			return
+		}
+
+		var allassets []bson.M
+		if err = result.All(ctx, &allassets); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing asset items"})
+			return
+		}
+
+		if len(allassets) == 0 {
+			c.JSON(http.StatusOK, gin.H{
+				"total_count": 0,
+				"asset_items": []bson.M{},
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, allassets[0])
+	}
+}
+
+func GetAsset() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		assetId := c.Param("asset_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var asset models.Asset
+		err := assetCollection.FindOne(ctx, bson.M{"asset_id": assetId}).Decode(&asset)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "asset not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching asset"})
+			return
+		}
+
+		if c.GetString("user_type") != "ADMIN" {
+			if asset.User_id == nil || (*asset.User_id != c.GetString("uid") && c.Query("transaction") != "true") || (asset.Status != nil && *asset.Status != 1) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this asset"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, asset)
+	}
+}
+
+// assetOrganization checks that organizationId exists and, for regular users, belongs to userId.
+func assetOrganization(ctx context.Context, organizationId *string, userId string, userType string) (*models.Organization, bool) {
+	if organizationId == nil || *organizationId == "" {
+		return nil, false
+	}
+
+	var organization models.Organization
+	if err := organizationCollection.FindOne(ctx, bson.M{"organization_id": organizationId}).Decode(&organization); err != nil {
+		return nil, false
+	}
+	if organization.User_id == nil {
+		return nil, false
+	}
+	if userType != "ADMIN" && (*organization.User_id != userId || (organization.Status != nil && *organization.Status != 1)) {
+		return nil, false
+	}
+
+	return &organization, true
+}
+
+func uniqueIds(ids []*string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, id := range ids {
+		if id == nil || *id == "" || seen[*id] {
+			continue
+		}
+		seen[*id] = true
+		unique = append(unique, *id)
+	}
+
+	return unique
+}
+
+// checkAssetIds reports whether every id is an active asset owned by userId, within organizationId when one is given.
+func checkAssetIds(ctx context.Context, ids []*string, userId string, organizationId *string) bool {
+	unique := uniqueIds(ids)
+	if len(unique) != len(ids) {
+		return false
+	}
+	if len(unique) == 0 {
+		return true
+	}
+
+	filter := bson.M{"asset_id": bson.M{"$in": unique}, "user_id": userId, "status": 1}
+	if organizationId != nil && *organizationId != "" {
+		filter["organization_id"] = *organizationId
+	}
+
+	count, err := assetCollection.CountDocuments(ctx, filter)
+
+	return err == nil && int(count) == len(unique)
+}
+
+func CreateAsset() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var asset models.Asset
+
+		if err := c.BindJSON(&asset); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := assetValidate.Struct(asset)
+		if validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		cpe, invalid := normalizeAssetCPE(asset.CPE)
+		if len(invalid) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cpe_error", "detail": invalid})
+			return
+		}
+		asset.CPE = cpe
+
+		userType := c.GetString("user_type")
+
+		organization, ok := assetOrganization(ctx, asset.Organization_id, c.GetString("uid"), userType)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "organization_error"})
+			return
+		}
+		// Assets belong to the owner of their organization so admins can create them on a user's behalf.
+		asset.User_id = organization.User_id
+
+		if !checkAssetIds(ctx, asset.Dependency, *asset.User_id, asset.Organization_id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dependency_error"})
+			return
+		}
+
+		asset.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		asset.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		asset.ID = primitive.NewObjectID()
+		asset.Asset_id = asset.ID.Hex()
+
+		_, insertErr := assetCollection.InsertOne(ctx, asset)
+		if insertErr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create asset"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"asset_id": asset.Asset_id})
+	}
+}
+
+func UpdateAsset() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		assetId := c.Param("asset_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var existingAsset models.Asset
+		err := assetCollection.FindOne(ctx, bson.M{"asset_id": assetId}).Decode(&existingAsset)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "asset not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching asset"})
+			return
+		}
+
+		userType := c.GetString("user_type")
+		userId := c.GetString("uid")
+
+		if userType != "ADMIN" {
+			if existingAsset.User_id == nil || *existingAsset.User_id != userId || (existingAsset.Status != nil && *existingAsset.Status != 1) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to update this asset"})
+				return
+			}
+		}
+
+		var updateData models.Asset
+		if err := c.BindJSON(&updateData); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if userType != "ADMIN" {
+			updateData.Status = nil
+		}
+
+		merged := existingAsset
+		update := bson.M{}
+
+		if updateData.Organization_id != nil {
+			organization, ok := assetOrganization(ctx, updateData.Organization_id, userId, userType)
+			if !ok || organization.User_id == nil || existingAsset.User_id == nil || *organization.User_id != *existingAsset.User_id {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "organization_error"})
+				return
+			}
+			update["organization_id"] = updateData.Organization_id
+			merged.Organization_id = updateData.Organization_id
+		}
+		if updateData.Name != nil {
+			update["name"] = updateData.Name
+			merged.Name = updateData.Name
+		}
+		if updateData.Status != nil {
+			update["status"] = updateData.Status
+			merged.Status = updateData.Status
+		}
+		if updateData.Type != nil {
+			update["type"] = updateData.Type
+			merged.Type = updateData.Type
+		}
+		if updateData.Description != nil {
+			update["description"] = updateData.Description
+			merged.Description = updateData.Description
+		}
+		if updateData.Owner != nil {
+			update["owner"] = updateData.Owner
+			merged.Owner = updateData.Owner
+		}
+		if updateData.Location != nil {
+			update["location"] = updateData.Location
+			merged.Location = updateData.Location
+		}
+		if updateData.Value != nil {
+			update["value"] = updateData.Value
+			merged.Value = updateData.Value
+		}
+		if updateData.Criticality != nil {
+			update["criticality"] = updateData.Criticality
+			merged.Criticality = updateData.Criticality
+		}
+		if updateData.Confidentiality != nil {
+			update["confidentiality"] = updateData.Confidentiality
+			merged.Confidentiality = updateData.Confidentiality
+		}
+		if updateData.Integrity != nil {
+			update["integrity"] = updateData.Integrity
+			merged.Integrity = updateData.Integrity
+		}
+		if updateData.Availability != nil {
+			update["availability"] = updateData.Availability
+			merged.Availability = updateData.Availability
+		}
+		if updateData.Dependency != nil {
+			update["dependency"] = updateData.Dependency
+			merged.Dependency = updateData.Dependency
+		}
+		if updateData.Tag != nil {
+			update["tag"] = updateData.Tag
+			merged.Tag = updateData.Tag
+		}
+		if updateData.CPE != nil {
+			cpe, invalid := normalizeAssetCPE(updateData.CPE)
+			if len(invalid) > 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "cpe_error", "detail": invalid})
+				return
+			}
+			update["cpe"] = cpe
+			merged.CPE = cpe
+		}
+		if updateData.Version != nil {
+			update["version"] = updateData.Version
+			merged.Version = updateData.Version
+		}
+
+		if validationErr := assetValidate.Struct(merged); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		if updateData.Dependency != nil || updateData.Organization_id != nil {
+			for _, dependency := range merged.Dependency {
+				if dependency != nil && *dependency == assetId {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "dependency_error"})
+					return
+				}
+			}
+			if !checkAssetIds(ctx, merged.Dependency, *existingAsset.User_id, merged.Organization_id) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "dependency_error"})
+				return
+			}
+			cycle, err := dependencyCycle(ctx, *merged.Organization_id, map[string][]*string{assetId: merged.Dependency})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking dependencies"})
+				return
+			}
+			if cycle != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "dependency_cycle", "cycle": cycle})
+				return
+			}
+		}
+
+		update["updated_at"] = time.Now().Format(time.RFC3339)
+
+		result, err := assetCollection.UpdateOne(
+			ctx,
+			bson.M{"asset_id": assetId},
+			bson.M{"$set": update},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update asset"})
+			return
+		}
+
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "asset not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, result.ModifiedCount)
+	}
+}
+
+func DeleteAsset() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := helper.CheckUserType(c, "ADMIN"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		assetId := c.Param("asset_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		// Drop the id from everything that points at it first, so a failed cleanup leaves the asset in place
+		// instead of a dangling reference.
+		references := []struct {
+			Collection *mongo.Collection
+			Field      string
+			Pull       string
+		}{
+			{assetCollection, "dependency", "dependency"},
+			{assessmentCollection, "asset_id", "asset_id"},
+			{resultCollection, "content.vulnerability.asset_id", "content.vulnerability.$[].asset_id"},
+		}
+		for _, reference := range references {
+			_, err := reference.Collection.UpdateMany(ctx, bson.M{reference.Field: assetId}, bson.M{"$pull": bson.M{reference.Pull: assetId}})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete asset"})
+				return
+			}
+		}
+
+		result, err := assetCollection.DeleteOne(ctx, bson.M{"asset_id": assetId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete asset"})
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func RemoveAsset() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		assetId := c.Param("asset_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var existingAsset models.Asset
+		err := assetCollection.FindOne(ctx, bson.M{"asset_id": assetId}).Decode(&existingAsset)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "asset not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching asset"})
+			return
+		}
+
+		if c.GetString("user_type") != "ADMIN" {
+			if existingAsset.User_id == nil || *existingAsset.User_id != c.GetString("uid") || (existingAsset.Status != nil && *existingAsset.Status != 1) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to remove this asset"})
+				return
+			}
+		}
+
+		status := 2
+		update := bson.M{
+			"status":     status,
+			"updated_at": time.Now().Format(time.RFC3339),
+		}
+
+		result, err := assetCollection.UpdateOne(
+			ctx,
+			bson.M{"asset_id": assetId},
+			bson.M{"$set": update},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove asset"})
+			return
+		}
+
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "asset not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, result.ModifiedCount)
+	}
+}
+
+// ImportAssets creates many assets of one organization at once. A dependency may name an existing asset id or
+// another asset of the same import by name. Nothing is inserted unless every item is valid.
+func ImportAssets() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var importData struct {
+			Organization_id *string         `json:"organization_id"`
+			Asset_items     []*models.Asset `json:"asset_items"`
+		}
+		if err := c.BindJSON(&importData); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if len(importData.Asset_items) == 0 || len(importData.Asset_items) > maxAssetImport {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("asset_items must contain between 1 and %d assets", maxAssetImport)})
+			return
+		}
+
+		organization, ok := assetOrganization(ctx, importData.Organization_id, c.GetString("uid"), c.GetString("user_type"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "organization_error"})
+			return
+		}
+
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		byName := map[string]string{}
+		for _, asset := range importData.Asset_items {
+			if asset == nil {
+				continue
+			}
+			asset.ID = primitive.NewObjectID()
+			asset.Asset_id = asset.ID.Hex()
+			asset.User_id = organization.User_id
+			asset.Organization_id = &organization.Organization_id
+			if asset.Status == nil {
+				status := 1
+				asset.Status = &status
+			}
+			asset.Created_at = now
+			asset.Updated_at = now
+			if asset.Name != nil {
+				byName[*asset.Name] = asset.Asset_id
+			}
+		}
+
+		importErrors := []gin.H{}
+		documents := []interface{}{}
+		for i, asset := range importData.Asset_items {
+			if asset == nil {
+				importErrors = append(importErrors, gin.H{"index": i, "error": "asset is required"})
+				continue
+			}
+
+			if validationErr := assetValidate.Struct(asset); validationErr != nil {
+				importErrors = append(importErrors, gin.H{"index": i, "error": validationErr.Error()})
+				continue
+			}
+
+			existing := []*string{}
+			for j, dependency := range asset.Dependency {
+				if dependency == nil {
+					continue
+				}
+				if id, ok := byName[*dependency]; ok {
+					if id == asset.Asset_id {
+						existing = append(existing, dependency)
+						break
+					}
+					asset.Dependency[j] = &id
+					continue
+				}
+				existing = append(existing, dependency)
+			}
+			if !checkAssetIds(ctx, existing, *organization.User_id, &organization.Organization_id) {
+				importErrors = append(importErrors, gin.H{"index": i, "error": "dependency_error"})
+				continue
+			}
+
+			documents = append(documents, asset)
+		}
+
+		if len(importErrors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "import_error", "errors": importErrors})
+			return
+		}
+
+		changes := map[string][]*string{}
+		for _, asset := range importData.Asset_items {
+			changes[asset.Asset_id] = asset.Dependency
+		}
+		cycle, err := dependencyCycle(ctx, organization.Organization_id, changes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking dependencies"})
+			return
+		}
+		if cycle != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dependency_cycle", "cycle": cycle})
+			return
+		}
+
+		if _, err := assetCollection.InsertMany(ctx, documents); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import assets"})
+			return
+		}
+
+		assetIds := []string{}
+		for _, asset := range importData.Asset_items {
+			assetIds = append(assetIds, asset.Asset_id)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"inserted_count": len(assetIds),
+			"asset_ids":      assetIds,
+		})
+	}
+}
+
+// checkVulnerabilityAssets validates the asset ids linked from every vulnerability of a result.
+func checkVulnerabilityAssets(ctx context.Context, content *models.Content, userId string) bool {
+	if content == nil {
+		return true
+	}
+
+	assetIds := []*string{}
+	for _, vulnerability := range content.Vulnerability {
+		if vulnerability == nil {
+			continue
+		}
+		for _, assetId := range vulnerability.Asset_id {
+			if assetId == nil || *assetId == "" {
+				return false
+			}
+		}
+		assetIds = append(assetIds, vulnerability.Asset_id...)
+	}
+
+	ids := []*string{}
+	for _, assetId := range uniqueIds(assetIds) {
+		id := assetId
+		ids = append(ids, &id)
+	}
+
+	return checkAssetIds(ctx, ids, userId, nil)
+}
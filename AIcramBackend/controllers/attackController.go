@@ -0,0 +1,374 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/database"
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var attackCollection *mongo.Collection = database.OpenCollection(database.Client, "attack")
+
+// attackIdLike matches a word written like an ATT&CK ID, whether or not it is a valid one.
+var attackIdLike = regexp.MustCompile(`^(?:TA|T|M)\d[\d.]*$`)
+
+const navigatorVersion = "4.9.1"
+const navigatorLayerVersion = "4.5"
+
+type navigatorTechnique struct {
+	Technique_id       string `json:"techniqueID"`
+	Score              *int   `json:"score,omitempty"`
+	Comment            string `json:"comment,omitempty"`
+	Enabled            bool   `json:"enabled"`
+	Show_subtechniques bool   `json:"showSubtechniques"`
+}
+
+func EnsureAttackIndex() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	unique := true
+	_, err := attackCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "attack_id", Value: 1}},
+		Options: &options.IndexOptions{Unique: &unique},
+	})
+	if err != nil {
+		log.Printf("Failed to create attack index: %v", err)
+	}
+}
+
+// ImportAttackBundle loads the tactics, techniques and mitigations of an ATT&CK STIX bundle file into the attack
+// collection, replacing the stored entries with the same IDs. It returns the number of entries and the dataset version.
+func ImportAttackBundle(ctx context.Context, path string) (int, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	dataset, err := helper.ReadAttackBundle(file)
+	if err != nil {
+		return 0, "", err
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	writes := []mongo.WriteModel{}
+	for _, entry := range dataset.Entries {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"attack_id": entry.ID}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"type":        entry.Type,
+					"name":        entry.Name,
+					"description": optionalText(entry.Description),
+					"url":         optionalText(entry.URL),
+					"shortname":   optionalText(entry.Shortname),
+					"parent":      optionalText(entry.Parent),
+					"tactic":      seedStrings(entry.Tactic),
+					"mitigation":  seedStrings(entry.Mitigation),
+					"platform":    seedStrings(entry.Platform),
+					"deprecated":  entry.Deprecated,
+					"version":     optionalText(dataset.Version),
+					"updated_at":  now,
+				},
+				"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "created_at": now},
+			}).
+			SetUpsert(true))
+	}
+	if len(writes) == 0 {
+		return 0, dataset.Version, nil
+	}
+
+	if _, err := attackCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return 0, dataset.Version, err
+	}
+
+	return len(writes), dataset.Version, nil
+}
+
+// attackIds extracts the distinct ATT&CK IDs written in MITRE references such as "T1190 Exploit Public-Facing Application".
+func attackIds(values []*string) []string {
+	ids := []string{}
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		for _, id := range helper.AttackIDPattern.FindAllString(strings.ToUpper(*value), -1) {
+			if !helper.ContainsValue(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+func contentAttackIds(content *models.Content) []string {
+	ids := []string{}
+	if content == nil {
+		return ids
+	}
+	for _, vulnerability := range content.Vulnerability {
+		if vulnerability == nil {
+			continue
+		}
+		for _, id := range attackIds(vulnerability.MITRE) {
+			if !helper.ContainsValue(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+func loadAttacks(ctx context.Context, ids []string) (map[string]models.Attack, error) {
+	found := map[string]models.Attack{}
+	if len(ids) == 0 {
+		return found, nil
+	}
+
+	cursor, err := attackCollection.Find(ctx, bson.M{"attack_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var items []models.Attack
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		found[item.Attack_id] = item
+	}
+
+	return found, nil
+}
+
+// malformedAttackIds returns the MITRE references of the content that start with something written like an
+// ATT&CK ID, such as "T119" or "T1059.1", that is not a valid one. Well-formed IDs missing from the dataset are
+// accepted; resultAttackItems reports them as unknown.
+func malformedAttackIds(content *models.Content) []string {
+	malformed := []string{}
+	if content == nil {
+		return malformed
+	}
+	for _, vulnerability := range content.Vulnerability {
+		if vulnerability == nil {
+			continue
+		}
+		for _, value := range vulnerability.MITRE {
+			if value == nil {
+				continue
+			}
+			fields := strings.Fields(strings.ToUpper(*value))
+			if len(fields) == 0 {
+				continue
+			}
+			id := strings.TrimRight(fields[0], ":,;")
+			if attackIdLike.MatchString(id) && helper.AttackIDPattern.FindString(id) != id && !helper.ContainsValue(malformed, *value) {
+				malformed = append(malformed, *value)
+			}
+		}
+	}
+
+	return malformed
+}
+
+// resultAttackItems describes every ATT&CK ID the result references with its name, parent and tactics. IDs that
+// are not in the dataset are listed with known set to false.
+func resultAttackItems(ctx context.Context, content *models.Content) ([]gin.H, error) {
+	ids := contentAttackIds(content)
+	found, err := loadAttacks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	tacticIds := []string{}
+	for _, attack := range found {
+		for _, tactic := range stringValues(attack.Tactic) {
+			if !helper.ContainsValue(tacticIds, tactic) {
+				tacticIds = append(tacticIds, tactic)
+			}
+		}
+	}
+	tactics, err := loadAttacks(ctx, tacticIds)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []gin.H{}
+	for _, id := range ids {
+		attack, ok := found[id]
+		if !ok {
+			items = append(items, gin.H{"attack_id": id, "known": false})
+			continue
+		}
+
+		tacticItems := []gin.H{}
+		for _, tacticId := range stringValues(attack.Tactic) {
+			if tactic, ok := tactics[tacticId]; ok {
+				tacticItems = append(tacticItems, gin.H{"attack_id": tactic.Attack_id, "name": tactic.Name, "shortname": tactic.Shortname})
+			}
+		}
+		items = append(items, gin.H{
+			"attack_id":    attack.Attack_id,
+			"known":        true,
+			"type":         attack.Type,
+			"name":         attack.Name,
+			"url":          attack.Url,
+			"parent":       attack.Parent,
+			"deprecated":   attack.Deprecated,
+			"tactic_items": tacticItems,
+		})
+	}
+
+	return items, nil
+}
+
+// attackLayer builds an ATT&CK Navigator layer scoring each referenced technique with the highest inherent risk
+// of the vulnerabilities that use it: impact × likelihood as a percentage of the largest product on the matrix
+// the result was scored with. Vulnerabilities without a rated position leave the technique unscored.
+func attackLayer(ctx context.Context, name string, description string, results []models.Result, metadata []gin.H) gin.H {
+	scores := map[string]int{}
+	comments := map[string][]string{}
+	for _, result := range results {
+		if result.Content == nil {
+			continue
+		}
+		var matrix *models.Matrix
+		if matrixVersion, _, _, err := resultMatrixVersion(ctx, result); err == nil {
+			matrix = matrixVersion.Matrix
+		}
+		for i, vulnerability := range result.Content.Vulnerability {
+			if vulnerability == nil {
+				continue
+			}
+			score := positionScore(matrix, vulnerability.Impact, vulnerability.Likelihood)
+			label := textValue(vulnerability.Name)
+			if label == "" {
+				label = "Vulnerability " + strconv.Itoa(i+1)
+			}
+			for _, id := range attackIds(vulnerability.MITRE) {
+				if strings.HasPrefix(id, "TA") || strings.HasPrefix(id, "M") {
+					continue
+				}
+				if current, ok := scores[id]; score != nil && (!ok || *score > current) {
+					scores[id] = *score
+				}
+				if !helper.ContainsValue(comments[id], label) {
+					comments[id] = append(comments[id], label)
+				}
+			}
+		}
+	}
+
+	// Sub-techniques are only shown when their parent is expanded, so parents are listed even without a score.
+	expand := map[string]bool{}
+	ids := []string{}
+	for id := range comments {
+		ids = append(ids, id)
+		if dot := strings.Index(id, "."); dot > 0 {
+			expand[id[:dot]] = true
+		}
+	}
+	for parent := range expand {
+		if _, ok := comments[parent]; !ok {
+			ids = append(ids, parent)
+		}
+	}
+	sort.Strings(ids)
+
+	techniques := []navigatorTechnique{}
+	for _, id := range ids {
+		technique := navigatorTechnique{Technique_id: id, Comment: strings.Join(comments[id], "; "), Enabled: true, Show_subtechniques: expand[id]}
+		if score, ok := scores[id]; ok {
+			technique.Score = &score
+		}
+		techniques = append(techniques, technique)
+	}
+
+	versions := gin.H{"navigator": navigatorVersion, "layer": navigatorLayerVersion}
+	var latest models.Attack
+	if err := attackCollection.FindOne(ctx, bson.M{"version": bson.M{"$ne": nil}}).Decode(&latest); err == nil && latest.Version != nil {
+		versions["attack"] = strings.Split(*latest.Version, ".")[0]
+	}
+
+	return gin.H{
+		"name":        name,
+		"description": description,
+		"domain":      "enterprise-attack",
+		"versions":    versions,
+		"sorting":     3,
+		"layout":      gin.H{"layout": "side", "aggregateFunction": "max", "showID": true, "showName": true},
+		"techniques":  techniques,
+		"gradient":    gin.H{"colors": []string{"#ffe766ff", "#ff6666ff"}, "minValue": 0, "maxValue": 100},
+		"legendItems": []gin.H{},
+		"metadata":    metadata,
+
+		"hideDisabled":                  false,
+		"selectTechniquesAcrossTactics": true,
+		"selectSubtechniquesWithParent": false,
+	}
+}
+
+func GetResultAttackLayer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		report, ok := loadResultReport(c, ctx)
+		if !ok {
+			return
+		}
+
+		layer := attackLayer(ctx, report.Title(), "Techniques referenced by result "+report.Result.Result_id,
+			[]models.Result{report.Result}, []gin.H{{"name": "result_id", "value": report.Result.Result_id}})
+		c.Header("Content-Disposition", "attachment; filename=\"result-"+report.Result.Result_id+"-layer.json\"")
+		c.JSON(http.StatusOK, layer)
+	}
+}
+
+// GetOrganizationAttackLayer covers the latest result of each of the organization's assessments.
+func GetOrganizationAttackLayer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organizationId := c.Param("organization_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		organization, ok := assetOrganization(ctx, &organizationId, c.GetString("uid"), c.GetString("user_type"))
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this organization"})
+			return
+		}
+
+		results, err := latestOrganizationResults(ctx, organizationId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing result items"})
+			return
+		}
+
+		name := textValue(organization.Name)
+		if name == "" {
+			name = organizationId
+		}
+		layer := attackLayer(ctx, name, "Techniques referenced by the latest result of each assessment of "+name,
+			results, []gin.H{{"name": "organization_id", "value": organizationId}})
+		c.Header("Content-Disposition", "attachment; filename=\"organization-"+organizationId+"-layer.json\"")
+		c.JSON(http.StatusOK, layer)
+	}
+}
@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"user-athentication-golang/models"
+)
+
+func TestMalformedAttackIds(t *testing.T) {
+	text := func(value string) *string { return &value }
+
+	tests := []struct {
+		mitre []*string
+		want  []string
+	}{
+		{[]*string{text("T1190 Exploit Public-Facing Application"), text("t1059.001"), text("TA0001: Initial Access"), text("M1050")}, []string{}},
+		{[]*string{text("T9999 Not in the dataset")}, []string{}},
+		{[]*string{text("Phishing"), text("Microsoft 365 abuse"), nil, text("  ")}, []string{}},
+		{[]*string{text("T119 Exploit"), text("T1059.1"), text("TA01"), text("M10500")}, []string{"T119 Exploit", "T1059.1", "TA01", "M10500"}},
+	}
+
+	for _, tt := range tests {
+		content := &models.Content{Vulnerability: []*models.Vulnerability{{MITRE: tt.mitre}}}
+		if got := malformedAttackIds(content); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("malformedAttackIds(%v) = %q, want %q", tt.mitre, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxAvatarUploadSize int64 = 10 << 20
+const primaryAvatarSize = 256
+
+func findAvatarOwner(c *gin.Context, ctx context.Context) (*models.User, bool) {
+	userId := c.Param("user_id")
+
+	var existingUser models.User
+	err := userCollection.FindOne(ctx, bson.M{"user_id": userId}).Decode(&existingUser)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching user"})
+		return nil, false
+	}
+
+	if c.GetString("user_type") != "ADMIN" && existingUser.User_id != c.GetString("uid") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to update this user's avatar"})
+		return nil, false
+	}
+
+	return &existingUser, true
+}
+
+func removeStoredFile(ctx context.Context, storage helper.Storage, fileId string) error {
+	var file models.File
+	err := fileCollection.FindOne(ctx, bson.M{"file_id": fileId}).Decode(&file)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+
+	cloudIds := []string{}
+	if file.Cloud_id != "" {
+		cloudIds = append(cloudIds, file.Cloud_id)
+	}
+	for _, variant := range file.Variant {
+		if variant != nil && variant.Cloud_id != "" && variant.Cloud_id != file.Cloud_id {
+			cloudIds = append(cloudIds, variant.Cloud_id)
+		}
+	}
+
+	for _, cloudId := range cloudIds {
+		if err := storage.Delete(ctx, cloudId); err != nil {
+			log.Printf("Failed to delete stored object %s: %v", cloudId, err)
+		}
+	}
+
+	result, err := fileCollection.DeleteOne(ctx, bson.M{"file_id": fileId})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount > 0 && file.User_id != nil {
+		releaseStorage(ctx, *file.User_id, storedBytes(file))
+	}
+
+	return nil
+}
+
+// removeAvatarFile removes a user's previous avatar. Before avatars had their own pipeline image_id could point at
+// any uploaded file, so only files with avatar variants that belong to the user are removed.
+func removeAvatarFile(ctx context.Context, storage helper.Storage, fileId string, userId string) error {
+	var file models.File
+	err := fileCollection.FindOne(ctx, bson.M{"file_id": fileId}).Decode(&file)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+
+	if len(file.Variant) == 0 || file.User_id == nil || *file.User_id != userId {
+		return nil
+	}
+
+	return removeStoredFile(ctx, storage, fileId)
+}
+
+func UpdateAvatar() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		existingUser, ok := findAvatarOwner(c, ctx)
+		if !ok {
+			return
+		}
+
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+			return
+		}
+
+		if file.Size > maxAvatarUploadSize {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "avatar exceeds the maximum size"})
+			return
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+			return
+		}
+		defer src.Close()
+
+		data, err := io.ReadAll(io.LimitReader(src, maxAvatarUploadSize+1))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+			return
+		}
+
+		img, _, err := helper.DecodeAvatar(data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "image_error"})
+			return
+		}
+
+		variants, err := helper.BuildAvatarVariants(img)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process avatar"})
+			return
+		}
+
+		storage, err := helper.NewStorage()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to initialize storage"})
+			return
+		}
+
+		var totalBytes int64
+		for _, variant := range variants {
+			totalBytes += int64(len(variant.Data))
+		}
+
+		usage, err := reserveStorage(ctx, existingUser.User_id, c.GetString("user_type"), totalBytes)
+		if err == errQuotaExceeded {
+			quotaExceededResponse(c, usage, totalBytes)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking quota"})
+			return
+		}
+
+		baseName := strings.TrimSuffix(filepath.Base(file.Filename), filepath.Ext(file.Filename))
+		stored := []*models.FileVariant{}
+		var primary *models.FileVariant
+
+		for _, variant := range variants {
+			name := fmt.Sprintf("%s_%d.jpg", baseName, variant.Size)
+			object, err := storage.Put(ctx, name, bytes.NewReader(variant.Data))
+			if err != nil {
+				for _, s := range stored {
+					storage.Delete(ctx, s.Cloud_id)
+				}
+				releaseStorage(ctx, existingUser.User_id, totalBytes)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload to storage"})
+				return
+			}
+
+			fileVariant := &models.FileVariant{
+				Size:      variant.Size,
+				Cloud_url: object.URL,
+				Cloud_id:  object.ID,
+				Bytes:     int64(len(variant.Data)),
+			}
+			stored = append(stored, fileVariant)
+
+			if primary == nil || variant.Size <= primaryAvatarSize {
+				primary = fileVariant
+			}
+		}
+
+		fileRecord := models.File{
+			ID:            primitive.NewObjectID(),
+			File_id:       primitive.NewObjectID().Hex(),
+			User_id:       &existingUser.User_id,
+			Original_name: baseName + ".jpg",
+			Cloud_url:     primary.Cloud_url,
+			Cloud_id:      primary.Cloud_id,
+			File_type:     "image/jpeg",
+			Size:          primary.Bytes,
+			Variant:       stored,
+			Created_at:    time.Now(),
+			Updated_at:    time.Now(),
+		}
+
+		_, err = fileCollection.InsertOne(ctx, fileRecord)
+		if err != nil {
+			for _, s := range stored {
+				storage.Delete(ctx, s.Cloud_id)
+			}
+			releaseStorage(ctx, existingUser.User_id, totalBytes)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file record"})
+			return
+		}
+
+		_, err = userCollection.UpdateOne(
+			ctx,
+			bson.M{"user_id": existingUser.User_id},
+			bson.M{"$set": bson.M{
+				"image_id":   fileRecord.File_id,
+				"updated_at": time.Now().Format(time.RFC3339),
+			}},
+		)
+		if err != nil {
+			removeStoredFile(ctx, storage, fileRecord.File_id)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+			return
+		}
+
+		if existingUser.Image_id != nil && *existingUser.Image_id != "" && *existingUser.Image_id != fileRecord.File_id {
+			if err := removeAvatarFile(ctx, storage, *existingUser.Image_id, existingUser.User_id); err != nil {
+				log.Printf("Failed to clean up previous avatar %s: %v", *existingUser.Image_id, err)
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"image_id":  fileRecord.File_id,
+			"cloud_url": fileRecord.Cloud_url,
+			"variant":   fileRecord.Variant,
+		})
+	}
+}
+
+func DeleteAvatar() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		existingUser, ok := findAvatarOwner(c, ctx)
+		if !ok {
+			return
+		}
+
+		if existingUser.Image_id == nil || *existingUser.Image_id == "" {
+			c.JSON(http.StatusOK, gin.H{"image_id": nil})
+			return
+		}
+
+		_, err := userCollection.UpdateOne(
+			ctx,
+			bson.M{"user_id": existingUser.User_id},
+			bson.M{"$set": bson.M{
+				"image_id":   nil,
+				"updated_at": time.Now().Format(time.RFC3339),
+			}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+			return
+		}
+
+		storage, err := helper.NewStorage()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to initialize storage"})
+			return
+		}
+
+		if err := removeAvatarFile(ctx, storage, *existingUser.Image_id, existingUser.User_id); err != nil {
+			log.Printf("Failed to clean up avatar %s: %v", *existingUser.Image_id, err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"image_id": nil})
+	}
+}
@@ -0,0 +1,318 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+)
+
+type comparedVulnerability struct {
+	Vulnerability *models.Vulnerability
+	Matrix        *models.Matrix
+	CVE           []string
+	Controls      []string
+	Matched       bool
+}
+
+func vulnerabilityKey(vulnerability *models.Vulnerability) string {
+	return strings.ToLower(strings.Join(strings.Fields(textValue(vulnerability.Name)), " "))
+}
+
+// vulnerabilityScores returns the inherent and residual risk as positions on matrix, the matrix the result was
+// scored with (0-100, see positionScore), so that results on different matrices or versions compare. A
+// vulnerability without residual scores keeps its inherent risk.
+func vulnerabilityScores(matrix *models.Matrix, vulnerability *models.Vulnerability) (*int, *int) {
+	inherent := positionScore(matrix, vulnerability.Impact, vulnerability.Likelihood)
+	residual := inherent
+	if vulnerability.New_impact != nil && vulnerability.New_likelihood != nil {
+		residual = positionScore(matrix, vulnerability.New_impact, vulnerability.New_likelihood)
+	}
+
+	return inherent, residual
+}
+
+// controlLabels names each control of the vulnerability by its framework references ("NIST AC-2", "ISO A.8.8"),
+// or by its name when it has none.
+func controlLabels(vulnerability *models.Vulnerability) []string {
+	labels := []string{}
+	for _, control := range vulnerability.Control {
+		if control == nil {
+			continue
+		}
+		references := []string{}
+		for _, code := range helper.NISTCodes(textValue(control.NIST)) {
+			references = append(references, "NIST "+code)
+		}
+		for _, code := range helper.ISOCodes(textValue(control.ISO)) {
+			references = append(references, "ISO "+code)
+		}
+		if len(references) == 0 {
+			if name := strings.TrimSpace(textValue(control.Name)); name != "" {
+				references = append(references, name)
+			}
+		}
+		for _, reference := range references {
+			if !helper.ContainsValue(labels, reference) {
+				labels = append(labels, reference)
+			}
+		}
+	}
+	sort.Strings(labels)
+
+	return labels
+}
+
+func comparedVulnerabilities(result *models.Result, matrix *models.Matrix) []*comparedVulnerability {
+	items := []*comparedVulnerability{}
+	if result.Content == nil {
+		return items
+	}
+	for _, vulnerability := range result.Content.Vulnerability {
+		if vulnerability == nil {
+			continue
+		}
+		item := &comparedVulnerability{Vulnerability: vulnerability, Matrix: matrix, CVE: []string{}, Controls: controlLabels(vulnerability)}
+		for _, value := range stringValues(vulnerability.CVE) {
+			if id := normalizeCVEId(value); cvePattern.MatchString(id) && !helper.ContainsValue(item.CVE, id) {
+				item.CVE = append(item.CVE, id)
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// missingValues returns the values of from that are not in other.
+func missingValues(from []string, other []string) []string {
+	missing := []string{}
+	for _, value := range from {
+		if !helper.ContainsValue(other, value) {
+			missing = append(missing, value)
+		}
+	}
+
+	return missing
+}
+
+func scoreDelta(before *int, after *int) *int {
+	if before == nil || after == nil {
+		return nil
+	}
+	delta := *after - *before
+
+	return &delta
+}
+
+func comparedItem(item *comparedVulnerability) gin.H {
+	inherent, residual := vulnerabilityScores(item.Matrix, item.Vulnerability)
+	return gin.H{
+		"name":           item.Vulnerability.Name,
+		"cve":            item.CVE,
+		"impact":         item.Vulnerability.Impact,
+		"likelihood":     item.Vulnerability.Likelihood,
+		"new_impact":     item.Vulnerability.New_impact,
+		"new_likelihood": item.Vulnerability.New_likelihood,
+		"inherent_score": inherent,
+		"residual_score": residual,
+		"control":        item.Controls,
+	}
+}
+
+func comparedResult(result *models.Result, items []*comparedVulnerability) (gin.H, int, int) {
+	inherentTotal, residualTotal := 0, 0
+	for _, item := range items {
+		inherent, residual := vulnerabilityScores(item.Matrix, item.Vulnerability)
+		if inherent != nil {
+			inherentTotal += *inherent
+		}
+		if residual != nil {
+			residualTotal += *residual
+		}
+	}
+
+	return gin.H{
+		"result_id":           result.Result_id,
+		"assessment_id":       result.Assessment_id,
+		"created_at":          result.Created_at,
+		"vulnerability_count": len(items),
+		"inherent_score":      inherentTotal,
+		"residual_score":      residualTotal,
+	}, inherentTotal, residualTotal
+}
+
+// pairVulnerabilities matches each vulnerability of after with one of before, first by a shared CVE id and then
+// by name. Matching goes in list order so the same two results always pair the same way.
+func pairVulnerabilities(before []*comparedVulnerability, after []*comparedVulnerability) [][2]*comparedVulnerability {
+	pairs := [][2]*comparedVulnerability{}
+	match := func(same func(a *comparedVulnerability, b *comparedVulnerability) bool) {
+		for _, b := range after {
+			if b.Matched {
+				continue
+			}
+			for _, a := range before {
+				if !a.Matched && same(a, b) {
+					a.Matched, b.Matched = true, true
+					pairs = append(pairs, [2]*comparedVulnerability{a, b})
+					break
+				}
+			}
+		}
+	}
+
+	match(func(a *comparedVulnerability, b *comparedVulnerability) bool {
+		for _, id := range b.CVE {
+			if helper.ContainsValue(a.CVE, id) {
+				return true
+			}
+		}
+		return false
+	})
+	match(func(a *comparedVulnerability, b *comparedVulnerability) bool {
+		key := vulnerabilityKey(b.Vulnerability)
+		return key != "" && key == vulnerabilityKey(a.Vulnerability)
+	})
+
+	return pairs
+}
+
+// compareResults lists what changed from result a to result b, each scored on its own matrix. Deltas are b
+// minus a.
+func compareResults(a *models.Result, matrixA *models.Matrix, b *models.Result, matrixB *models.Matrix) gin.H {
+	before, after := comparedVulnerabilities(a, matrixA), comparedVulnerabilities(b, matrixB)
+	pairs := pairVulnerabilities(before, after)
+
+	changed := []gin.H{}
+	unchanged := 0
+	for _, pair := range pairs {
+		x, y := pair[0], pair[1]
+		inherentBefore, residualBefore := vulnerabilityScores(x.Matrix, x.Vulnerability)
+		inherentAfter, residualAfter := vulnerabilityScores(y.Matrix, y.Vulnerability)
+		cveAdded, cveRemoved := missingValues(y.CVE, x.CVE), missingValues(x.CVE, y.CVE)
+		controlAdded, controlRemoved := missingValues(y.Controls, x.Controls), missingValues(x.Controls, y.Controls)
+
+		fields := []string{}
+		for _, field := range []struct {
+			Name   string
+			Before *int
+			After  *int
+		}{
+			{"impact", x.Vulnerability.Impact, y.Vulnerability.Impact},
+			{"likelihood", x.Vulnerability.Likelihood, y.Vulnerability.Likelihood},
+			{"new_impact", x.Vulnerability.New_impact, y.Vulnerability.New_impact},
+			{"new_likelihood", x.Vulnerability.New_likelihood, y.Vulnerability.New_likelihood},
+		} {
+			if (field.Before == nil) != (field.After == nil) || (field.Before != nil && *field.Before != *field.After) {
+				fields = append(fields, field.Name)
+			}
+		}
+		if vulnerabilityKey(x.Vulnerability) != vulnerabilityKey(y.Vulnerability) {
+			fields = append(fields, "name")
+		}
+		if len(cveAdded) > 0 || len(cveRemoved) > 0 {
+			fields = append(fields, "cve")
+		}
+		if len(controlAdded) > 0 || len(controlRemoved) > 0 {
+			fields = append(fields, "control")
+		}
+		if len(fields) == 0 {
+			unchanged++
+			continue
+		}
+
+		matchedBy := "name"
+		for _, id := range y.CVE {
+			if helper.ContainsValue(x.CVE, id) {
+				matchedBy = "cve"
+				break
+			}
+		}
+		changed = append(changed, gin.H{
+			"name":            y.Vulnerability.Name,
+			"matched_by":      matchedBy,
+			"changed_fields":  fields,
+			"a":               comparedItem(x),
+			"b":               comparedItem(y),
+			"inherent_delta":  scoreDelta(inherentBefore, inherentAfter),
+			"residual_delta":  scoreDelta(residualBefore, residualAfter),
+			"cve_added":       cveAdded,
+			"cve_removed":     cveRemoved,
+			"control_added":   controlAdded,
+			"control_removed": controlRemoved,
+		})
+	}
+
+	added := []gin.H{}
+	for _, item := range after {
+		if !item.Matched {
+			added = append(added, comparedItem(item))
+		}
+	}
+	removed := []gin.H{}
+	for _, item := range before {
+		if !item.Matched {
+			removed = append(removed, comparedItem(item))
+		}
+	}
+
+	controlsBefore, controlsAfter := []string{}, []string{}
+	for _, item := range before {
+		controlsBefore = append(controlsBefore, missingValues(item.Controls, controlsBefore)...)
+	}
+	for _, item := range after {
+		controlsAfter = append(controlsAfter, missingValues(item.Controls, controlsAfter)...)
+	}
+	controlAdded, controlRemoved := missingValues(controlsAfter, controlsBefore), missingValues(controlsBefore, controlsAfter)
+	sort.Strings(controlAdded)
+	sort.Strings(controlRemoved)
+
+	summaryA, inherentA, residualA := comparedResult(a, before)
+	summaryB, inherentB, residualB := comparedResult(b, after)
+
+	return gin.H{
+		"a":               summaryA,
+		"b":               summaryB,
+		"same_assessment": a.Assessment_id != nil && b.Assessment_id != nil && *a.Assessment_id == *b.Assessment_id,
+		"inherent_delta":  inherentB - inherentA,
+		"residual_delta":  residualB - residualA,
+		"added":           added,
+		"removed":         removed,
+		"changed":         changed,
+		"unchanged_count": unchanged,
+		"control_added":   controlAdded,
+		"control_removed": controlRemoved,
+	}
+}
+
+// CompareResults reports what changed between two results, usually two runs of the same assessment:
+// GET /results/compare?a=<older result_id>&b=<newer result_id>.
+func CompareResults() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		if c.Query("a") == "" || c.Query("b") == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a and b result ids are required"})
+			return
+		}
+
+		a, ok := findResult(c, ctx, c.Query("a"))
+		if !ok {
+			return
+		}
+		b, ok := findResult(c, ctx, c.Query("b"))
+		if !ok {
+			return
+		}
+
+		matrices := resultMatrices(ctx, []models.Result{*a, *b})
+		c.JSON(http.StatusOK, compareResults(a, matrices[a.Result_id], b, matrices[b.Result_id]))
+	}
+}
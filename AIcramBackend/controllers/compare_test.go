@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/models"
+)
+
+func testMatrix(values ...int) *models.Matrix {
+	levels := []*models.Level{}
+	for _, value := range values {
+		value := value
+		levels = append(levels, &models.Level{Value: &value})
+	}
+
+	return &models.Matrix{Impact: levels, Likelihood: levels}
+}
+
+func testVulnerability(name string, impact, likelihood, newImpact, newLikelihood int) *models.Vulnerability {
+	return &models.Vulnerability{
+		Name:           &name,
+		Impact:         &impact,
+		Likelihood:     &likelihood,
+		New_impact:     &newImpact,
+		New_likelihood: &newLikelihood,
+		Control:        []*models.Control{{Name: &name}},
+	}
+}
+
+func TestCompareResultsScoresEachOnItsMatrix(t *testing.T) {
+	a := &models.Result{Result_id: "a", Content: &models.Content{Vulnerability: []*models.Vulnerability{
+		testVulnerability("Exposed admin panel", 3, 3, 1, 1),
+	}}}
+	b := &models.Result{Result_id: "b", Content: &models.Content{Vulnerability: []*models.Vulnerability{
+		testVulnerability("Exposed admin panel", 5, 5, 2, 2),
+	}}}
+
+	comparison := compareResults(a, testMatrix(1, 2, 3), b, testMatrix(1, 2, 3, 4, 5))
+	changed := comparison["changed"].([]gin.H)
+	if len(changed) != 1 {
+		t.Fatalf("got %d changed vulnerabilities, want 1", len(changed))
+	}
+	if delta := changed[0]["inherent_delta"].(*int); delta == nil || *delta != 0 {
+		t.Errorf("inherent_delta = %v, want 0 for the top corner of both matrices", delta)
+	}
+	if delta := changed[0]["residual_delta"].(*int); delta == nil || *delta != 5 {
+		t.Errorf("residual_delta = %v, want 5 (11 on 3x3 to 16 on 5x5)", delta)
+	}
+	if delta := comparison["inherent_delta"].(int); delta != 0 {
+		t.Errorf("total inherent_delta = %d, want 0", delta)
+	}
+}
+
+func TestVulnerabilityTreatedUsesMatrixPositions(t *testing.T) {
+	matrix := testMatrix(2, 3, 5)
+
+	// 5 x 2 = 10 is above 3 x 3 = 9, but on the matrix (3rd, 1st) = 3 is below (2nd, 2nd) = 4.
+	if vulnerabilityTreated(matrix, testVulnerability("Weak passwords", 5, 2, 3, 3)) {
+		t.Error("treated, want untreated: the residual position is higher")
+	}
+	if !vulnerabilityTreated(matrix, testVulnerability("Weak passwords", 5, 5, 3, 3)) {
+		t.Error("untreated, want treated")
+	}
+	if !vulnerabilityTreated(nil, testVulnerability("Weak passwords", 5, 2, 3, 3)) {
+		t.Error("untreated without a matrix, want the product comparison")
+	}
+}
@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// vulnerabilityTreated reports whether the vulnerability has controls that lower its residual risk below the
+// inherent one, comparing their positions on matrix (see riskPosition). Scores that are not levels of matrix
+// are compared by their impact x likelihood products instead.
+func vulnerabilityTreated(matrix *models.Matrix, vulnerability *models.Vulnerability) bool {
+	hasControl := false
+	for _, control := range vulnerability.Control {
+		if control != nil {
+			hasControl = true
+			break
+		}
+	}
+	if !hasControl || vulnerability.New_impact == nil || vulnerability.New_likelihood == nil {
+		return false
+	}
+	if vulnerability.Impact == nil || vulnerability.Likelihood == nil {
+		return true
+	}
+
+	residual, residualOk := riskPosition(matrix, vulnerability.New_impact, vulnerability.New_likelihood)
+	inherent, inherentOk := riskPosition(matrix, vulnerability.Impact, vulnerability.Likelihood)
+	if residualOk && inherentOk {
+		return residual < inherent
+	}
+
+	return *vulnerability.New_impact**vulnerability.New_likelihood < *vulnerability.Impact**vulnerability.Likelihood
+}
+
+// latestOrganizationResults returns the most recent active result of each active assessment of the organization.
+func latestOrganizationResults(ctx context.Context, organizationId string) ([]models.Result, error) {
+	cursor, err := assessmentCollection.Find(
+		ctx,
+		bson.M{"organization_id": organizationId, "status": 1},
+		options.Find().SetProjection(bson.M{"assessment_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var assessments []bson.M
+	if err = cursor.All(ctx, &assessments); err != nil {
+		return nil, err
+	}
+
+	assessmentIds := []interface{}{}
+	for _, assessment := range assessments {
+		assessmentIds = append(assessmentIds, assessment["assessment_id"])
+	}
+	if len(assessmentIds) == 0 {
+		return []models.Result{}, nil
+	}
+
+	cursor, err = resultCollection.Find(
+		ctx,
+		bson.M{"assessment_id": bson.M{"$in": assessmentIds}, "status": bson.M{"$in": bson.A{1, 2}}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.Result
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	latest := []models.Result{}
+	for _, result := range results {
+		if result.Assessment_id == nil || seen[*result.Assessment_id] {
+			continue
+		}
+		seen[*result.Assessment_id] = true
+		latest = append(latest, result)
+	}
+
+	return latest, nil
+}
+
+func percentOf(count int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return math.Round(float64(count)/float64(total)*1000) / 10
+}
+
+// GetOrganizationCompliance reports, for each regulation the organization selected, which requirements are
+// covered (every mapped vulnerability treated), partially covered (some mapped vulnerabilities untreated) or
+// uncovered (no control maps to them, or none of the mapped vulnerabilities is treated), based on the latest
+// result of each of its assessments.
+func GetOrganizationCompliance() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organizationId := c.Param("organization_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		organization, ok := assetOrganization(ctx, &organizationId, c.GetString("uid"), c.GetString("user_type"))
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this organization"})
+			return
+		}
+
+		selected := uniqueIds(organization.Regulation_id)
+		if len(selected) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "organization has not selected any regulation"})
+			return
+		}
+
+		regulations, err := loadRegulations(ctx, bson.M{"regulation_id": bson.M{"$in": selected}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing regulation items"})
+			return
+		}
+
+		results, err := latestOrganizationResults(ctx, organizationId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing result items"})
+			return
+		}
+
+		matrices := resultMatrices(ctx, results)
+
+		type driver struct {
+			Item    gin.H
+			Treated bool
+		}
+		drivers := map[string]map[string]*driver{}
+		untreated := []gin.H{}
+		for _, result := range results {
+			if result.Content == nil {
+				continue
+			}
+			for i, vulnerability := range result.Content.Vulnerability {
+				if vulnerability == nil {
+					continue
+				}
+
+				treated := vulnerabilityTreated(matrices[result.Result_id], vulnerability)
+				item := gin.H{
+					"result_id":      result.Result_id,
+					"assessment_id":  result.Assessment_id,
+					"vulnerability":  i,
+					"name":           vulnerability.Name,
+					"impact":         vulnerability.Impact,
+					"likelihood":     vulnerability.Likelihood,
+					"new_impact":     vulnerability.New_impact,
+					"new_likelihood": vulnerability.New_likelihood,
+					"treated":        treated,
+				}
+				if !treated {
+					untreated = append(untreated, item)
+				}
+
+				key := result.Result_id + "/" + strconv.Itoa(i)
+				for _, control := range vulnerability.Control {
+					if control == nil {
+						continue
+					}
+
+					ids := matchedRequirements(control, regulations)
+					for _, id := range control.Requirement {
+						if id != nil {
+							ids[*id] = true
+						}
+					}
+
+					for id := range ids {
+						if drivers[id] == nil {
+							drivers[id] = map[string]*driver{}
+						}
+						drivers[id][key] = &driver{Item: item, Treated: treated}
+					}
+				}
+			}
+		}
+
+		frameworks := []gin.H{}
+		totalRequirements, totalCovered, totalPartial := 0, 0, 0
+		for _, regulation := range regulations {
+			requirements := []gin.H{}
+			covered, partial, uncovered := 0, 0, 0
+			for _, requirement := range regulation.Requirement {
+				if requirement == nil || requirement.Requirement_id == nil {
+					continue
+				}
+
+				mapped := drivers[*requirement.Requirement_id]
+				keys := make([]string, 0, len(mapped))
+				for key := range mapped {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+
+				gaps := []gin.H{}
+				for _, key := range keys {
+					if !mapped[key].Treated {
+						gaps = append(gaps, mapped[key].Item)
+					}
+				}
+
+				status := "covered"
+				switch {
+				case len(mapped) == 0, len(gaps) == len(mapped):
+					status = "uncovered"
+					uncovered++
+				case len(gaps) > 0:
+					status = "partial"
+					partial++
+				default:
+					covered++
+				}
+
+				requirements = append(requirements, gin.H{
+					"requirement_id":      requirement.Requirement_id,
+					"code":                requirement.Code,
+					"title":               requirement.Title,
+					"status":              status,
+					"vulnerability_count": len(mapped),
+					"gap_items":           gaps,
+				})
+			}
+
+			total := covered + partial + uncovered
+			totalRequirements += total
+			totalCovered += covered
+			totalPartial += partial
+
+			frameworks = append(frameworks, gin.H{
+				"regulation_id":     regulation.Regulation_id,
+				"name":              regulation.Name,
+				"version":           regulation.Version,
+				"total_count":       total,
+				"covered_count":     covered,
+				"partial_count":     partial,
+				"uncovered_count":   uncovered,
+				"covered_percent":   percentOf(covered, total),
+				"partial_percent":   percentOf(partial, total),
+				"uncovered_percent": percentOf(uncovered, total),
+				"requirement":       requirements,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"organization_id":   organizationId,
+			"result_count":      len(results),
+			"total_count":       totalRequirements,
+			"covered_percent":   percentOf(totalCovered, totalRequirements),
+			"partial_percent":   percentOf(totalPartial, totalRequirements),
+			"uncovered_percent": percentOf(totalRequirements-totalCovered-totalPartial, totalRequirements),
+			"regulation_items":  frameworks,
+			"untreated_items":   untreated,
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxComponents caps the known vulnerable components kept for one result; the highest scored are kept.
+const maxComponents = 200
+
+type installedComponent struct {
+	Asset      models.Asset
+	CPE        string
+	Components []string
+}
+
+// normalizeAssetCPE returns the asset's CPE 2.3 identifiers in canonical lowercase form together with the values
+// that could not be parsed.
+func normalizeAssetCPE(values []*string) ([]*string, []string) {
+	normalized := []*string{}
+	invalid := []string{}
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		components, err := helper.ParseCPE(*value)
+		if err != nil {
+			invalid = append(invalid, *value)
+			continue
+		}
+		cpe := helper.FormatCPE(components)
+		normalized = append(normalized, &cpe)
+	}
+
+	return normalized, invalid
+}
+
+// installedComponents expands the CPEs of the assets. A CPE without a version takes the asset's software version.
+func installedComponents(assets []models.Asset) []installedComponent {
+	installed := []installedComponent{}
+	for _, asset := range assets {
+		version := strings.ToLower(strings.TrimSpace(textValue(asset.Version)))
+		for _, value := range stringValues(asset.CPE) {
+			components, err := helper.ParseCPE(value)
+			if err != nil {
+				continue
+			}
+			if components[helper.CPEVersion] == "*" && version != "" {
+				components[helper.CPEVersion] = strings.ReplaceAll(version, ":", "\\:")
+			}
+			installed = append(installed, installedComponent{Asset: asset, CPE: helper.FormatCPE(components), Components: components})
+		}
+	}
+
+	return installed
+}
+
+func cpeRange(match *models.CPEMatch) helper.CPERange {
+	return helper.CPERange{
+		StartIncluding: textValue(match.Version_start_including),
+		StartExcluding: textValue(match.Version_start_excluding),
+		EndIncluding:   textValue(match.Version_end_including),
+		EndExcluding:   textValue(match.Version_end_excluding),
+	}
+}
+
+// matchComponents looks the assets' CPEs up in the CVE collection and returns one entry per asset, CPE and CVE whose
+// vulnerable configuration covers the installed version. Rejected CVEs are left out. The list is ordered by CVSS
+// score, then CVE id, asset id and CPE, so the same inventory and database always give the same list.
+func matchComponents(ctx context.Context, assets []models.Asset) ([]*models.Component, error) {
+	installed := installedComponents(assets)
+	matched := []*models.Component{}
+	if len(installed) == 0 {
+		return matched, nil
+	}
+
+	prefixes := []interface{}{}
+	seen := map[string]bool{}
+	for _, item := range installed {
+		prefix := helper.CPEProductPrefix(item.Components)
+		if !seen[prefix] {
+			seen[prefix] = true
+			prefixes = append(prefixes, primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)})
+		}
+	}
+
+	cursor, err := cveCollection.Find(ctx, bson.M{"cpe.criteria": bson.M{"$in": prefixes}}, options.Find().SetSort(bson.D{{Key: "cve_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var cves []models.CVE
+	if err := cursor.All(ctx, &cves); err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{}
+	for i := range cves {
+		cve := &cves[i]
+		if strings.EqualFold(textValue(cve.Status), "Rejected") {
+			continue
+		}
+		for _, match := range cve.CPE {
+			if match == nil || (match.Vulnerable != nil && !*match.Vulnerable) {
+				continue
+			}
+			criteria, err := helper.ParseCPE(textValue(match.Criteria))
+			if err != nil {
+				continue
+			}
+			for _, item := range installed {
+				key := item.Asset.Asset_id + " " + item.CPE + " " + cve.Cve_id
+				if found[key] || !helper.MatchCPE(item.Components, criteria, cpeRange(match)) {
+					continue
+				}
+				found[key] = true
+
+				assetId := item.Asset.Asset_id
+				component := &models.Component{
+					Asset_id:    &assetId,
+					Name:        item.Asset.Name,
+					CPE:         optionalText(item.CPE),
+					Criteria:    match.Criteria,
+					Cve_id:      &cve.Cve_id,
+					Description: cve.Description,
+				}
+				if cve.CVSS != nil {
+					component.Score = cve.CVSS.Score
+					component.Severity = cve.CVSS.Severity
+				}
+				matched = append(matched, component)
+			}
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if (a.Score == nil) != (b.Score == nil) {
+			return a.Score != nil
+		}
+		if a.Score != nil && *a.Score != *b.Score {
+			return *a.Score > *b.Score
+		}
+		if *a.Cve_id != *b.Cve_id {
+			return *a.Cve_id < *b.Cve_id
+		}
+		if *a.Asset_id != *b.Asset_id {
+			return *a.Asset_id < *b.Asset_id
+		}
+		return *a.CPE < *b.CPE
+	})
+	if len(matched) > maxComponents {
+		matched = matched[:maxComponents]
+	}
+
+	return matched, nil
+}
+
+// organizationComponents matches the active assets of an organization, or only the listed ones when assetIds is
+// not empty.
+func organizationComponents(ctx context.Context, organizationId string, assetIds []string) ([]*models.Component, error) {
+	filter := bson.M{"status": 1, "cpe.0": bson.M{"$exists": true}}
+	if organizationId != "" {
+		filter["organization_id"] = organizationId
+	}
+	if len(assetIds) > 0 {
+		filter["asset_id"] = bson.M{"$in": assetIds}
+	} else if organizationId == "" {
+		return []*models.Component{}, nil
+	}
+
+	cursor, err := assetCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "asset_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var assets []models.Asset
+	if err := cursor.All(ctx, &assets); err != nil {
+		return nil, err
+	}
+
+	return matchComponents(ctx, assets)
+}
+
+// resultComponents matches the assets of the result's assessment: the assets it selected, or every asset of its
+// organization when it selected none.
+func resultComponents(ctx context.Context, assessmentId *string) ([]*models.Component, error) {
+	if assessmentId == nil || *assessmentId == "" {
+		return nil, nil
+	}
+
+	var assessment models.Assessment
+	if err := assessmentCollection.FindOne(ctx, bson.M{"assessment_id": *assessmentId}).Decode(&assessment); err != nil {
+		return nil, err
+	}
+
+	return organizationComponents(ctx, textValue(assessment.Organization_id), uniqueIds(assessment.Asset_id))
+}
@@ -0,0 +1,450 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/database"
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var controlCatalogCollection *mongo.Collection = database.OpenCollection(database.Client, "control_catalog")
+
+type isoControlSeed struct {
+	Code  string
+	Title string
+	NIST  []string
+}
+
+const isoAnnexAVersion = "ISO/IEC 27001:2022"
+
+var isoAnnexAThemes = map[string]string{
+	"5": "Organizational controls",
+	"6": "People controls",
+	"7": "Physical controls",
+	"8": "Technological controls",
+}
+
+// isoAnnexA lists the 93 Annex A controls of ISO/IEC 27001:2022, each with the NIST SP 800-53 Rev. 5 controls that
+// most directly correspond to it. The NIST side of the mapping is derived from these entries.
+var isoAnnexA = []isoControlSeed{
+	{"A.5.1", "Policies for information security", []string{"PM-1", "PL-1"}},
+	{"A.5.2", "Information security roles and responsibilities", []string{"PM-2", "PS-9"}},
+	{"A.5.3", "Segregation of duties", []string{"AC-5"}},
+	{"A.5.4", "Management responsibilities", []string{"PL-4", "PS-6"}},
+	{"A.5.5", "Contact with authorities", []string{"IR-6"}},
+	{"A.5.6", "Contact with special interest groups", []string{"PM-15", "SI-5"}},
+	{"A.5.7", "Threat intelligence", []string{"PM-16", "RA-10", "SI-5"}},
+	{"A.5.8", "Information security in project management", []string{"PL-2", "SA-3", "SA-4", "SA-8"}},
+	{"A.5.9", "Inventory of information and other associated assets", []string{"CM-8", "PM-5"}},
+	{"A.5.10", "Acceptable use of information and other associated assets", []string{"PL-4", "AC-20"}},
+	{"A.5.11", "Return of assets", []string{"PS-4", "PS-5"}},
+	{"A.5.12", "Classification of information", []string{"RA-2"}},
+	{"A.5.13", "Labelling of information", []string{"AC-16", "MP-3"}},
+	{"A.5.14", "Information transfer", []string{"AC-4", "AC-20", "CA-3", "SC-8"}},
+	{"A.5.15", "Access control", []string{"AC-1", "AC-3", "AC-6"}},
+	{"A.5.16", "Identity management", []string{"AC-2", "IA-2", "IA-4"}},
+	{"A.5.17", "Authentication information", []string{"IA-5"}},
+	{"A.5.18", "Access rights", []string{"AC-2", "AC-6"}},
+	{"A.5.19", "Information security in supplier relationships", []string{"SA-9", "SR-1", "SR-3"}},
+	{"A.5.20", "Addressing information security within supplier agreements", []string{"SA-4", "SA-9", "SR-3"}},
+	{"A.5.21", "Managing information security in the ICT supply chain", []string{"SR-2", "SR-3", "SR-5"}},
+	{"A.5.22", "Monitoring, review and change management of supplier services", []string{"SA-9", "SR-6"}},
+	{"A.5.23", "Information security for use of cloud services", []string{"AC-20", "SA-9"}},
+	{"A.5.24", "Information security incident management planning and preparation", []string{"IR-1", "IR-4", "IR-8"}},
+	{"A.5.25", "Assessment and decision on information security events", []string{"AU-6", "IR-4"}},
+	{"A.5.26", "Response to information security incidents", []string{"IR-4"}},
+	{"A.5.27", "Learning from information security incidents", []string{"IR-4", "IR-8"}},
+	{"A.5.28", "Collection of evidence", []string{"AU-9", "IR-4"}},
+	{"A.5.29", "Information security during disruption", []string{"CP-2", "CP-10"}},
+	{"A.5.30", "ICT readiness for business continuity", []string{"CP-2", "CP-4", "CP-7"}},
+	{"A.5.31", "Legal, statutory, regulatory and contractual requirements", []string{"PL-1", "PM-9"}},
+	{"A.5.32", "Intellectual property rights", []string{"CM-10"}},
+	{"A.5.33", "Protection of records", []string{"AU-9", "AU-11", "SI-12"}},
+	{"A.5.34", "Privacy and protection of PII", []string{"PT-1", "PT-2", "PT-3"}},
+	{"A.5.35", "Independent review of information security", []string{"CA-2", "CA-7"}},
+	{"A.5.36", "Compliance with policies, rules and standards for information security", []string{"CA-2", "CA-7"}},
+	{"A.5.37", "Documented operating procedures", []string{"SA-5"}},
+	{"A.6.1", "Screening", []string{"PS-3"}},
+	{"A.6.2", "Terms and conditions of employment", []string{"PL-4", "PS-6"}},
+	{"A.6.3", "Information security awareness, education and training", []string{"AT-2", "AT-3"}},
+	{"A.6.4", "Disciplinary process", []string{"PS-8"}},
+	{"A.6.5", "Responsibilities after termination or change of employment", []string{"PS-4", "PS-5"}},
+	{"A.6.6", "Confidentiality or non-disclosure agreements", []string{"PS-6"}},
+	{"A.6.7", "Remote working", []string{"AC-17", "PE-17"}},
+	{"A.6.8", "Information security event reporting", []string{"IR-6"}},
+	{"A.7.1", "Physical security perimeters", []string{"PE-3"}},
+	{"A.7.2", "Physical entry", []string{"PE-2", "PE-3", "PE-8"}},
+	{"A.7.3", "Securing offices, rooms and facilities", []string{"PE-3", "PE-5"}},
+	{"A.7.4", "Physical security monitoring", []string{"PE-6"}},
+	{"A.7.5", "Protecting against physical and environmental threats", []string{"PE-13", "PE-14", "PE-15"}},
+	{"A.7.6", "Working in secure areas", []string{"PE-2", "PE-3"}},
+	{"A.7.7", "Clear desk and clear screen", []string{"AC-11", "MP-2"}},
+	{"A.7.8", "Equipment siting and protection", []string{"PE-5", "PE-18"}},
+	{"A.7.9", "Security of assets off-premises", []string{"MP-5", "PE-17"}},
+	{"A.7.10", "Storage media", []string{"MP-2", "MP-4", "MP-5", "MP-6"}},
+	{"A.7.11", "Supporting utilities", []string{"PE-9", "PE-11", "PE-12"}},
+	{"A.7.12", "Cabling security", []string{"PE-4", "PE-9"}},
+	{"A.7.13", "Equipment maintenance", []string{"MA-2", "MA-6"}},
+	{"A.7.14", "Secure disposal or re-use of equipment", []string{"MP-6"}},
+	{"A.8.1", "User end point devices", []string{"AC-19"}},
+	{"A.8.2", "Privileged access rights", []string{"AC-2", "AC-6"}},
+	{"A.8.3", "Information access restriction", []string{"AC-3"}},
+	{"A.8.4", "Access to source code", []string{"AC-3", "CM-5"}},
+	{"A.8.5", "Secure authentication", []string{"AC-7", "IA-2", "IA-8"}},
+	{"A.8.6", "Capacity management", []string{"AU-4", "SC-5"}},
+	{"A.8.7", "Protection against malware", []string{"SI-3"}},
+	{"A.8.8", "Management of technical vulnerabilities", []string{"RA-5", "SI-2"}},
+	{"A.8.9", "Configuration management", []string{"CM-2", "CM-3", "CM-6"}},
+	{"A.8.10", "Information deletion", []string{"MP-6", "SI-12"}},
+	{"A.8.11", "Data masking", []string{"SI-19"}},
+	{"A.8.12", "Data leakage prevention", []string{"AC-4", "SC-7", "SI-4"}},
+	{"A.8.13", "Information backup", []string{"CP-9"}},
+	{"A.8.14", "Redundancy of information processing facilities", []string{"CP-6", "CP-7", "SC-36"}},
+	{"A.8.15", "Logging", []string{"AU-2", "AU-3", "AU-12"}},
+	{"A.8.16", "Monitoring activities", []string{"AU-6", "SI-4"}},
+	{"A.8.17", "Clock synchronization", []string{"AU-8", "SC-45"}},
+	{"A.8.18", "Use of privileged utility programs", []string{"AC-6", "CM-7"}},
+	{"A.8.19", "Installation of software on operational systems", []string{"CM-7", "CM-11"}},
+	{"A.8.20", "Networks security", []string{"SC-7", "SC-8"}},
+	{"A.8.21", "Security of network services", []string{"SA-9", "SC-7"}},
+	{"A.8.22", "Segregation of networks", []string{"SC-7"}},
+	{"A.8.23", "Web filtering", []string{"SC-7", "SI-4"}},
+	{"A.8.24", "Use of cryptography", []string{"SC-12", "SC-13"}},
+	{"A.8.25", "Secure development life cycle", []string{"SA-3", "SA-8", "SA-15"}},
+	{"A.8.26", "Application security requirements", []string{"SA-4", "SA-8"}},
+	{"A.8.27", "Secure system architecture and engineering principles", []string{"SA-8", "SA-17"}},
+	{"A.8.28", "Secure coding", []string{"SA-11", "SA-15"}},
+	{"A.8.29", "Security testing in development and acceptance", []string{"CA-2", "SA-11"}},
+	{"A.8.30", "Outsourced development", []string{"SA-4", "SA-9", "SA-11"}},
+	{"A.8.31", "Separation of development, test and production environments", []string{"CM-4", "SA-3"}},
+	{"A.8.32", "Change management", []string{"CM-3", "CM-4"}},
+	{"A.8.33", "Test information", []string{"SA-3"}},
+	{"A.8.34", "Protection of information systems during audit testing", []string{"CA-2"}},
+}
+
+var catalogSortNumber = regexp.MustCompile(`\d+`)
+
+func catalogControlId(framework string, code string) string {
+	return framework + ":" + code
+}
+
+// catalogSortId pads the numbers of a code so "AC-2" sorts before "AC-10" and "A.5.9" before "A.5.10".
+func catalogSortId(framework string, code string) string {
+	return framework + ":" + catalogSortNumber.ReplaceAllStringFunc(code, func(number string) string {
+		return strings.Repeat("0", 4-len(number)) + number
+	})
+}
+
+// SeedControlCatalog creates the catalog index and loads the ISO/IEC 27001 Annex A controls. The NIST SP 800-53
+// catalog is imported separately from NIST's OSCAL file (see cmd/controlimport).
+func SeedControlCatalog() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	unique := true
+	_, err := controlCatalogCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "control_id", Value: 1}},
+		Options: &options.IndexOptions{Unique: &unique},
+	})
+	if err != nil {
+		log.Printf("Failed to create control catalog index: %v", err)
+	}
+
+	upsert := true
+	for _, seed := range isoAnnexA {
+		mapping := []string{}
+		for _, code := range seed.NIST {
+			mapping = append(mapping, catalogControlId("NIST", code))
+		}
+
+		now := time.Now()
+		_, err := controlCatalogCollection.UpdateOne(
+			ctx,
+			bson.M{"control_id": catalogControlId("ISO", seed.Code)},
+			bson.M{
+				"$set": bson.M{
+					"framework":  "ISO",
+					"code":       seed.Code,
+					"title":      seed.Title,
+					"family":     isoAnnexAThemes[strings.Split(seed.Code, ".")[1]],
+					"withdrawn":  false,
+					"mapping":    mapping,
+					"version":    isoAnnexAVersion,
+					"sort_id":    catalogSortId("ISO", seed.Code),
+					"updated_at": now,
+				},
+				"$setOnInsert": bson.M{
+					"_id":        primitive.NewObjectID(),
+					"created_at": now,
+				},
+			},
+			&options.UpdateOptions{Upsert: &upsert},
+		)
+		if err != nil {
+			log.Printf("Failed to seed control %s: %v", seed.Code, err)
+		}
+	}
+}
+
+// ImportNISTCatalog loads NIST SP 800-53 controls and enhancements from an OSCAL JSON catalog file, returning the
+// number of controls and the catalog version.
+func ImportNISTCatalog(ctx context.Context, path string) (int, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	entries, version, err := helper.ReadOSCALCatalog(file)
+	if err != nil {
+		return 0, "", err
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	writes := []mongo.WriteModel{}
+	for _, entry := range entries {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"control_id": catalogControlId("NIST", entry.Code)}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"framework":   "NIST",
+					"code":        entry.Code,
+					"title":       entry.Title,
+					"family":      optionalText(entry.Family),
+					"parent":      optionalText(entry.Parent),
+					"description": optionalText(entry.Description),
+					"withdrawn":   entry.Withdrawn,
+					"version":     optionalText(version),
+					"sort_id":     catalogSortId("NIST", entry.Code),
+					"updated_at":  now,
+				},
+				"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "created_at": now, "mapping": []string{}},
+			}).
+			SetUpsert(true))
+	}
+
+	if _, err := controlCatalogCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return 0, version, err
+	}
+
+	return len(writes), version, nil
+}
+
+func loadCatalogControls(ctx context.Context, filter interface{}) ([]models.CatalogControl, error) {
+	cursor, err := controlCatalogCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var controls []models.CatalogControl
+	if err := cursor.All(ctx, &controls); err != nil {
+		return nil, err
+	}
+
+	return controls, nil
+}
+
+// normalizeResultControls checks the NIST and ISO references of every control against the catalog, rewrites
+// them in canonical form ("AC-2(1), SI-4", "A.8.8") and fills Reference with the control titles. References the
+// catalog does not list as current, such as ISO/IEC 27001:2013 codes, unknown or withdrawn controls, are kept
+// with Verified false. It returns only the references that are malformed.
+func normalizeResultControls(ctx context.Context, content *models.Content) ([]string, error) {
+	invalid := []string{}
+	if content == nil {
+		return invalid, nil
+	}
+
+	type parsed struct {
+		Control *models.Control
+		NIST    []string
+		ISO     []string
+	}
+	controls := []parsed{}
+	ids := []string{}
+	for _, vulnerability := range content.Vulnerability {
+		if vulnerability == nil {
+			continue
+		}
+		for _, control := range vulnerability.Control {
+			if control == nil {
+				continue
+			}
+
+			item := parsed{Control: control}
+			if text := strings.TrimSpace(textValue(control.NIST)); text != "" {
+				if item.NIST = helper.NISTCodes(text); len(item.NIST) == 0 {
+					invalid = append(invalid, "NIST "+text)
+				}
+			}
+			if text := strings.TrimSpace(textValue(control.ISO)); text != "" {
+				if item.ISO = helper.ISOCodes(text); len(item.ISO) == 0 {
+					invalid = append(invalid, "ISO "+text)
+				}
+			}
+			for _, code := range item.NIST {
+				ids = append(ids, catalogControlId("NIST", code))
+			}
+			for _, code := range item.ISO {
+				ids = append(ids, catalogControlId("ISO", code))
+			}
+			controls = append(controls, item)
+		}
+	}
+	if len(controls) == 0 {
+		return invalid, nil
+	}
+
+	catalog := map[string]models.CatalogControl{}
+	if len(ids) > 0 {
+		found, err := loadCatalogControls(ctx, bson.M{"control_id": bson.M{"$in": ids}})
+		if err != nil {
+			return nil, err
+		}
+		for _, control := range found {
+			catalog[control.Control_id] = control
+		}
+	}
+	for _, item := range controls {
+		references := []*models.ControlReference{}
+		normalized := map[string][]string{}
+		for _, framework := range []string{"NIST", "ISO"} {
+			codes := item.NIST
+			if framework == "ISO" {
+				codes = item.ISO
+			}
+			for _, code := range codes {
+				if helper.ContainsValue(normalized[framework], code) {
+					continue
+				}
+				id := catalogControlId(framework, code)
+				entry, ok := catalog[id]
+				verified := ok && (entry.Withdrawn == nil || !*entry.Withdrawn)
+
+				normalized[framework] = append(normalized[framework], code)
+				reference := &models.ControlReference{Control_id: optionalText(id), Framework: optionalText(framework), Code: optionalText(code), Verified: &verified}
+				if ok {
+					reference.Title = entry.Title
+				}
+				references = append(references, reference)
+			}
+		}
+
+		if item.Control.NIST != nil {
+			value := strings.Join(normalized["NIST"], ", ")
+			item.Control.NIST = &value
+		}
+		if item.Control.ISO != nil {
+			value := strings.Join(normalized["ISO"], ", ")
+			item.Control.ISO = &value
+		}
+		item.Control.Reference = references
+	}
+
+	return invalid, nil
+}
+
+// GetControls searches the catalog by ?framework= (NIST or ISO), ?family= and ?search= (code or title). Each item's
+// mapping lists the corresponding controls of the other framework in both directions.
+func GetControls() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
+		if err != nil || recordPerPage < 1 {
+			recordPerPage = 50
+		}
+
+		page, err1 := strconv.Atoi(c.Query("page"))
+		if err1 != nil || page < 1 {
+			page = 1
+		}
+
+		startIndex := (page - 1) * recordPerPage
+		if queryStartIndex, err := strconv.Atoi(c.Query("startIndex")); err == nil && queryStartIndex >= 0 {
+			startIndex = queryStartIndex
+		}
+
+		matchCriteria := bson.D{}
+		if framework := strings.ToUpper(c.Query("framework")); framework != "" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "framework", Value: framework})
+		}
+		if family := c.Query("family"); family != "" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "family", Value: family})
+		}
+		if c.Query("withdrawn") != "true" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "withdrawn", Value: bson.M{"$ne": true}})
+		}
+		if search := strings.TrimSpace(c.Query("search")); search != "" {
+			pattern := primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}
+			matchCriteria = append(matchCriteria, bson.E{Key: "$or", Value: bson.A{bson.M{"code": pattern}, bson.M{"title": pattern}}})
+		}
+
+		pipeline := mongo.Pipeline{
+			bson.D{{Key: "$match", Value: matchCriteria}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "sort_id", Value: 1}}}},
+			bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: nil}, {Key: "total_count", Value: bson.M{"$sum": 1}}, {Key: "data", Value: bson.M{"$push": "$$ROOT"}}}}},
+			bson.D{{Key: "$project", Value: bson.D{
+				{Key: "_id", Value: 0},
+				{Key: "total_count", Value: 1},
+				{Key: "control_items", Value: bson.M{"$slice": bson.A{"$data", startIndex, recordPerPage}}},
+			}}},
+		}
+
+		cursor, err := controlCatalogCollection.Aggregate(ctx, pipeline)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing control items"})
+			return
+		}
+
+		var pages []struct {
+			Total_count   int
+			Control_items []models.CatalogControl
+		}
+		if err = cursor.All(ctx, &pages); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing control items"})
+			return
+		}
+		if len(pages) == 0 {
+			c.JSON(http.StatusOK, gin.H{"total_count": 0, "control_items": []models.CatalogControl{}})
+			return
+		}
+
+		items := pages[0].Control_items
+		ids := []string{}
+		for _, item := range items {
+			ids = append(ids, item.Control_id)
+		}
+		mapped, err := loadCatalogControls(ctx, bson.M{"mapping": bson.M{"$in": ids}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing control items"})
+			return
+		}
+		for i := range items {
+			mapping := stringValues(items[i].Mapping)
+			for _, other := range mapped {
+				if helper.ContainsValue(stringValues(other.Mapping), items[i].Control_id) && !helper.ContainsValue(mapping, other.Control_id) {
+					mapping = append(mapping, other.Control_id)
+				}
+			}
+			items[i].Mapping = seedStrings(mapping)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"total_count": pages[0].Total_count, "control_items": items})
+	}
+}
@@ -0,0 +1,341 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/database"
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var cveCollection *mongo.Collection = database.OpenCollection(database.Client, "cve")
+
+var cvePattern = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+const cveImportBatch = 500
+
+// CVEImportStats counts what one feed file changed in the knowledge base. Unchanged records were already
+// stored with the same or a later last-modified date.
+type CVEImportStats struct {
+	Read      int
+	Inserted  int
+	Updated   int
+	Unchanged int
+	Skipped   int
+}
+
+func EnsureCVEIndex() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	unique := true
+	_, err := cveCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "cve_id", Value: 1}}, Options: &options.IndexOptions{Unique: &unique}},
+		{Keys: bson.D{{Key: "cpe.criteria", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("Failed to create cve index: %v", err)
+	}
+}
+
+func normalizeCVEId(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
+
+func optionalText(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+// cveFromNVD converts an NVD record; ok is false when the record has no usable id or modification date.
+func cveFromNVD(record *helper.NVDCVE) (models.CVE, bool) {
+	cve := models.CVE{Cve_id: normalizeCVEId(record.ID)}
+	lastModified, err := helper.ParseNVDTime(record.LastModified)
+	if !cvePattern.MatchString(cve.Cve_id) || err != nil {
+		return cve, false
+	}
+	cve.Last_modified = lastModified
+	cve.Published, _ = helper.ParseNVDTime(record.Published)
+	cve.Status = optionalText(record.VulnStatus)
+	cve.Description = optionalText(record.Description())
+
+	if data := record.CVSS(); data != nil {
+		score := data.BaseScore
+		cve.CVSS = &models.CVSS{
+			Version:  optionalText(data.Version),
+			Vector:   optionalText(data.VectorString),
+			Score:    &score,
+			Severity: optionalText(data.BaseSeverity),
+		}
+	}
+
+	cve.CWE = []*string{}
+	for _, cwe := range record.CWE() {
+		value := cwe
+		cve.CWE = append(cve.CWE, &value)
+	}
+
+	cve.CPE = []*models.CPEMatch{}
+	for _, match := range record.CPEMatches() {
+		vulnerable := match.Vulnerable
+		cve.CPE = append(cve.CPE, &models.CPEMatch{
+			Criteria:                optionalText(match.Criteria),
+			Vulnerable:              &vulnerable,
+			Version_start_including: optionalText(match.VersionStartIncluding),
+			Version_start_excluding: optionalText(match.VersionStartExcluding),
+			Version_end_including:   optionalText(match.VersionEndIncluding),
+			Version_end_excluding:   optionalText(match.VersionEndExcluding),
+		})
+	}
+
+	return cve, true
+}
+
+// writeCVEBatch upserts the records that are newer than what is stored, so replaying an older feed after a
+// modified feed never rolls a record back.
+func writeCVEBatch(ctx context.Context, batch []models.CVE, stats *CVEImportStats) error {
+	// A feed that repeats an id within the batch keeps its most recently modified record.
+	ids := []string{}
+	latest := map[string]models.CVE{}
+	for _, cve := range batch {
+		previous, ok := latest[cve.Cve_id]
+		if !ok {
+			ids = append(ids, cve.Cve_id)
+		} else {
+			stats.Unchanged++
+			if !cve.Last_modified.After(previous.Last_modified) {
+				continue
+			}
+		}
+		latest[cve.Cve_id] = cve
+	}
+
+	cursor, err := cveCollection.Find(ctx, bson.M{"cve_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"cve_id": 1, "last_modified": 1}))
+	if err != nil {
+		return err
+	}
+	var stored []struct {
+		Cve_id        string
+		Last_modified time.Time
+	}
+	if err := cursor.All(ctx, &stored); err != nil {
+		return err
+	}
+	known := map[string]time.Time{}
+	for _, item := range stored {
+		known[item.Cve_id] = item.Last_modified
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	writes := []mongo.WriteModel{}
+	for _, id := range ids {
+		cve := latest[id]
+		if lastModified, ok := known[id]; ok && !cve.Last_modified.After(lastModified) {
+			stats.Unchanged++
+			continue
+		}
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"cve_id": cve.Cve_id}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"status":        cve.Status,
+					"description":   cve.Description,
+					"cvss":          cve.CVSS,
+					"cwe":           cve.CWE,
+					"cpe":           cve.CPE,
+					"published":     cve.Published,
+					"last_modified": cve.Last_modified,
+					"updated_at":    now,
+				},
+				"$setOnInsert": bson.M{"created_at": now},
+			}).
+			SetUpsert(true))
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+
+	result, err := cveCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(true))
+	if err != nil {
+		return err
+	}
+	stats.Inserted += int(result.UpsertedCount)
+	stats.Updated += int(result.MatchedCount)
+
+	return nil
+}
+
+// ImportCVEFeed loads an NVD JSON 2.0 feed file (yearly or "modified", optionally gzip compressed) into the
+// cve collection. Records are written in batches as the file is read.
+func ImportCVEFeed(ctx context.Context, path string) (CVEImportStats, error) {
+	stats := CVEImportStats{}
+	file, err := os.Open(path)
+	if err != nil {
+		return stats, err
+	}
+	defer file.Close()
+
+	batch := []models.CVE{}
+	err = helper.ReadNVDFeed(file, func(record *helper.NVDCVE) error {
+		stats.Read++
+		cve, ok := cveFromNVD(record)
+		if !ok {
+			stats.Skipped++
+			return nil
+		}
+		batch = append(batch, cve)
+		if len(batch) < cveImportBatch {
+			return nil
+		}
+		err := writeCVEBatch(ctx, batch, &stats)
+		batch = batch[:0]
+		return err
+	})
+	if err == nil && len(batch) > 0 {
+		err = writeCVEBatch(ctx, batch, &stats)
+	}
+
+	return stats, err
+}
+
+// lookupCVEs loads the stored records of the given ids, keyed by normalized id.
+func lookupCVEs(ctx context.Context, ids []string) (map[string]models.CVE, error) {
+	found := map[string]models.CVE{}
+	if len(ids) == 0 {
+		return found, nil
+	}
+
+	cursor, err := cveCollection.Find(ctx, bson.M{"cve_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var items []models.CVE
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		found[item.Cve_id] = item
+	}
+
+	return found, nil
+}
+
+func GetCVE() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var cve models.CVE
+		err := cveCollection.FindOne(ctx, bson.M{"cve_id": normalizeCVEId(c.Param("cve_id"))}).Decode(&cve)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "cve not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching cve"})
+			return
+		}
+
+		c.JSON(http.StatusOK, cve)
+	}
+}
+
+// GetResultCVEs lists, per vulnerability, each referenced CVE with its stored description, CVSS score and CWEs.
+// References that are malformed, missing from the knowledge base or rejected by NVD are flagged.
+func GetResultCVEs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resultId := c.Param("result_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		result, ok := findResult(c, ctx, resultId)
+		if !ok {
+			return
+		}
+
+		vulnerabilities := []*models.Vulnerability{}
+		if result.Content != nil {
+			vulnerabilities = result.Content.Vulnerability
+		}
+		ids := []string{}
+		for _, vulnerability := range vulnerabilities {
+			if vulnerability == nil {
+				continue
+			}
+			for _, value := range stringValues(vulnerability.CVE) {
+				if id := normalizeCVEId(value); cvePattern.MatchString(id) {
+					ids = append(ids, id)
+				}
+			}
+		}
+
+		found, err := lookupCVEs(ctx, ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing cve items"})
+			return
+		}
+
+		items := []gin.H{}
+		knownCount, flaggedCount := 0, 0
+		for i, vulnerability := range vulnerabilities {
+			if vulnerability == nil {
+				continue
+			}
+
+			cveItems := []gin.H{}
+			for _, value := range stringValues(vulnerability.CVE) {
+				id := normalizeCVEId(value)
+				item := gin.H{"cve_id": id, "known": false, "flagged": true}
+				cve, ok := found[id]
+				switch {
+				case !cvePattern.MatchString(id):
+					item["cve_id"] = value
+					item["reason"] = "not a valid CVE id"
+				case !ok:
+					item["reason"] = "not found in the CVE knowledge base"
+				default:
+					item["known"] = true
+					item["flagged"] = false
+					item["status"] = cve.Status
+					item["description"] = cve.Description
+					item["cvss"] = cve.CVSS
+					item["cwe"] = cve.CWE
+					item["published"] = cve.Published
+					item["last_modified"] = cve.Last_modified
+					if cve.Status != nil && *cve.Status == "Rejected" {
+						item["flagged"] = true
+						item["reason"] = "rejected by NVD"
+					}
+				}
+
+				if item["known"] == true {
+					knownCount++
+				}
+				if item["flagged"] == true {
+					flaggedCount++
+				}
+				cveItems = append(cveItems, item)
+			}
+
+			items = append(items, gin.H{"vulnerability": i, "name": vulnerability.Name, "cve_items": cveItems})
+		}
+
+		c.JSON(http.StatusOK, gin.H{"known_count": knownCount, "flagged_count": flaggedCount, "vulnerability_items": items})
+	}
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"user-athentication-golang/models"
+)
+
+// The official schemas are vendored under testdata (see testdata/README.md); the tests fail when they are absent.
+const stixSchemaRoot = "testdata/stix2.1"
+const oscalSchemaRoot = "testdata/oscal"
+const oscalResultsSchema = "oscal_assessment-results_schema.json"
+
+func fixtureReport() *resultReport {
+	text := func(value string) *string { return &value }
+	number := func(value int) *int { return &value }
+	amount := func(value float64) *float64 { return &value }
+
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	return &resultReport{
+		Result: models.Result{
+			Result_id:     "result-1",
+			User_id:       text("user-1"),
+			Status:        number(1),
+			Assessment_id: text("assessment-1"),
+			Content: &models.Content{
+				Success: number(1),
+				Summary: text("Two exposed services were assessed."),
+				Vulnerability: []*models.Vulnerability{
+					{
+						Name:           text("Unpatched web server"),
+						Description:    text("The public web server runs a version with a known remote code execution flaw."),
+						CVE:            []*string{text("CVE-2021-41773")},
+						MITRE:          []*string{text("T1190 Exploit Public-Facing Application")},
+						Asset_id:       []*string{text("asset-1")},
+						Impact:         number(4),
+						Likelihood:     number(5),
+						New_impact:     number(2),
+						New_likelihood: number(2),
+						Loss:           &models.Loss{Mean: amount(120000)},
+						New_loss:       &models.Loss{Mean: amount(15000)},
+						Control: []*models.Control{
+							{Name: text("Patch management"), NIST: text("SI-2"), ISO: text("A.8.8")},
+							{Name: text("Web application firewall"), NIST: text("SC-7")},
+						},
+					},
+					{
+						Name:       text("Shared administrator accounts"),
+						MITRE:      []*string{text("T1078.003")},
+						Impact:     number(3),
+						Likelihood: number(3),
+						Control: []*models.Control{
+							{Name: text("Account management"), NIST: text("AC-2"), ISO: text("A.5.15")},
+						},
+					},
+				},
+			},
+			Created_at: created,
+			Updated_at: created.Add(time.Hour),
+		},
+		Assessment:    &models.Assessment{Name: text("Perimeter review")},
+		Organization:  &models.Organization{Name: text("Example Corp")},
+		Generated_at:  created.Add(2 * time.Hour),
+		MatrixVersion: 1,
+	}
+}
+
+// schemaCompiler resolves the remote $id and $ref URLs used by the official schemas against the vendored copies
+// under root, so that validation never touches the network.
+func schemaCompiler(t *testing.T, root string) *jsonschema.Compiler {
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+	compiler.LoadURL = func(location string) (io.ReadCloser, error) {
+		parsed, err := url.Parse(location)
+		if err != nil || parsed.Scheme == "file" {
+			return jsonschema.LoadURL(location)
+		}
+
+		parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+		for i := range parts {
+			path := filepath.Join(append([]string{root}, parts[i:]...)...)
+			if file, err := os.Open(path); err == nil {
+				return file, nil
+			}
+		}
+		t.Fatalf("schema %s is not vendored under %s", location, root)
+		return nil, nil
+	}
+
+	return compiler
+}
+
+func validateAgainst(t *testing.T, compiler *jsonschema.Compiler, path string, document interface{}) {
+	schema, err := compiler.Compile(path)
+	if err != nil {
+		t.Fatalf("compiling %s: %v", path, err)
+	}
+
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		t.Fatalf("encoding document: %v", err)
+	}
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		t.Fatalf("decoding document: %v", err)
+	}
+	if err := schema.Validate(decoded); err != nil {
+		t.Errorf("%s: %#v", path, err)
+	}
+}
+
+func TestSTIXBundleSchema(t *testing.T) {
+	bundle := buildSTIXBundle(fixtureReport())
+	if problems := validateSTIXBundle(bundle); len(problems) > 0 {
+		t.Fatalf("validateSTIXBundle: %v", problems)
+	}
+
+	bundleSchema := filepath.Join(stixSchemaRoot, "schemas", "common", "bundle.json")
+	if _, err := os.Stat(bundleSchema); err != nil {
+		t.Fatalf("STIX 2.1 schemas are not vendored at %s; see testdata/README.md", stixSchemaRoot)
+	}
+
+	compiler := schemaCompiler(t, stixSchemaRoot)
+	validateAgainst(t, compiler, bundleSchema, bundle)
+
+	kinds := map[string]string{"relationship": "sros"}
+	for _, object := range bundle.Objects {
+		group := kinds[object.Type]
+		if group == "" {
+			group = "sdos"
+		}
+		validateAgainst(t, compiler, filepath.Join(stixSchemaRoot, "schemas", group, object.Type+".json"), object)
+	}
+}
+
+func TestOSCALResultsSchema(t *testing.T) {
+	document := buildOSCALResults(fixtureReport())
+	if problems := validateOSCALResults(document); len(problems) > 0 {
+		t.Fatalf("validateOSCALResults: %v", problems)
+	}
+
+	schema := filepath.Join(oscalSchemaRoot, oscalResultsSchema)
+	if _, err := os.Stat(schema); err != nil {
+		t.Fatalf("OSCAL %s schema is not vendored at %s; see testdata/README.md", oscalVersion, schema)
+	}
+
+	validateAgainst(t, schemaCompiler(t, oscalSchemaRoot), schema, document)
+}
@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// exportColumns lists the header of each export kind; vulnerability is the zero-based index within the result.
+var exportColumns = map[string][]interface{}{
+	"vulnerabilities": {
+		"result_id", "assessment_id", "vulnerability", "name", "description",
+		"impact", "likelihood", "new_impact", "new_likelihood",
+		"cve", "mitre", "asset_id", "control_count", "loss_mean", "new_loss_mean",
+	},
+	"controls": {
+		"result_id", "assessment_id", "vulnerability", "vulnerability_name", "control",
+		"name", "description", "nist", "iso", "requirement",
+	},
+}
+
+func intCell(value *int) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return *value
+}
+
+func lossCell(loss *models.Loss) interface{} {
+	if loss == nil || loss.Mean == nil {
+		return nil
+	}
+
+	return *loss.Mean
+}
+
+func exportRows(kind string, result models.Result) [][]interface{} {
+	rows := [][]interface{}{}
+	if result.Content == nil {
+		return rows
+	}
+
+	for i, vulnerability := range result.Content.Vulnerability {
+		if vulnerability == nil {
+			continue
+		}
+
+		if kind == "vulnerabilities" {
+			controls := 0
+			for _, control := range vulnerability.Control {
+				if control != nil {
+					controls++
+				}
+			}
+			rows = append(rows, []interface{}{
+				result.Result_id, textValue(result.Assessment_id), i, textValue(vulnerability.Name), textValue(vulnerability.Description),
+				intCell(vulnerability.Impact), intCell(vulnerability.Likelihood), intCell(vulnerability.New_impact), intCell(vulnerability.New_likelihood),
+				joinValues(vulnerability.CVE, "; "), joinValues(vulnerability.MITRE, "; "), joinValues(vulnerability.Asset_id, "; "),
+				controls, lossCell(vulnerability.Loss), lossCell(vulnerability.New_loss),
+			})
+			continue
+		}
+
+		for j, control := range vulnerability.Control {
+			if control == nil {
+				continue
+			}
+			rows = append(rows, []interface{}{
+				result.Result_id, textValue(result.Assessment_id), i, textValue(vulnerability.Name), j,
+				textValue(control.Name), textValue(control.Description), textValue(control.NIST), textValue(control.ISO),
+				joinValues(control.Requirement, "; "),
+			})
+		}
+	}
+
+	return rows
+}
+
+// startExport checks the kind and ?format= (csv by default) before anything is written, then sends the headers
+// and the header row. It returns false when an error response has already been written.
+func startExport(c *gin.Context, name string) (helper.TableWriter, bool) {
+	kind := c.Param("kind")
+	columns, ok := exportColumns[kind]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be vulnerabilities or controls"})
+		return nil, false
+	}
+
+	var writer helper.TableWriter
+	var err error
+	switch format := c.DefaultQuery("format", "csv"); format {
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		writer = helper.NewCSVWriter(c.Writer)
+	case "xlsx":
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		writer, err = helper.NewXLSXWriter(c.Writer, kind)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
+		return nil, false
+	}
+	c.Header("Content-Disposition", "attachment; filename=\""+kind+"-"+name+"."+c.DefaultQuery("format", "csv")+"\"")
+	c.Status(http.StatusOK)
+
+	if err == nil {
+		err = writer.WriteRow(columns)
+	}
+	if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+
+	return writer, true
+}
+
+func ExportResult() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resultId := c.Param("result_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		result, ok := findResult(c, ctx, resultId)
+		if !ok {
+			return
+		}
+
+		writer, ok := startExport(c, result.Result_id)
+		if !ok {
+			return
+		}
+		for _, row := range exportRows(c.Param("kind"), *result) {
+			if err := writer.WriteRow(row); err != nil {
+				c.Error(err)
+				return
+			}
+		}
+		if err := writer.Close(); err != nil {
+			c.Error(err)
+		}
+	}
+}
+
+// parseExportTime reads an RFC 3339 time or a YYYY-MM-DD date. With endOfDay a date resolves to the start of
+// the following day, so that an exclusive upper bound still includes the whole day.
+func parseExportTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err == nil && endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, err
+}
+
+// ExportResults streams the rows of every result matching ?assessment_id=, ?organization_id=, ?from= and ?to=
+// (RFC 3339 or YYYY-MM-DD, on created_at; a date-only to includes that day). Admins may also filter by
+// ?user_id= and ?status=.
+func ExportResults() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		filter := bson.M{}
+		if c.GetString("user_type") != "ADMIN" {
+			filter["user_id"] = c.GetString("uid")
+			filter["status"] = bson.M{"$in": bson.A{1, 2}}
+		} else {
+			if queryUserId := c.Query("user_id"); queryUserId != "" {
+				filter["user_id"] = queryUserId
+			}
+			if queryStatus := c.Query("status"); queryStatus != "" {
+				status, err := strconv.Atoi(queryStatus)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+					return
+				}
+				filter["status"] = status
+			}
+		}
+
+		if assessmentId := c.Query("assessment_id"); assessmentId != "" {
+			filter["assessment_id"] = assessmentId
+		}
+		if organizationId := c.Query("organization_id"); organizationId != "" {
+			assessmentIds, err := assessmentCollection.Distinct(ctx, "assessment_id", bson.M{"organization_id": organizationId})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing assessment items"})
+				return
+			}
+			if assessmentId, ok := filter["assessment_id"]; ok {
+				filter["$and"] = bson.A{bson.M{"assessment_id": assessmentId}, bson.M{"assessment_id": bson.M{"$in": assessmentIds}}}
+				delete(filter, "assessment_id")
+			} else {
+				filter["assessment_id"] = bson.M{"$in": assessmentIds}
+			}
+		}
+
+		created := bson.M{}
+		if from := c.Query("from"); from != "" {
+			t, err := parseExportTime(from, false)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+				return
+			}
+			created["$gte"] = t
+		}
+		if to := c.Query("to"); to != "" {
+			t, err := parseExportTime(to, true)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+				return
+			}
+			created["$lt"] = t
+		}
+		if len(created) > 0 {
+			filter["created_at"] = created
+		}
+
+		cursor, err := resultCollection.Find(
+			ctx,
+			filter,
+			options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).SetBatchSize(100),
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing result items"})
+			return
+		}
+		defer cursor.Close(ctx)
+
+		writer, ok := startExport(c, time.Now().Format("20060102"))
+		if !ok {
+			return
+		}
+
+		// Rows are written as results are read so large exports never sit in memory.
+		for cursor.Next(ctx) {
+			var result models.Result
+			if err := cursor.Decode(&result); err != nil {
+				c.Error(err)
+				return
+			}
+			for _, row := range exportRows(c.Param("kind"), result) {
+				if err := writer.WriteRow(row); err != nil {
+					c.Error(err)
+					return
+				}
+			}
+			c.Writer.Flush()
+		}
+		if err := cursor.Err(); err != nil {
+			c.Error(err)
+			return
+		}
+		if err := writer.Close(); err != nil {
+			c.Error(err)
+		}
+	}
+}
@@ -1,21 +1,21 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"log"
 	"net/http"
-	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 	"user-athentication-golang/database"
 	"user-athentication-golang/models"
 
 	helper "user-athentication-golang/helpers"
 
-	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -25,15 +25,6 @@ var fileCollection *mongo.Collection = database.OpenCollection(database.Client,
 
 func UploadFile() gin.HandlerFunc {
 	return func(c *gin.Context) {
-
-		err := godotenv.Load(".env")
-
-		if err != nil {
-			log.Fatal("Error loading .env file")
-		}
-
-		CLOUDINARYURL := os.Getenv("CLOUDINARY_URL")
-
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
@@ -43,41 +34,52 @@ func UploadFile() gin.HandlerFunc {
 			return
 		}
 
-		cld, err := cloudinary.NewFromURL(CLOUDINARYURL)
+		storage, err := helper.NewStorage()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to initialize storage"})
+			return
+		}
+
+		userId := c.GetString("uid")
+
+		usage, err := reserveStorage(ctx, userId, c.GetString("user_type"), file.Size)
+		if err == errQuotaExceeded {
+			quotaExceededResponse(c, usage, file.Size)
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to initialize Cloudinary"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking quota"})
 			return
 		}
 
 		src, err := file.Open()
 		if err != nil {
+			releaseStorage(ctx, userId, file.Size)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
 			return
 		}
 		defer src.Close()
 
-		uploadResult, err := cld.Upload.Upload(ctx, src, uploader.UploadParams{
-			Folder: "aicram",
-		})
+		data, err := io.ReadAll(src)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload to Cloudinary"})
+			releaseStorage(ctx, userId, file.Size)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
 			return
 		}
 
-		fileRecord := models.File{
-			ID:            primitive.NewObjectID(),
-			File_id:       primitive.NewObjectID().Hex(),
-			Original_name: file.Filename,
-			Cloud_url:     uploadResult.SecureURL,
-			Cloud_id:      uploadResult.PublicID,
-			File_type:     file.Header.Get("Content-Type"),
-			Size:          file.Size,
-			Created_at:    time.Now(),
-			Updated_at:    time.Now(),
+		stored, err := storage.Put(ctx, file.Filename, bytes.NewReader(data))
+		if err != nil {
+			releaseStorage(ctx, userId, file.Size)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload to storage"})
+			return
 		}
 
+		fileRecord := newFileRecord(userId, file.Filename, file.Header.Get("Content-Type"), file.Size, stored, data)
+
 		_, err = fileCollection.InsertOne(ctx, fileRecord)
 		if err != nil {
+			releaseStorage(ctx, userId, file.Size)
+			storage.Delete(ctx, stored.ID)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file record"})
 			return
 		}
@@ -85,17 +87,61 @@ func UploadFile() gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{
 			"file_id":   fileRecord.File_id,
 			"cloud_url": fileRecord.Cloud_url,
+			"extracted": fileRecord.Text != nil,
 		})
 	}
 }
 
+func newFileRecord(userId string, name string, fileType string, size int64, stored *helper.StoredFile, data []byte) models.File {
+	fileRecord := models.File{
+		ID:            primitive.NewObjectID(),
+		File_id:       primitive.NewObjectID().Hex(),
+		User_id:       &userId,
+		Original_name: name,
+		Cloud_url:     stored.URL,
+		Cloud_id:      stored.ID,
+		File_type:     fileType,
+		Size:          size,
+		Created_at:    time.Now(),
+		Updated_at:    time.Now(),
+	}
+
+	if data != nil && helper.IsExtractable(name, fileType) {
+		text, err := helper.ExtractText(data, name, fileType)
+		if err != nil {
+			log.Printf("Failed to extract text from %s: %v", name, err)
+		} else {
+			fileRecord.Text = &text
+		}
+	}
+
+	return fileRecord
+}
+
+func parseDateQuery(value string, endOfDay bool) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return t, true
+}
+
 func GetFiles() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if err := helper.CheckUserType(c, "ADMIN"); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
 		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
 		if err != nil || recordPerPage < 1 {
@@ -108,30 +154,157 @@ func GetFiles() gin.HandlerFunc {
 		}
 
 		startIndex := (page - 1) * recordPerPage
-		startIndex, err = strconv.Atoi(c.Query("startIndex"))
-
-		matchStage := bson.D{{"$match", bson.D{{}}}}
-		sortStage := bson.D{{"$sort", bson.D{{"created_at", -1}}}}
-		groupStage := bson.D{{"$group", bson.D{{"_id", bson.D{{"_id", "null"}}}, {"total_count", bson.D{{"$sum", 1}}}, {"data", bson.D{{"$push", "$$ROOT"}}}}}}
-		projectStage := bson.D{
-			{"$project", bson.D{
-				{"_id", 0},
-				{"total_count", 1},
-				{"file_items", bson.D{{"$slice", []interface{}{"$data", startIndex, recordPerPage}}}},
-			}}}
-
-		result, err := fileCollection.Aggregate(ctx, mongo.Pipeline{
-			matchStage, sortStage, groupStage, projectStage})
-		defer cancel()
+		if queryStartIndex, err := strconv.Atoi(c.Query("startIndex")); err == nil && queryStartIndex >= 0 {
+			startIndex = queryStartIndex
+		}
+
+		userId := c.GetString("uid")
+		userType := c.GetString("user_type")
+
+		matchCriteria := bson.D{}
+
+		if userType != "ADMIN" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "user_id", Value: userId})
+		} else if queryUserId := c.Query("user_id"); queryUserId != "" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "user_id", Value: queryUserId})
+		}
+
+		if fileType := c.Query("file_type"); fileType != "" {
+			if strings.HasSuffix(fileType, "/") || strings.HasSuffix(fileType, "/*") {
+				prefix := strings.TrimSuffix(fileType, "*")
+				matchCriteria = append(matchCriteria, bson.E{Key: "file_type", Value: primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix), Options: "i"}})
+			} else {
+				matchCriteria = append(matchCriteria, bson.E{Key: "file_type", Value: fileType})
+			}
+		}
+
+		sizeRange := bson.M{}
+		if minSize, err := strconv.ParseInt(c.Query("min_size"), 10, 64); err == nil {
+			sizeRange["$gte"] = minSize
+		}
+		if maxSize, err := strconv.ParseInt(c.Query("max_size"), 10, 64); err == nil {
+			sizeRange["$lte"] = maxSize
+		}
+		if len(sizeRange) > 0 {
+			matchCriteria = append(matchCriteria, bson.E{Key: "size", Value: sizeRange})
+		}
+
+		dateRange := bson.M{}
+		if from, ok := parseDateQuery(c.Query("date_from"), false); ok {
+			dateRange["$gte"] = from
+		}
+		if to, ok := parseDateQuery(c.Query("date_to"), true); ok {
+			dateRange["$lte"] = to
+		}
+		if len(dateRange) > 0 {
+			matchCriteria = append(matchCriteria, bson.E{Key: "created_at", Value: dateRange})
+		}
+
+		if search := strings.TrimSpace(c.Query("search")); search != "" {
+			matchCriteria = append(matchCriteria, bson.E{Key: "original_name", Value: primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}})
+		}
+
+		if assessmentId := c.Query("assessment_id"); assessmentId != "" {
+			var assessment models.Assessment
+			err := assessmentCollection.FindOne(ctx, bson.M{"assessment_id": assessmentId}).Decode(&assessment)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "assessment_error"})
+				return
+			}
+			if userType != "ADMIN" && (assessment.User_id == nil || *assessment.User_id != userId) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this assessment"})
+				return
+			}
+			fileIds := []string{}
+			for _, fileId := range assessment.File {
+				if fileId != nil {
+					fileIds = append(fileIds, *fileId)
+				}
+			}
+			matchCriteria = append(matchCriteria, bson.E{Key: "file_id", Value: bson.M{"$in": fileIds}})
+		}
+
+		pipeline := mongo.Pipeline{}
+		if len(matchCriteria) > 0 {
+			pipeline = append(pipeline, bson.D{{Key: "$match", Value: matchCriteria}})
+		}
+
+		attached := c.Query("attached")
+		if attached == "true" || attached == "false" {
+			pipeline = append(pipeline,
+				bson.D{{Key: "$lookup", Value: bson.M{
+					"from":         "assessment",
+					"localField":   "file_id",
+					"foreignField": "file",
+					"as":           "attached_assessment",
+				}}},
+				bson.D{{Key: "$lookup", Value: bson.M{
+					"from":         "user",
+					"localField":   "file_id",
+					"foreignField": "image_id",
+					"as":           "attached_user",
+				}}},
+			)
+
+			attachedMatch := bson.M{"$or": bson.A{
+				bson.M{"attached_assessment.0": bson.M{"$exists": true}},
+				bson.M{"attached_user.0": bson.M{"$exists": true}},
+			}}
+			if attached == "false" {
+				attachedMatch = bson.M{
+					"attached_assessment.0": bson.M{"$exists": false},
+					"attached_user.0":       bson.M{"$exists": false},
+				}
+			}
+			pipeline = append(pipeline, bson.D{{Key: "$match", Value: attachedMatch}})
+		}
+
+		sortField := "created_at"
+		switch c.Query("sort") {
+		case "size":
+			sortField = "size"
+		case "name":
+			sortField = "original_name"
+		case "file_type":
+			sortField = "file_type"
+		}
+		sortOrder := -1
+		if c.Query("order") == "asc" {
+			sortOrder = 1
+		}
+
+		pipeline = append(pipeline,
+			bson.D{{Key: "$project", Value: bson.M{"text": 0, "attached_assessment": 0, "attached_user": 0}}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: sortField, Value: sortOrder}, {Key: "_id", Value: sortOrder}}}},
+			bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: nil}, {Key: "total_count", Value: bson.M{"$sum": 1}}, {Key: "data", Value: bson.M{"$push": "$$ROOT"}}}}},
+			bson.D{{Key: "$project", Value: bson.D{
+				{Key: "_id", Value: 0},
+				{Key: "total_count", Value: 1},
+				{Key: "file_items", Value: bson.M{"$slice": bson.A{"$data", startIndex, recordPerPage}}},
+			}}},
+		)
+
+		result, err := fileCollection.Aggregate(ctx, pipeline)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while listing file items"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing file items"})
+			return
 		}
+
 		var allfiles []bson.M
 		if err = result.All(ctx, &allfiles); err != nil {
-			log.Fatal(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing file items"})
+			return
 		}
-		c.JSON(http.StatusOK, allfiles[0])
 
+		if len(allfiles) == 0 {
+			c.JSON(http.StatusOK, gin.H{
+				"total_count": 0,
+				"file_items":  []bson.M{},
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, allfiles[0])
 	}
 }
 
@@ -153,27 +326,176 @@ func GetFile() gin.HandlerFunc {
 			return
 		}
 
+		if c.GetString("user_type") != "ADMIN" && (file.User_id == nil || *file.User_id != c.GetString("uid")) {
+			// Avatars uploaded before files had owners are still readable by the user they belong to.
+			count, err := userCollection.CountDocuments(ctx, bson.M{"user_id": c.GetString("uid"), "image_id": fileId})
+			if err != nil || count == 0 {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this file"})
+				return
+			}
+		}
+
 		c.JSON(http.StatusOK, file)
 	}
 }
 
+// DeleteFile lets admins delete any file and members delete their own, removing the stored objects and
+// releasing the quota they used.
 func DeleteFile() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if err := helper.CheckUserType(c, "ADMIN"); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		fileId := c.Param("file_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var file models.File
+		err := fileCollection.FindOne(ctx, bson.M{"file_id": fileId}).Decode(&file)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete file"})
 			return
 		}
 
-		fileId := c.Param("file_id")
-		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		if c.GetString("user_type") != "ADMIN" && (file.User_id == nil || *file.User_id != c.GetString("uid")) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to delete this file"})
+			return
+		}
 
-		result, err := fileCollection.DeleteOne(ctx, bson.M{"file_id": fileId})
-		defer cancel()
+		storage, err := helper.NewStorage()
 		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to initialize storage"})
+			return
+		}
+
+		if err := removeStoredFile(ctx, storage, fileId); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete file"})
 			return
 		}
 
-		c.JSON(http.StatusOK, result)
+		// A deleted avatar must not stay referenced from its user.
+		userCollection.UpdateMany(ctx, bson.M{"image_id": fileId}, bson.M{"$set": bson.M{"image_id": nil}})
+
+		c.JSON(http.StatusOK, gin.H{"DeletedCount": 1})
 	}
 }
+
+func ExtractFile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 100*time.Second)
+		defer cancel()
+
+		fileId := c.Param("file_id")
+		var file models.File
+
+		err := fileCollection.FindOne(ctx, bson.M{"file_id": fileId}).Decode(&file)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if c.GetString("user_type") != "ADMIN" {
+			if file.User_id == nil || *file.User_id != c.GetString("uid") {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to extract this file"})
+				return
+			}
+		}
+
+		if !helper.IsExtractable(file.Original_name, file.File_type) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_file_type"})
+			return
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.Cloud_url, nil)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to download file"})
+			return
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to download file"})
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to download file"})
+			return
+		}
+
+		// Reading one byte past the cap detects anything larger without buffering it.
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxExtractSize+1))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to download file"})
+			return
+		}
+		if int64(len(data)) > maxExtractSize {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "file is too large to extract"})
+			return
+		}
+
+		text, err := helper.ExtractText(data, file.Original_name, file.File_type)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "failed to extract text: " + err.Error()})
+			return
+		}
+
+		_, err = fileCollection.UpdateOne(
+			ctx,
+			bson.M{"file_id": fileId},
+			bson.M{"$set": bson.M{
+				"text":       text,
+				"updated_at": time.Now(),
+			}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update file"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"file_id": fileId,
+			"length":  len(text),
+		})
+	}
+}
+
+func loadFileTexts(ctx context.Context, fileIds []string, userId string, userType string) ([]gin.H, error) {
+	documents := []gin.H{}
+	if len(fileIds) == 0 {
+		return documents, nil
+	}
+
+	filter := bson.M{"file_id": bson.M{"$in": fileIds}}
+	if userType != "ADMIN" {
+		filter["user_id"] = userId
+	}
+
+	cursor, err := fileCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []models.File
+	if err = cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if file.Text == nil || *file.Text == "" {
+			continue
+		}
+		documents = append(documents, gin.H{
+			"name": file.Original_name,
+			"text": *file.Text,
+		})
+	}
+
+	return documents, nil
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// organizationAssetGraph loads the active assets of an organization and their dependency edges.
+func organizationAssetGraph(ctx context.Context, organizationId string) (map[string]models.Asset, helper.DependencyGraph, error) {
+	cursor, err := assetCollection.Find(ctx, bson.M{"organization_id": organizationId, "status": 1})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var assets []models.Asset
+	if err = cursor.All(ctx, &assets); err != nil {
+		return nil, nil, err
+	}
+
+	byId := map[string]models.Asset{}
+	graph := helper.DependencyGraph{}
+	for _, asset := range assets {
+		byId[asset.Asset_id] = asset
+		graph[asset.Asset_id] = uniqueIds(asset.Dependency)
+	}
+
+	return byId, graph, nil
+}
+
+// dependencyCycle returns a cycle through one of the given assets once their dependencies are replaced
+// in the organization graph, or nil when none would exist.
+func dependencyCycle(ctx context.Context, organizationId string, changes map[string][]*string) ([]string, error) {
+	_, graph, err := organizationAssetGraph(ctx, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	for assetId, dependencies := range changes {
+		graph[assetId] = uniqueIds(dependencies)
+	}
+
+	// Only cycles through a changed asset count, so an existing cycle elsewhere does not block unrelated edits.
+	for _, cycle := range graph.Cycles() {
+		for _, assetId := range cycle {
+			if _, changed := changes[assetId]; changed {
+				return cycle, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func graphNode(asset models.Asset) gin.H {
+	return gin.H{
+		"asset_id":        asset.Asset_id,
+		"name":            asset.Name,
+		"type":            asset.Type,
+		"value":           asset.Value,
+		"criticality":     asset.Criticality,
+		"confidentiality": asset.Confidentiality,
+		"integrity":       asset.Integrity,
+		"availability":    asset.Availability,
+		"tag":             asset.Tag,
+	}
+}
+
+func GetOrganizationGraph() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organizationId := c.Param("organization_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		if _, ok := assetOrganization(ctx, &organizationId, c.GetString("uid"), c.GetString("user_type")); !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this organization"})
+			return
+		}
+
+		assets, graph, err := organizationAssetGraph(ctx, organizationId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while building asset graph"})
+			return
+		}
+
+		nodes := []gin.H{}
+		edges := []gin.H{}
+		assetIds := make([]string, 0, len(assets))
+		for assetId := range assets {
+			assetIds = append(assetIds, assetId)
+		}
+		sort.Strings(assetIds)
+
+		for _, assetId := range assetIds {
+			asset := assets[assetId]
+			nodes = append(nodes, graphNode(asset))
+			for _, dependency := range graph[asset.Asset_id] {
+				edges = append(edges, gin.H{
+					"source": asset.Asset_id,
+					"target": dependency,
+				})
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"organization_id": organizationId,
+			"nodes":           nodes,
+			"edges":           edges,
+			"cycles":          graph.Cycles(),
+		})
+	}
+}
+
+// blastRadius expands the start assets to everything depending on them and aggregates value and criticality.
+func blastRadius(c *gin.Context, ctx context.Context, organizationId string, start []string) {
+	assets, graph, err := organizationAssetGraph(ctx, organizationId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while building asset graph"})
+		return
+	}
+
+	impacted := []gin.H{}
+	totalValue := 0.0
+	maxCriticality := 0
+	criticalityCount := map[string]int{}
+	for _, node := range graph.BlastRadius(start) {
+		asset, ok := assets[node.ID]
+		if !ok {
+			continue
+		}
+
+		item := graphNode(asset)
+		item["depth"] = node.Depth
+		impacted = append(impacted, item)
+
+		if asset.Value != nil {
+			totalValue += *asset.Value
+		}
+		if asset.Criticality != nil {
+			criticalityCount[strconv.Itoa(*asset.Criticality)]++
+			if *asset.Criticality > maxCriticality {
+				maxCriticality = *asset.Criticality
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"organization_id":   organizationId,
+		"source":            start,
+		"total_count":       len(impacted),
+		"total_value":       totalValue,
+		"max_criticality":   maxCriticality,
+		"criticality_count": criticalityCount,
+		"impacted_items":    impacted,
+		"cycles":            graph.Cycles(),
+	})
+}
+
+func GetAssetImpact() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		assetId := c.Param("asset_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var asset models.Asset
+		err := assetCollection.FindOne(ctx, bson.M{"asset_id": assetId}).Decode(&asset)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "asset not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching asset"})
+			return
+		}
+
+		if c.GetString("user_type") != "ADMIN" {
+			if asset.User_id == nil || *asset.User_id != c.GetString("uid") || (asset.Status != nil && *asset.Status != 1) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this asset"})
+				return
+			}
+		}
+
+		if asset.Organization_id == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "organization_error"})
+			return
+		}
+
+		blastRadius(c, ctx, *asset.Organization_id, []string{asset.Asset_id})
+	}
+}
+
+// GetResultImpact computes the blast radius of the assets linked to one vulnerability of a result,
+// selected by its zero-based index in ?vulnerability=.
+func GetResultImpact() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resultId := c.Param("result_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var result models.Result
+		err := resultCollection.FindOne(ctx, bson.M{"result_id": resultId}).Decode(&result)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "result not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching result"})
+			return
+		}
+
+		if c.GetString("user_type") != "ADMIN" {
+			if result.User_id == nil || *result.User_id != c.GetString("uid") || (result.Status != nil && *result.Status != 1 && *result.Status != 2) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this result"})
+				return
+			}
+		}
+
+		index, err := strconv.Atoi(c.Query("vulnerability"))
+		if err != nil || result.Content == nil || index < 0 || index >= len(result.Content.Vulnerability) || result.Content.Vulnerability[index] == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vulnerability"})
+			return
+		}
+
+		start := uniqueIds(result.Content.Vulnerability[index].Asset_id)
+		if len(start) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "vulnerability is not linked to any asset"})
+			return
+		}
+
+		var asset models.Asset
+		if err := assetCollection.FindOne(ctx, bson.M{"asset_id": start[0]}).Decode(&asset); err != nil || asset.Organization_id == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "asset_error"})
+			return
+		}
+
+		blastRadius(c, ctx, *asset.Organization_id, start)
+	}
+}
@@ -0,0 +1,418 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/database"
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// assessmentReference describes how assessments point at a matrix or organization.
+type assessmentReference struct {
+	Field  string
+	Target string
+	// Reassign checks that targetId can replace the deleted document and returns the fields to set on assessments.
+	Reassign func(c *gin.Context, ctx context.Context, targetId string) (bson.M, string)
+	// Assets marks references that the organization's assets hold too; they follow the assessments.
+	Assets bool
+}
+
+// referenceUpdate is one UpdateMany that moves documents off a matrix or organization before it is removed.
+type referenceUpdate struct {
+	Collection *mongo.Collection
+	IdField    string
+	Filter     bson.M
+	Set        bson.M
+}
+
+var errReferenceTargetNotFound = errors.New("reference target not found")
+
+var matrixReference = assessmentReference{
+	Field:  "matrix_id",
+	Target: "matrix",
+	Reassign: func(c *gin.Context, ctx context.Context, targetId string) (bson.M, string) {
+		var matrix models.Matrix
+		if err := matrixCollection.FindOne(ctx, bson.M{"matrix_id": targetId}).Decode(&matrix); err != nil {
+			return nil, "matrix_error"
+		}
+		if !isTemplateMatrix(matrix) {
+			if matrix.Status != nil && *matrix.Status != 1 {
+				return nil, "matrix_error"
+			}
+			if c.GetString("user_type") != "ADMIN" && (matrix.User_id == nil || *matrix.User_id != c.GetString("uid")) {
+				return nil, "matrix_error"
+			}
+		}
+
+		version, err := recordMatrixVersion(ctx, matrix, matrix.User_id)
+		if err != nil {
+			return nil, "matrix_error"
+		}
+
+		return bson.M{"matrix_id": matrix.Matrix_id, "matrix_version": version}, ""
+	},
+}
+
+var organizationReference = assessmentReference{
+	Field:  "organization_id",
+	Target: "organization",
+	Reassign: func(c *gin.Context, ctx context.Context, targetId string) (bson.M, string) {
+		var organization models.Organization
+		if err := organizationCollection.FindOne(ctx, bson.M{"organization_id": targetId}).Decode(&organization); err != nil {
+			return nil, "organization_error"
+		}
+		if organization.Status != nil && *organization.Status != 1 {
+			return nil, "organization_error"
+		}
+		if c.GetString("user_type") != "ADMIN" && (organization.User_id == nil || *organization.User_id != c.GetString("uid")) {
+			return nil, "organization_error"
+		}
+
+		return bson.M{"organization_id": organization.Organization_id}, ""
+	},
+	Assets: true,
+}
+
+func findDocuments(ctx context.Context, collection *mongo.Collection, filter bson.M, projection bson.M) ([]bson.M, error) {
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []bson.M
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+// pinResultMatrices keeps the results of a matrix that is being deleted, or whose assessments move to another
+// matrix, scored with the version they were created with: unpinned results get the matrix's latest version and
+// results that only knew the matrix through their assessment get the assessment's matrix and version.
+func pinResultMatrices(ctx context.Context, matrixId string, assessments []bson.M) ([]referenceUpdate, error) {
+	latest, err := storedMatrixVersion(ctx, matrixId)
+	if err != nil || latest == 0 {
+		return nil, err
+	}
+
+	updates := []referenceUpdate{{
+		Collection: resultCollection,
+		IdField:    "result_id",
+		Filter:     bson.M{"matrix_id": matrixId, "matrix_version": nil},
+		Set:        bson.M{"matrix_version": latest},
+	}}
+
+	byVersion := map[int][]string{}
+	versions := []int{}
+	for _, assessment := range assessments {
+		assessmentId, _ := assessment["assessment_id"].(string)
+		if assessmentId == "" {
+			continue
+		}
+		version := latest
+		switch pinned := assessment["matrix_version"].(type) {
+		case int32:
+			if pinned > 0 {
+				version = int(pinned)
+			}
+		case int64:
+			if pinned > 0 {
+				version = int(pinned)
+			}
+		}
+		if _, ok := byVersion[version]; !ok {
+			versions = append(versions, version)
+		}
+		byVersion[version] = append(byVersion[version], assessmentId)
+	}
+	for _, version := range versions {
+		updates = append(updates, referenceUpdate{
+			Collection: resultCollection,
+			IdField:    "result_id",
+			Filter:     bson.M{"assessment_id": bson.M{"$in": byVersion[version]}, "matrix_id": bson.M{"$in": bson.A{nil, ""}}},
+			Set:        bson.M{"matrix_id": matrixId, "matrix_version": version},
+		})
+	}
+
+	return updates, nil
+}
+
+// resolveAssessmentReferences runs before a matrix or organization is removed (soft) or deleted (hard) and returns
+// the updates that move the documents referencing it. Without ?cascade=true or ?reassign=<id> it refuses with 409
+// and lists the referencing assessments and assets; cascade soft-removes them (and clears the dangling reference on
+// hard delete) and reassign points them at another document. A member is refused without details while documents
+// of other users reference it. Results keep the matrix version they were scored with. It returns false when a
+// response has already been written.
+func resolveAssessmentReferences(c *gin.Context, ctx context.Context, reference assessmentReference, id string, hardDelete bool) ([]referenceUpdate, bool) {
+	cascade := c.Query("cascade") == "true"
+	reassign := c.Query("reassign")
+	if cascade && reassign != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cascade and reassign cannot be combined"})
+		return nil, false
+	}
+	if reassign == id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reference.Target + "_error"})
+		return nil, false
+	}
+
+	// Removed assessments and assets only block a hard delete, where they would be left pointing at nothing.
+	filter := bson.M{reference.Field: id}
+	if !hardDelete {
+		filter["status"] = 1
+	}
+
+	// Members only see and move their own documents; references held by other users block the removal outright.
+	if c.GetString("user_type") != "ADMIN" {
+		uid := c.GetString("uid")
+		foreign := bson.M{"user_id": bson.M{"$ne": uid}}
+		for key, value := range filter {
+			foreign[key] = value
+		}
+		count, err := assessmentCollection.CountDocuments(ctx, foreign, options.Count().SetLimit(1))
+		if err == nil && count == 0 && reference.Assets {
+			count, err = assetCollection.CountDocuments(ctx, foreign, options.Count().SetLimit(1))
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking references"})
+			return nil, false
+		}
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": reference.Target + " is referenced by other users"})
+			return nil, false
+		}
+		filter["user_id"] = uid
+	}
+
+	assessments, err := findDocuments(ctx, assessmentCollection, filter, bson.M{"_id": 0, "assessment_id": 1, "name": 1, "user_id": 1, "status": 1, "matrix_version": 1})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking references"})
+		return nil, false
+	}
+	assets := []bson.M{}
+	if reference.Assets {
+		assets, err = findDocuments(ctx, assetCollection, filter, bson.M{"_id": 0, "asset_id": 1, "name": 1, "user_id": 1, "status": 1})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking references"})
+			return nil, false
+		}
+	}
+
+	updates := []referenceUpdate{}
+	if reference.Field == "matrix_id" && (hardDelete || (reassign != "" && len(assessments) > 0)) {
+		pins, err := pinResultMatrices(ctx, id, assessments)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking references"})
+			return nil, false
+		}
+		updates = append(updates, pins...)
+	}
+	if len(assessments) == 0 && len(assets) == 0 {
+		return updates, true
+	}
+
+	var set, assetSet bson.M
+	switch {
+	case reassign != "":
+		var errCode string
+		set, errCode = reference.Reassign(c, ctx, reassign)
+		if errCode != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errCode})
+			return nil, false
+		}
+		assetSet = bson.M{reference.Field: set[reference.Field]}
+	case cascade:
+		set = bson.M{"status": 2}
+		assetSet = bson.M{"status": 2}
+		if hardDelete {
+			set[reference.Field] = nil
+			assetSet[reference.Field] = nil
+			if reference.Field == "matrix_id" {
+				set["matrix_version"] = nil
+			}
+		}
+	default:
+		c.JSON(http.StatusConflict, gin.H{
+			"error":            reference.Target + " is still referenced",
+			"total_count":      len(assessments) + len(assets),
+			"assessment_items": assessments,
+			"asset_items":      assets,
+		})
+		return nil, false
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	if len(assessments) > 0 {
+		set["updated_at"] = now
+		updates = append(updates, referenceUpdate{Collection: assessmentCollection, IdField: "assessment_id", Filter: filter, Set: set})
+	}
+	if len(assets) > 0 {
+		assetSet["updated_at"] = now
+		updates = append(updates, referenceUpdate{Collection: assetCollection, IdField: "asset_id", Filter: filter, Set: assetSet})
+	}
+
+	return updates, true
+}
+
+// applyReferenceUpdates runs the reference updates and then remove, which deletes or soft-removes the referenced
+// document, in one transaction. Standalone servers cannot run transactions, so there the previous values of the
+// updated documents are saved first and written back when a later step fails.
+func applyReferenceUpdates(ctx context.Context, updates []referenceUpdate, remove func(ctx context.Context) error) error {
+	session, err := database.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		for _, update := range updates {
+			if _, err := update.Collection.UpdateMany(sessCtx, update.Filter, bson.M{"$set": update.Set}); err != nil {
+				return nil, err
+			}
+		}
+		return nil, remove(sessCtx)
+	})
+	if !transactionsUnsupported(err) {
+		return err
+	}
+
+	type savedDocuments struct {
+		Update    referenceUpdate
+		Documents []bson.M
+	}
+	saved := []savedDocuments{}
+	restore := func() {
+		for i := len(saved) - 1; i >= 0; i-- {
+			for _, document := range saved[i].Documents {
+				set, unset := bson.M{}, bson.M{}
+				for field := range saved[i].Update.Set {
+					if value, ok := document[field]; ok {
+						set[field] = value
+					} else {
+						unset[field] = ""
+					}
+				}
+				change := bson.M{}
+				if len(set) > 0 {
+					change["$set"] = set
+				}
+				if len(unset) > 0 {
+					change["$unset"] = unset
+				}
+				saved[i].Update.Collection.UpdateOne(ctx, bson.M{saved[i].Update.IdField: document[saved[i].Update.IdField]}, change)
+			}
+		}
+	}
+
+	for _, update := range updates {
+		projection := bson.M{"_id": 0, update.IdField: 1}
+		for field := range update.Set {
+			projection[field] = 1
+		}
+		documents, err := findDocuments(ctx, update.Collection, update.Filter, projection)
+		if err != nil {
+			restore()
+			return err
+		}
+		saved = append(saved, savedDocuments{Update: update, Documents: documents})
+		if _, err := update.Collection.UpdateMany(ctx, update.Filter, bson.M{"$set": update.Set}); err != nil {
+			restore()
+			return err
+		}
+	}
+	if err := remove(ctx); err != nil {
+		restore()
+		return err
+	}
+
+	return nil
+}
+
+func danglingReferences(ctx context.Context, collection *mongo.Collection, idField string, field string, target string, targetField string) ([]bson.M, error) {
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{field: bson.M{"$nin": bson.A{nil, ""}}}}},
+		bson.D{{Key: "$lookup", Value: bson.M{
+			"from":         target,
+			"localField":   field,
+			"foreignField": targetField,
+			"as":           "target",
+		}}},
+		bson.D{{Key: "$match", Value: bson.M{"target": bson.M{"$size": 0}}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":     0,
+			"id":      "$" + idField,
+			"user_id": 1,
+			"status":  1,
+			"missing": "$" + field,
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var items []bson.M
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []bson.M{}
+	}
+
+	return items, nil
+}
+
+// CheckIntegrity lists references that point at documents which no longer exist.
+func CheckIntegrity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := helper.CheckUserType(c, "ADMIN"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		checks := []struct {
+			Name        string
+			Collection  *mongo.Collection
+			IdField     string
+			Field       string
+			Target      string
+			TargetField string
+		}{
+			{"assessment_matrix", assessmentCollection, "assessment_id", "matrix_id", "matrix", "matrix_id"},
+			{"assessment_organization", assessmentCollection, "assessment_id", "organization_id", "organization", "organization_id"},
+			{"asset_organization", assetCollection, "asset_id", "organization_id", "organization", "organization_id"},
+			{"result_assessment", resultCollection, "result_id", "assessment_id", "assessment", "assessment_id"},
+			// Results are scored with a stored matrix version, which outlives the matrix itself.
+			{"result_matrix", resultCollection, "result_id", "matrix_id", "matrix_version", "matrix_id"},
+		}
+
+		report := gin.H{}
+		total := 0
+		for _, check := range checks {
+			items, err := danglingReferences(ctx, check.Collection, check.IdField, check.Field, check.Target, check.TargetField)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking integrity"})
+				return
+			}
+			report[check.Name] = items
+			total += len(items)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"total_count": total,
+			"dangling":    report,
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const quantitativeMatrixType = 4
+
+const maxSimulationIterations = 100000
+
+func isQuantitativeMatrix(matrix *models.Matrix) bool {
+	return matrix != nil && matrix.Type != nil && *matrix.Type == quantitativeMatrixType
+}
+
+// organizationExposure is the monetary value at risk: the sum of the values of the organization's asset records,
+// falling back to the embedded asset list and then to revenue for organizations without an inventory.
+func organizationExposure(organization models.Organization, assets []models.Asset) float64 {
+	exposure := 0.0
+	for _, asset := range assets {
+		if asset.Value != nil && *asset.Value > 0 {
+			exposure += *asset.Value
+		}
+	}
+	if exposure > 0 {
+		return exposure
+	}
+
+	for _, asset := range organization.Asset {
+		if asset != nil && asset.Value != nil && *asset.Value > 0 {
+			exposure += *asset.Value
+		}
+	}
+
+	if exposure == 0 && organization.Revenue != nil && *organization.Revenue > 0 {
+		exposure = *organization.Revenue
+	}
+
+	return exposure
+}
+
+// vulnerabilityExposure narrows the exposure to the assets a vulnerability is linked to, when it has any valued ones.
+func vulnerabilityExposure(vulnerability *models.Vulnerability, assetValues map[string]float64, organizationExposure float64) float64 {
+	exposure := 0.0
+	for _, assetId := range uniqueIds(vulnerability.Asset_id) {
+		exposure += assetValues[assetId]
+	}
+	if exposure > 0 {
+		return exposure
+	}
+
+	return organizationExposure
+}
+
+func toLoss(distribution helper.LossDistribution, exposure float64) *models.Loss {
+	iterations := distribution.Iterations
+	mean := distribution.Mean
+	p10 := distribution.P10
+	p50 := distribution.P50
+	p90 := distribution.P90
+	p95 := distribution.P95
+	p99 := distribution.P99
+
+	return &models.Loss{
+		Iterations: &iterations,
+		Exposure:   &exposure,
+		Mean:       &mean,
+		P10:        &p10,
+		P50:        &p50,
+		P90:        &p90,
+		P95:        &p95,
+		P99:        &p99,
+	}
+}
+
+func lossScenario(matrix *models.Matrix, impact *int, likelihood *int, exposure float64) (helper.LossScenario, bool) {
+	frequency := findLevel(matrix.Likelihood, likelihood)
+	loss := findLevel(matrix.Impact, impact)
+	if frequency == nil || frequency.Min == nil || frequency.Max == nil || loss == nil || loss.Min == nil || loss.Max == nil {
+		return helper.LossScenario{}, false
+	}
+
+	return helper.LossScenario{
+		Frequency: helper.LossRange{Min: *frequency.Min, Max: *frequency.Max},
+		Magnitude: helper.LossRange{Min: *loss.Min * exposure, Max: *loss.Max * exposure},
+	}, true
+}
+
+// saveQuantifiedResult quantifies a result that is being saved. Losses are derived, so a failure only leaves
+// them empty and is logged; POST /results/:result_id/quantify can retry it.
+func saveQuantifiedResult(ctx context.Context, result *models.Result) {
+	if err := quantifyResult(ctx, result, helper.DefaultSimulationIterations); err != nil {
+		log.Printf("Failed to quantify result %s: %v", result.Result_id, err)
+	}
+}
+
+// quantifyResult fills the annualized loss percentiles of result when it was scored with a quantitative matrix.
+// Inherent loss uses impact/likelihood and residual loss uses new_impact/new_likelihood.
+func quantifyResult(ctx context.Context, result *models.Result, iterations int) error {
+	content := result.Content
+	if content == nil {
+		return nil
+	}
+
+	content.Loss = nil
+	content.New_loss = nil
+	for _, vulnerability := range content.Vulnerability {
+		if vulnerability != nil {
+			vulnerability.Loss = nil
+			vulnerability.New_loss = nil
+		}
+	}
+
+	if result.Assessment_id == nil {
+		return nil
+	}
+
+	// Losses are only simulated on the matrix version the result was scored with, never on the live matrix.
+	matrixVersion, _, _, err := resultMatrixVersion(ctx, *result)
+	if err == errResultNoMatrix {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	matrix := matrixVersion.Matrix
+
+	if !isQuantitativeMatrix(matrix) {
+		return nil
+	}
+
+	var assessment models.Assessment
+	if err := assessmentCollection.FindOne(ctx, bson.M{"assessment_id": result.Assessment_id}).Decode(&assessment); err != nil {
+		return err
+	}
+	if assessment.Organization_id == nil || *assessment.Organization_id == "" {
+		return nil
+	}
+
+	var organization models.Organization
+	if err := organizationCollection.FindOne(ctx, bson.M{"organization_id": assessment.Organization_id}).Decode(&organization); err != nil {
+		return err
+	}
+
+	cursor, err := assetCollection.Find(ctx, bson.M{"organization_id": organization.Organization_id, "status": 1})
+	if err != nil {
+		return err
+	}
+	var assets []models.Asset
+	if err = cursor.All(ctx, &assets); err != nil {
+		return err
+	}
+
+	assetValues := map[string]float64{}
+	for _, asset := range assets {
+		if asset.Value != nil && *asset.Value > 0 {
+			assetValues[asset.Asset_id] = *asset.Value
+		}
+	}
+
+	exposure := organizationExposure(organization, assets)
+	if exposure == 0 {
+		return nil
+	}
+
+	var inherent, residual []helper.LossScenario
+	var inherentOwners, residualOwners []*models.Vulnerability
+	var inherentExposure, residualExposure []float64
+	for _, vulnerability := range content.Vulnerability {
+		if vulnerability == nil {
+			continue
+		}
+		assetExposure := vulnerabilityExposure(vulnerability, assetValues, exposure)
+		if scenario, ok := lossScenario(matrix, vulnerability.Impact, vulnerability.Likelihood, assetExposure); ok {
+			inherent = append(inherent, scenario)
+			inherentOwners = append(inherentOwners, vulnerability)
+			inherentExposure = append(inherentExposure, assetExposure)
+		}
+		if scenario, ok := lossScenario(matrix, vulnerability.New_impact, vulnerability.New_likelihood, assetExposure); ok {
+			residual = append(residual, scenario)
+			residualOwners = append(residualOwners, vulnerability)
+			residualExposure = append(residualExposure, assetExposure)
+		}
+	}
+
+	// Seeding from the result id keeps the percentiles stable when the same result is recomputed.
+	seed := int64(crc32.ChecksumIEEE([]byte(result.Result_id)))
+
+	if len(inherent) > 0 {
+		distributions, total := helper.SimulateLoss(inherent, iterations, seed)
+		for i, vulnerability := range inherentOwners {
+			vulnerability.Loss = toLoss(distributions[i], inherentExposure[i])
+		}
+		content.Loss = toLoss(total, exposure)
+	}
+
+	if len(residual) > 0 {
+		distributions, total := helper.SimulateLoss(residual, iterations, seed+1)
+		for i, vulnerability := range residualOwners {
+			vulnerability.New_loss = toLoss(distributions[i], residualExposure[i])
+		}
+		content.New_loss = toLoss(total, exposure)
+	}
+
+	return nil
+}
+
+func QuantifyResult() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resultId := c.Param("result_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var result models.Result
+		err := resultCollection.FindOne(ctx, bson.M{"result_id": resultId}).Decode(&result)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "result not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching result"})
+			return
+		}
+
+		if c.GetString("user_type") != "ADMIN" {
+			if result.User_id == nil || *result.User_id != c.GetString("uid") || (result.Status != nil && *result.Status != 1) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to update this result"})
+				return
+			}
+		}
+
+		iterations := helper.DefaultSimulationIterations
+		if c.Query("iterations") != "" {
+			iterations, err = strconv.Atoi(c.Query("iterations"))
+			if err != nil || iterations < 1 || iterations > maxSimulationIterations {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("iterations must be between 1 and %d", maxSimulationIterations)})
+				return
+			}
+		}
+
+		if err := quantifyResult(ctx, &result, iterations); err != nil {
+			if err == errMatrixVersionNotFound {
+				c.JSON(http.StatusConflict, gin.H{"error": "matrix version not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to quantify result"})
+			return
+		}
+
+		_, err = resultCollection.UpdateOne(
+			ctx,
+			bson.M{"result_id": resultId},
+			bson.M{"$set": bson.M{
+				"content":    result.Content,
+				"updated_at": time.Now().Format(time.RFC3339),
+			}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update result"})
+			return
+		}
+
+		c.JSON(http.StatusOK, result.Content)
+	}
+}
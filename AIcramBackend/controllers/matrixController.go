@@ -54,19 +54,29 @@ func GetMatrices() gin.HandlerFunc {
 			return
 		}
 
+		templateParam := c.Query("template")
+		templateFilter := bson.M{"template": bson.M{"$nin": bson.A{nil, ""}}, "status": 1}
+
 		var matchStage bson.D
 		if userType == "ADMIN" {
 			queryUserId := c.Query("user_id")
-			if queryUserId != "" {
+			if templateParam == "true" {
+				matchStage = bson.D{{Key: "$match", Value: templateFilter}}
+			} else if queryUserId != "" {
 				matchStage = bson.D{{"$match", bson.D{{"user_id", queryUserId}}}}
 			} else {
 				matchStage = bson.D{{"$match", bson.D{{}}}}
 			}
 		} else {
-			matchStage = bson.D{{"$match", bson.D{
-				{"user_id", userId},
-				{"status", 1},
-			}}}
+			ownFilter := bson.M{"user_id": userId, "status": 1}
+			switch templateParam {
+			case "true":
+				matchStage = bson.D{{Key: "$match", Value: templateFilter}}
+			case "all":
+				matchStage = bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{ownFilter, templateFilter}}}}
+			default:
+				matchStage = bson.D{{Key: "$match", Value: ownFilter}}
+			}
 		}
 
 		sortStage := bson.D{{"$sort", bson.D{{"created_at", -1}}}}
@@ -129,8 +139,8 @@ func GetMatrix() gin.HandlerFunc {
 
 		transactionParam := c.Query("transaction")
 
-		if userType != "ADMIN" {
-			if (*matrix.User_id != userId.(string) && transactionParam != "true") || (matrix.Status != nil && *matrix.Status != 1) {
+		if userType != "ADMIN" && !isTemplateMatrix(matrix) {
+			if matrix.User_id == nil || (*matrix.User_id != userId.(string) && transactionParam != "true") || (matrix.Status != nil && *matrix.Status != 1) {
 				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this matrix"})
 				return
 			}
@@ -158,6 +168,12 @@ func CreateMatrix() gin.HandlerFunc {
 			return
 		}
 
+		if err := validateMatrixLevels(matrix); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		matrix.Type = matrixType(matrix)
+
 		userType, exists := c.Get("user_type")
 		if !exists {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "user type not found in context"})
@@ -193,10 +209,14 @@ func CreateMatrix() gin.HandlerFunc {
 			matrix.Status = &status
 		}
 
+		matrix.Template = nil
+		matrix.Cloned_from = nil
+
 		matrix.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		matrix.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		matrix.ID = primitive.NewObjectID()
 		matrix.Matrix_id = matrix.ID.Hex()
+		matrix.Version = nil
 
 		resultInsertionNumber, insertErr := matrixCollection.InsertOne(ctx, matrix)
 		if insertErr != nil {
@@ -204,6 +224,11 @@ func CreateMatrix() gin.HandlerFunc {
 			return
 		}
 
+		if _, err := recordMatrixVersion(ctx, matrix, matrix.User_id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record matrix version"})
+			return
+		}
+
 		c.JSON(http.StatusOK, resultInsertionNumber)
 	}
 }
@@ -237,6 +262,11 @@ func UpdateMatrix() gin.HandlerFunc {
 			return
 		}
 
+		if isTemplateMatrix(existingMatrix) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "template matrices are read-only"})
+			return
+		}
+
 		if userType != "ADMIN" {
 			if *existingMatrix.User_id != userId.(string) || (existingMatrix.Status != nil && *existingMatrix.Status != 1) {
 				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to update this matrix"})
@@ -250,6 +280,12 @@ func UpdateMatrix() gin.HandlerFunc {
 			return
 		}
 
+		// Snapshot legacy matrices before their first edit so earlier results keep a version to point at.
+		if _, err := recordMatrixVersion(ctx, existingMatrix, existingMatrix.User_id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record matrix version"})
+			return
+		}
+
 		update := bson.M{}
 
 		if userType != "ADMIN" {
@@ -263,42 +299,44 @@ func UpdateMatrix() gin.HandlerFunc {
 		if updateData.Status != nil && userType == "ADMIN" {
 			update["status"] = updateData.Status
 		}
-		if updateData.Type != nil {
-			update["type"] = updateData.Type
-		}
 		if updateData.Description != nil {
 			update["description"] = updateData.Description
 		}
-		if updateData.Impact_1 != nil {
-			update["impact_1"] = updateData.Impact_1
+		if updateData.Impact != nil {
+			update["impact"] = updateData.Impact
 		}
-		if updateData.Impact_2 != nil {
-			update["impact_2"] = updateData.Impact_2
+		if updateData.Likelihood != nil {
+			update["likelihood"] = updateData.Likelihood
 		}
-		if updateData.Impact_3 != nil {
-			update["impact_3"] = updateData.Impact_3
+
+		merged := existingMatrix
+		if updateData.Name != nil {
+			merged.Name = updateData.Name
 		}
-		if updateData.Impact_4 != nil {
-			update["impact_4"] = updateData.Impact_4
+		if _, ok := update["status"]; ok {
+			merged.Status = updateData.Status
 		}
-		if updateData.Impact_5 != nil {
-			update["impact_5"] = updateData.Impact_5
+		if updateData.Type != nil {
+			merged.Type = updateData.Type
 		}
-		if updateData.Likelihood_1 != nil {
-			update["likelihood_1"] = updateData.Likelihood_1
+		if updateData.Description != nil {
+			merged.Description = updateData.Description
 		}
-		if updateData.Likelihood_2 != nil {
-			update["likelihood_2"] = updateData.Likelihood_2
+		if updateData.Impact != nil {
+			merged.Impact = updateData.Impact
 		}
-		if updateData.Likelihood_3 != nil {
-			update["likelihood_3"] = updateData.Likelihood_3
+		if updateData.Likelihood != nil {
+			merged.Likelihood = updateData.Likelihood
 		}
-		if updateData.Likelihood_4 != nil {
-			update["likelihood_4"] = updateData.Likelihood_4
+		if validationErr := matrixValidate.Struct(merged); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
 		}
-		if updateData.Likelihood_5 != nil {
-			update["likelihood_5"] = updateData.Likelihood_5
+		if err := validateMatrixLevels(merged); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
+		update["type"] = matrixType(merged)
 
 		update["updated_at"] = time.Now().Format(time.RFC3339)
 
@@ -317,6 +355,15 @@ func UpdateMatrix() gin.HandlerFunc {
 			return
 		}
 
+		var updatedMatrix models.Matrix
+		if err := matrixCollection.FindOne(ctx, bson.M{"matrix_id": matrixId}).Decode(&updatedMatrix); err == nil {
+			uid := userId.(string)
+			if _, err := recordMatrixVersion(ctx, updatedMatrix, &uid); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record matrix version"})
+				return
+			}
+		}
+
 		c.JSON(http.StatusOK, result.ModifiedCount)
 	}
 }
@@ -332,7 +379,23 @@ func DeleteMatrix() gin.HandlerFunc {
 		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		result, err := matrixCollection.DeleteOne(ctx, bson.M{"matrix_id": matrixId})
+		templateCount, err := matrixCollection.CountDocuments(ctx, bson.M{"matrix_id": matrixId, "template": bson.M{"$nin": bson.A{nil, ""}}})
+		if err == nil && templateCount > 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "template matrices are read-only"})
+			return
+		}
+
+		updates, ok := resolveAssessmentReferences(c, ctx, matrixReference, matrixId, true)
+		if !ok {
+			return
+		}
+
+		var result *mongo.DeleteResult
+		err = applyReferenceUpdates(ctx, updates, func(ctx context.Context) error {
+			var err error
+			result, err = matrixCollection.DeleteOne(ctx, bson.M{"matrix_id": matrixId})
+			return err
+		})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete matrix"})
 			return
@@ -371,6 +434,11 @@ func RemoveMatrix() gin.HandlerFunc {
 			return
 		}
 
+		if isTemplateMatrix(existingMatrix) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "template matrices are read-only"})
+			return
+		}
+
 		if userType != "ADMIN" {
 			if *existingMatrix.User_id != userId.(string) || (existingMatrix.Status != nil && *existingMatrix.Status != 1) {
 				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to remove this matrix"})
@@ -378,27 +446,119 @@ func RemoveMatrix() gin.HandlerFunc {
 			}
 		}
 
+		updates, ok := resolveAssessmentReferences(c, ctx, matrixReference, matrixId, false)
+		if !ok {
+			return
+		}
+
 		status := 2
 		update := bson.M{
 			"status":     status,
 			"updated_at": time.Now().Format(time.RFC3339),
 		}
 
-		result, err := matrixCollection.UpdateOne(
-			ctx,
-			bson.M{"matrix_id": matrixId},
-			bson.M{"$set": update},
-		)
+		var result *mongo.UpdateResult
+		err = applyReferenceUpdates(ctx, updates, func(ctx context.Context) error {
+			var err error
+			result, err = matrixCollection.UpdateOne(
+				ctx,
+				bson.M{"matrix_id": matrixId},
+				bson.M{"$set": update},
+			)
+			if err == nil && result.MatchedCount == 0 {
+				return errReferenceTargetNotFound
+			}
+			return err
+		})
+		if err == errReferenceTargetNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "matrix not found"})
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove matrix"})
 			return
 		}
 
-		if result.MatchedCount == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "matrix not found"})
+		c.JSON(http.StatusOK, result.ModifiedCount)
+	}
+}
+
+func CloneMatrix() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		matrixId := c.Param("matrix_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var source models.Matrix
+		err := matrixCollection.FindOne(ctx, bson.M{"matrix_id": matrixId}).Decode(&source)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "matrix not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching matrix"})
 			return
 		}
 
-		c.JSON(http.StatusOK, result.ModifiedCount)
+		userType := c.GetString("user_type")
+		userId := c.GetString("uid")
+
+		if userType != "ADMIN" && !isTemplateMatrix(source) {
+			if source.Status == nil || *source.Status != 1 {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to clone this matrix"})
+				return
+			}
+		}
+
+		var cloneData struct {
+			Name *string `json:"name" validate:"omitempty,min=2,max=100"`
+		}
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&cloneData); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if validationErr := matrixValidate.Struct(cloneData); validationErr != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+		}
+
+		matrix := source
+		if cloneData.Name != nil {
+			matrix.Name = cloneData.Name
+		} else {
+			name := []rune(*source.Name)
+			if len(name) > 93 {
+				name = name[:93]
+			}
+			copyName := string(name) + " (Copy)"
+			matrix.Name = &copyName
+		}
+
+		status := 1
+		sourceId := source.Matrix_id
+		matrix.User_id = &userId
+		matrix.Status = &status
+		matrix.Template = nil
+		matrix.Cloned_from = &sourceId
+		matrix.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		matrix.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		matrix.ID = primitive.NewObjectID()
+		matrix.Matrix_id = matrix.ID.Hex()
+		matrix.Version = nil
+
+		_, insertErr := matrixCollection.InsertOne(ctx, matrix)
+		if insertErr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clone matrix"})
+			return
+		}
+
+		if _, err := recordMatrixVersion(ctx, matrix, matrix.User_id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record matrix version"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"matrix_id": matrix.Matrix_id})
 	}
 }
@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Matrices used to store exactly five impact_N/likelihood_N fields, where Type 1 (3x3) used slots 2-4,
+// Type 2 (4x4) slots 2-5 and Type 3 (5x5) all five. Slot numbers are kept as level values so existing
+// vulnerability scores still point at the same level after migration.
+var legacySlotLabels = [5]string{"Very Low", "Low", "Medium", "High", "Extreme"}
+
+type legacyRange struct {
+	Min *float64
+	Max *float64
+}
+
+type legacyMatrix struct {
+	Type         *int
+	Impact_1     *string
+	Impact_2     *string
+	Impact_3     *string
+	Impact_4     *string
+	Impact_5     *string
+	Likelihood_1 *string
+	Likelihood_2 *string
+	Likelihood_3 *string
+	Likelihood_4 *string
+	Likelihood_5 *string
+	Frequency_1  *legacyRange
+	Frequency_2  *legacyRange
+	Frequency_3  *legacyRange
+	Frequency_4  *legacyRange
+	Frequency_5  *legacyRange
+	Loss_1       *legacyRange
+	Loss_2       *legacyRange
+	Loss_3       *legacyRange
+	Loss_4       *legacyRange
+	Loss_5       *legacyRange
+}
+
+var legacyMatrixFields = []string{
+	"impact_1", "impact_2", "impact_3", "impact_4", "impact_5",
+	"likelihood_1", "likelihood_2", "likelihood_3", "likelihood_4", "likelihood_5",
+	"frequency_1", "frequency_2", "frequency_3", "frequency_4", "frequency_5",
+	"loss_1", "loss_2", "loss_3", "loss_4", "loss_5",
+}
+
+func legacySlots(matrixType *int) (int, int) {
+	if matrixType != nil {
+		switch *matrixType {
+		case 1:
+			return 2, 4
+		case 2:
+			return 2, 5
+		}
+	}
+
+	return 1, 5
+}
+
+func legacyLevels(matrixType *int, texts [5]*string, bounds [5]*legacyRange) []*models.Level {
+	first, last := legacySlots(matrixType)
+
+	levels := []*models.Level{}
+	for slot := first; slot <= last; slot++ {
+		value := slot
+		label := legacySlotLabels[slot-1]
+		level := &models.Level{
+			Value:       &value,
+			Label:       &label,
+			Description: texts[slot-1],
+		}
+		if bounds[slot-1] != nil {
+			level.Min = bounds[slot-1].Min
+			level.Max = bounds[slot-1].Max
+		}
+		levels = append(levels, level)
+	}
+
+	return levels
+}
+
+func (m legacyMatrix) levels() ([]*models.Level, []*models.Level) {
+	impact := legacyLevels(
+		m.Type,
+		[5]*string{m.Impact_1, m.Impact_2, m.Impact_3, m.Impact_4, m.Impact_5},
+		[5]*legacyRange{m.Loss_1, m.Loss_2, m.Loss_3, m.Loss_4, m.Loss_5},
+	)
+	likelihood := legacyLevels(
+		m.Type,
+		[5]*string{m.Likelihood_1, m.Likelihood_2, m.Likelihood_3, m.Likelihood_4, m.Likelihood_5},
+		[5]*legacyRange{m.Frequency_1, m.Frequency_2, m.Frequency_3, m.Frequency_4, m.Frequency_5},
+	)
+
+	return impact, likelihood
+}
+
+func legacyUnset(prefix string) bson.M {
+	unset := bson.M{}
+	for _, field := range legacyMatrixFields {
+		unset[prefix+field] = ""
+	}
+
+	return unset
+}
+
+// MigrateMatrixLevels converts matrices and stored matrix versions from the fixed five-slot fields to level arrays.
+func MigrateMatrixLevels() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	migrated, err := migrateLegacyMatrices(ctx, matrixCollection, "", "matrix_id")
+	if err != nil {
+		log.Printf("Failed to migrate matrix levels: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated %d matrices to level arrays", migrated)
+	}
+
+	migrated, err = migrateLegacyMatrices(ctx, matrixVersionCollection, "matrix.", "version_id")
+	if err != nil {
+		log.Printf("Failed to migrate matrix version levels: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated %d matrix versions to level arrays", migrated)
+	}
+}
+
+func migrateLegacyMatrices(ctx context.Context, collection *mongo.Collection, prefix string, idField string) (int, error) {
+	cursor, err := collection.Find(ctx, bson.M{
+		prefix + "impact": bson.M{"$exists": false},
+		prefix + "type":   bson.M{"$exists": true},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		id, ok := cursor.Current.Lookup(idField).StringValueOK()
+		if !ok {
+			continue
+		}
+
+		document := cursor.Current
+		if prefix != "" {
+			nested, ok := cursor.Current.Lookup("matrix").DocumentOK()
+			if !ok {
+				continue
+			}
+			document = nested
+		}
+
+		var legacy legacyMatrix
+		if err := bson.Unmarshal(document, &legacy); err != nil {
+			return migrated, err
+		}
+
+		impact, likelihood := legacy.levels()
+		_, err = collection.UpdateOne(
+			ctx,
+			bson.M{idField: id},
+			bson.M{
+				"$set": bson.M{
+					prefix + "impact":     impact,
+					prefix + "likelihood": likelihood,
+				},
+				"$unset": legacyUnset(prefix),
+			},
+		)
+		if err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+
+	return migrated, cursor.Err()
+}
+
+// normalizeMatrixLevels numbers levels 1..N when the client left every value empty.
+func normalizeMatrixLevels(levels []*models.Level) {
+	for _, level := range levels {
+		if level == nil || level.Value != nil {
+			return
+		}
+	}
+
+	for i, level := range levels {
+		value := i + 1
+		level.Value = &value
+	}
+}
+
+func validateLevels(axis string, levels []*models.Level, quantitative bool) error {
+	previous := 0
+	for i, level := range levels {
+		if level == nil {
+			return fmt.Errorf("%s level %d is required", axis, i+1)
+		}
+		if level.Value == nil {
+			return fmt.Errorf("%s levels must either all have a value or none", axis)
+		}
+		if *level.Value <= previous {
+			return fmt.Errorf("%s level values must be strictly ascending", axis)
+		}
+		previous = *level.Value
+
+		if quantitative && (level.Min == nil || level.Max == nil) {
+			return fmt.Errorf("%s level %d needs min and max for quantitative matrices", axis, i+1)
+		}
+		if level.Min != nil && level.Max != nil && *level.Max < *level.Min {
+			return fmt.Errorf("%s level %d must have min <= max", axis, i+1)
+		}
+	}
+
+	return nil
+}
+
+// validateMatrixLevels checks both axes of a matrix of any N x M size. Quantitative matrices map every likelihood
+// level to an annual frequency range and every impact level to a loss range expressed as a fraction (0-1) of the
+// exposed asset value.
+func validateMatrixLevels(matrix models.Matrix) error {
+	normalizeMatrixLevels(matrix.Impact)
+	normalizeMatrixLevels(matrix.Likelihood)
+
+	quantitative := isQuantitativeMatrix(&matrix)
+	if err := validateLevels("impact", matrix.Impact, quantitative); err != nil {
+		return err
+	}
+	if err := validateLevels("likelihood", matrix.Likelihood, quantitative); err != nil {
+		return err
+	}
+
+	if quantitative {
+		for i, level := range matrix.Impact {
+			if *level.Max > 1 {
+				return fmt.Errorf("impact level %d loss must be a fraction between 0 and 1", i+1)
+			}
+		}
+	}
+
+	return nil
+}
+
+// matrixType keeps quantitative matrices at quantitativeMatrixType and otherwise derives the former size type from the
+// levels: 1 for 3x3, 2 for 4x4, 3 for 5x5 and nil for every other size.
+func matrixType(matrix models.Matrix) *int {
+	if isQuantitativeMatrix(&matrix) {
+		return matrix.Type
+	}
+
+	size := len(matrix.Impact)
+	if size != len(matrix.Likelihood) || size < 3 || size > 5 {
+		return nil
+	}
+	legacyType := size - 2
+
+	return &legacyType
+}
+
+func findLevel(levels []*models.Level, value *int) *models.Level {
+	if value == nil {
+		return nil
+	}
+
+	for _, level := range levels {
+		if level != nil && level.Value != nil && *level.Value == *value {
+			return level
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,560 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/database"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var matrixVersionCollection *mongo.Collection = database.OpenCollection(database.Client, "matrix_version")
+
+var errMatrixVersionConflict = errors.New("matrix version conflict")
+
+func EnsureMatrixVersionIndex() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	unique := true
+	_, err := matrixVersionCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "matrix_id", Value: 1}, {Key: "version", Value: 1}},
+		Options: &options.IndexOptions{Unique: &unique},
+	})
+	if err != nil {
+		log.Printf("Failed to create matrix version index: %v", err)
+	}
+}
+
+// RecordMatrixVersions snapshots every matrix that has no stored version yet, so that reading versions never has to.
+func RecordMatrixVersions() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	cursor, err := matrixCollection.Find(ctx, bson.M{})
+	if err != nil {
+		log.Printf("Failed to record matrix versions: %v", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	recorded := 0
+	for cursor.Next(ctx) {
+		var matrix models.Matrix
+		if err := cursor.Decode(&matrix); err != nil {
+			continue
+		}
+		latest, err := latestMatrixVersion(ctx, matrix.Matrix_id)
+		if err != nil || latest != nil {
+			continue
+		}
+		if _, err := recordMatrixVersion(ctx, matrix, matrix.User_id); err != nil {
+			log.Printf("Failed to record version of matrix %s: %v", matrix.Matrix_id, err)
+			continue
+		}
+		recorded++
+	}
+	if recorded > 0 {
+		log.Printf("Recorded versions of %d matrices", recorded)
+	}
+}
+
+func isDuplicateKeyError(err error) bool {
+	var writeErr mongo.WriteException
+	if errors.As(err, &writeErr) {
+		for _, e := range writeErr.WriteErrors {
+			if e.Code == 11000 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type matrixField struct {
+	Name  string
+	Value func(m *models.Matrix) interface{}
+}
+
+func stringValue(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return *value
+}
+
+func intValue(value *int) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return *value
+}
+
+func floatValue(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	return *value
+}
+
+// matrixDefinitionFields are the parts of a matrix that change the meaning of a score; status and ownership are not versioned.
+var matrixDefinitionFields = []matrixField{
+	{"name", func(m *models.Matrix) interface{} { return stringValue(m.Name) }},
+	{"type", func(m *models.Matrix) interface{} { return intValue(m.Type) }},
+	{"description", func(m *models.Matrix) interface{} { return stringValue(m.Description) }},
+}
+
+type levelField struct {
+	Name  string
+	Value func(l *models.Level) interface{}
+}
+
+var levelDefinitionFields = []levelField{
+	{"value", func(l *models.Level) interface{} { return intValue(l.Value) }},
+	{"label", func(l *models.Level) interface{} { return stringValue(l.Label) }},
+	{"description", func(l *models.Level) interface{} { return stringValue(l.Description) }},
+	{"min", func(l *models.Level) interface{} { return floatValue(l.Min) }},
+	{"max", func(l *models.Level) interface{} { return floatValue(l.Max) }},
+}
+
+func levelAt(levels []*models.Level, index int) *models.Level {
+	if index < len(levels) {
+		return levels[index]
+	}
+
+	return nil
+}
+
+// diffLevels reports added and removed levels as a whole and changed levels field by field, e.g. impact[2].label.
+func diffLevels(axis string, from []*models.Level, to []*models.Level) []gin.H {
+	changes := []gin.H{}
+
+	count := len(from)
+	if len(to) > count {
+		count = len(to)
+	}
+
+	for i := 0; i < count; i++ {
+		fromLevel := levelAt(from, i)
+		toLevel := levelAt(to, i)
+		name := fmt.Sprintf("%s[%d]", axis, i)
+
+		if fromLevel == nil || toLevel == nil {
+			if fromLevel != toLevel {
+				changes = append(changes, gin.H{"field": name, "from": fromLevel, "to": toLevel})
+			}
+			continue
+		}
+
+		for _, field := range levelDefinitionFields {
+			fromValue := field.Value(fromLevel)
+			toValue := field.Value(toLevel)
+			if fromValue != toValue {
+				changes = append(changes, gin.H{
+					"field": name + "." + field.Name,
+					"from":  fromValue,
+					"to":    toValue,
+				})
+			}
+		}
+	}
+
+	return changes
+}
+
+func diffMatrices(from *models.Matrix, to *models.Matrix) []gin.H {
+	changes := []gin.H{}
+	for _, field := range matrixDefinitionFields {
+		fromValue := field.Value(from)
+		toValue := field.Value(to)
+		if fromValue != toValue {
+			changes = append(changes, gin.H{
+				"field": field.Name,
+				"from":  fromValue,
+				"to":    toValue,
+			})
+		}
+	}
+
+	changes = append(changes, diffLevels("impact", from.Impact, to.Impact)...)
+	changes = append(changes, diffLevels("likelihood", from.Likelihood, to.Likelihood)...)
+
+	return changes
+}
+
+func latestMatrixVersion(ctx context.Context, matrixId string) (*models.MatrixVersion, error) {
+	var version models.MatrixVersion
+	err := matrixVersionCollection.FindOne(
+		ctx,
+		bson.M{"matrix_id": matrixId},
+		options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}),
+	).Decode(&version)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// recordMatrixVersion snapshots matrix if its definition differs from the latest stored version and returns the current version number.
+func recordMatrixVersion(ctx context.Context, matrix models.Matrix, userId *string) (int, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		latest, err := latestMatrixVersion(ctx, matrix.Matrix_id)
+		if err != nil {
+			return 0, err
+		}
+
+		current := 0
+		if latest != nil {
+			current = latest.Version
+			if latest.Matrix != nil && len(diffMatrices(latest.Matrix, &matrix)) == 0 {
+				if matrix.Version == nil || *matrix.Version != current {
+					matrixCollection.UpdateOne(ctx, bson.M{"matrix_id": matrix.Matrix_id}, bson.M{"$set": bson.M{"version": current}})
+				}
+				return current, nil
+			}
+		}
+
+		next := current + 1
+		snapshot := matrix
+		snapshot.Version = &next
+
+		version := models.MatrixVersion{
+			ID:         primitive.NewObjectID(),
+			Matrix_id:  matrix.Matrix_id,
+			Version:    next,
+			User_id:    userId,
+			Matrix:     &snapshot,
+			Created_at: time.Now(),
+		}
+		version.Version_id = version.ID.Hex()
+
+		_, err = matrixVersionCollection.InsertOne(ctx, version)
+		if isDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = matrixCollection.UpdateOne(ctx, bson.M{"matrix_id": matrix.Matrix_id}, bson.M{"$set": bson.M{"version": next}})
+		if err != nil {
+			return 0, err
+		}
+
+		return next, nil
+	}
+
+	return 0, errMatrixVersionConflict
+}
+
+func currentMatrixVersion(ctx context.Context, matrixId string) (int, error) {
+	var matrix models.Matrix
+	if err := matrixCollection.FindOne(ctx, bson.M{"matrix_id": matrixId}).Decode(&matrix); err != nil {
+		return 0, err
+	}
+
+	return recordMatrixVersion(ctx, matrix, matrix.User_id)
+}
+
+// storedMatrixVersion returns the latest stored version number of the matrix, or 0 when it has none. It does not read
+// the matrix itself, so versions stay reachable after the matrix is deleted.
+func storedMatrixVersion(ctx context.Context, matrixId string) (int, error) {
+	latest, err := latestMatrixVersion(ctx, matrixId)
+	if err != nil || latest == nil {
+		return 0, err
+	}
+
+	return latest.Version, nil
+}
+
+func findMatrixVersion(ctx context.Context, matrixId string, version int) (*models.MatrixVersion, error) {
+	var matrixVersion models.MatrixVersion
+	err := matrixVersionCollection.FindOne(ctx, bson.M{"matrix_id": matrixId, "version": version}).Decode(&matrixVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &matrixVersion, nil
+}
+
+func canViewMatrix(c *gin.Context, matrix models.Matrix) bool {
+	if c.GetString("user_type") == "ADMIN" || isTemplateMatrix(matrix) {
+		return true
+	}
+	if matrix.Status != nil && *matrix.Status != 1 {
+		return false
+	}
+
+	return matrix.User_id != nil && (*matrix.User_id == c.GetString("uid") || c.Query("transaction") == "true")
+}
+
+// findViewableMatrix loads the matrix whose versions the caller may view. Versions outlive their matrix so that
+// pinned results stay readable, so a deleted matrix is authorized against its latest stored version.
+func findViewableMatrix(c *gin.Context, ctx context.Context) (*models.Matrix, bool) {
+	var matrix models.Matrix
+	err := matrixCollection.FindOne(ctx, bson.M{"matrix_id": c.Param("matrix_id")}).Decode(&matrix)
+	if err == mongo.ErrNoDocuments {
+		var latest *models.MatrixVersion
+		if latest, err = latestMatrixVersion(ctx, c.Param("matrix_id")); err == nil {
+			if latest == nil || latest.Matrix == nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "matrix not found"})
+				return nil, false
+			}
+			matrix = *latest.Matrix
+			matrix.Matrix_id = latest.Matrix_id
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching matrix"})
+		return nil, false
+	}
+
+	if !canViewMatrix(c, matrix) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this matrix"})
+		return nil, false
+	}
+
+	return &matrix, true
+}
+
+func GetMatrixVersions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		matrix, ok := findViewableMatrix(c, ctx)
+		if !ok {
+			return
+		}
+
+		cursor, err := matrixVersionCollection.Find(
+			ctx,
+			bson.M{"matrix_id": matrix.Matrix_id},
+			options.Find().SetSort(bson.D{{Key: "version", Value: -1}}),
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing matrix versions"})
+			return
+		}
+
+		var versions []models.MatrixVersion
+		if err = cursor.All(ctx, &versions); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing matrix versions"})
+			return
+		}
+		if versions == nil {
+			versions = []models.MatrixVersion{}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"total_count":   len(versions),
+			"version_items": versions,
+		})
+	}
+}
+
+func GetMatrixVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		matrix, ok := findViewableMatrix(c, ctx)
+		if !ok {
+			return
+		}
+
+		version, err := strconv.Atoi(c.Param("version"))
+		if err != nil || version < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+			return
+		}
+
+		matrixVersion, err := findMatrixVersion(ctx, matrix.Matrix_id, version)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "matrix version not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching matrix version"})
+			return
+		}
+
+		c.JSON(http.StatusOK, matrixVersion)
+	}
+}
+
+func DiffMatrixVersions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		matrix, ok := findViewableMatrix(c, ctx)
+		if !ok {
+			return
+		}
+
+		current, err := storedMatrixVersion(ctx, matrix.Matrix_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching matrix versions"})
+			return
+		}
+
+		from, err := strconv.Atoi(c.Query("from"))
+		if err != nil || from < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from version"})
+			return
+		}
+
+		to := current
+		if c.Query("to") != "" {
+			to, err = strconv.Atoi(c.Query("to"))
+			if err != nil || to < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to version"})
+				return
+			}
+		}
+
+		fromVersion, err := findMatrixVersion(ctx, matrix.Matrix_id, from)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "matrix version not found"})
+			return
+		}
+
+		toVersion, err := findMatrixVersion(ctx, matrix.Matrix_id, to)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "matrix version not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"matrix_id": matrix.Matrix_id,
+			"from":      from,
+			"to":        to,
+			"changes":   diffMatrices(fromVersion.Matrix, toVersion.Matrix),
+		})
+	}
+}
+
+func GetResultMatrix() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		result, ok := findResult(c, ctx, c.Param("result_id"))
+		if !ok {
+			return
+		}
+
+		matrixVersion, current, pinned, err := resultMatrixVersion(ctx, *result)
+		if err != nil {
+			switch err {
+			case errResultNoMatrix:
+				c.JSON(http.StatusNotFound, gin.H{"error": "result has no matrix"})
+			case errMatrixVersionNotFound:
+				c.JSON(http.StatusNotFound, gin.H{"error": "matrix version not found"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching matrix"})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"result_id":       result.Result_id,
+			"matrix_id":       matrixVersion.Matrix_id,
+			"version":         matrixVersion.Version,
+			"current_version": current,
+			"pinned":          pinned,
+			"outdated":        matrixVersion.Version != current,
+			"matrix":          matrixVersion.Matrix,
+			"content":         result.Content,
+		})
+	}
+}
+
+var errResultNoMatrix = errors.New("result has no matrix")
+var errMatrixVersionNotFound = errors.New("matrix version not found")
+
+// resultMatrixVersion returns the matrix version a result was scored with, the matrix's current version and
+// whether the version was pinned rather than defaulting to the current one.
+func resultMatrixVersion(ctx context.Context, result models.Result) (*models.MatrixVersion, int, bool, error) {
+	matrixId := result.Matrix_id
+	pinned := result.Matrix_version
+	if (matrixId == nil || *matrixId == "") && result.Assessment_id != nil {
+		// Results created before versioning fall back to the assessment's matrix.
+		var assessment models.Assessment
+		if err := assessmentCollection.FindOne(ctx, bson.M{"assessment_id": result.Assessment_id}).Decode(&assessment); err == nil {
+			matrixId = assessment.Matrix_id
+			pinned = assessment.Matrix_version
+		}
+	}
+
+	if matrixId == nil || *matrixId == "" {
+		return nil, 0, false, errResultNoMatrix
+	}
+
+	current, err := storedMatrixVersion(ctx, *matrixId)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	version := current
+	if pinned != nil {
+		version = *pinned
+	}
+	if version == 0 {
+		return nil, current, false, errMatrixVersionNotFound
+	}
+
+	matrixVersion, err := findMatrixVersion(ctx, *matrixId, version)
+	if err != nil {
+		return nil, current, false, errMatrixVersionNotFound
+	}
+
+	return matrixVersion, current, pinned != nil, nil
+}
+
+// pinResultMatrix returns the matrix version a new analysis of assessment is scored with and pins it on the assessment.
+func pinResultMatrix(ctx context.Context, assessment models.Assessment) (*string, *int, error) {
+	if assessment.Matrix_id == nil || *assessment.Matrix_id == "" {
+		return nil, nil, nil
+	}
+
+	version, err := currentMatrixVersion(ctx, *assessment.Matrix_id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if assessment.Matrix_version == nil || *assessment.Matrix_version != version {
+		_, err = assessmentCollection.UpdateOne(
+			ctx,
+			bson.M{"assessment_id": assessment.Assessment_id},
+			bson.M{"$set": bson.M{"matrix_version": version}},
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	matrixId := *assessment.Matrix_id
+	return &matrixId, &version, nil
+}
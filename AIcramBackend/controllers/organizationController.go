@@ -188,6 +188,11 @@ func CreateOrganization() gin.HandlerFunc {
 			organization.User_id = &userID
 		}
 
+		if !checkRegulationIds(ctx, organization.Regulation_id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "regulation_error"})
+			return
+		}
+
 		if organization.Status == nil {
 			status := 1
 			organization.Status = &status
@@ -204,6 +209,14 @@ func CreateOrganization() gin.HandlerFunc {
 			return
 		}
 
+		// Assets sent with the organization go to the asset collection; a failure leaves them embedded for the
+		// startup migration to retry.
+		if len(organization.Asset) > 0 {
+			if _, err := moveOrganizationAssets(ctx, organization); err != nil {
+				log.Printf("Failed to move assets of organization %s: %v", organization.Organization_id, err)
+			}
+		}
+
 		c.JSON(http.StatusOK, resultInsertionNumber)
 	}
 }
@@ -250,6 +263,11 @@ func UpdateOrganization() gin.HandlerFunc {
 			return
 		}
 
+		if updateData.Regulation_id != nil && !checkRegulationIds(ctx, updateData.Regulation_id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "regulation_error"})
+			return
+		}
+
 		update := bson.M{}
 
 		if userType != "ADMIN" {
@@ -284,6 +302,9 @@ func UpdateOrganization() gin.HandlerFunc {
 		if updateData.Regulation != nil {
 			update["regulation"] = updateData.Regulation
 		}
+		if updateData.Regulation_id != nil {
+			update["regulation_id"] = updateData.Regulation_id
+		}
 		if updateData.Asset != nil {
 			update["asset"] = updateData.Asset
 		}
@@ -317,6 +338,15 @@ func UpdateOrganization() gin.HandlerFunc {
 			return
 		}
 
+		if len(updateData.Asset) > 0 {
+			var updatedOrganization models.Organization
+			if err := organizationCollection.FindOne(ctx, bson.M{"organization_id": organizationId}).Decode(&updatedOrganization); err == nil {
+				if _, err := moveOrganizationAssets(ctx, updatedOrganization); err != nil {
+					log.Printf("Failed to move assets of organization %s: %v", organizationId, err)
+				}
+			}
+		}
+
 		c.JSON(http.StatusOK, result.ModifiedCount)
 	}
 }
@@ -332,7 +362,17 @@ func DeleteOrganization() gin.HandlerFunc {
 		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		result, err := organizationCollection.DeleteOne(ctx, bson.M{"organization_id": organizationId})
+		updates, ok := resolveAssessmentReferences(c, ctx, organizationReference, organizationId, true)
+		if !ok {
+			return
+		}
+
+		var result *mongo.DeleteResult
+		err := applyReferenceUpdates(ctx, updates, func(ctx context.Context) error {
+			var err error
+			result, err = organizationCollection.DeleteOne(ctx, bson.M{"organization_id": organizationId})
+			return err
+		})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete organization"})
 			return
@@ -378,24 +418,36 @@ func RemoveOrganization() gin.HandlerFunc {
 			}
 		}
 
+		updates, ok := resolveAssessmentReferences(c, ctx, organizationReference, organizationId, false)
+		if !ok {
+			return
+		}
+
 		status := 2
 		update := bson.M{
 			"status":     status,
 			"updated_at": time.Now().Format(time.RFC3339),
 		}
 
-		result, err := organizationCollection.UpdateOne(
-			ctx,
-			bson.M{"organization_id": organizationId},
-			bson.M{"$set": update},
-		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove organization"})
+		var result *mongo.UpdateResult
+		err = applyReferenceUpdates(ctx, updates, func(ctx context.Context) error {
+			var err error
+			result, err = organizationCollection.UpdateOne(
+				ctx,
+				bson.M{"organization_id": organizationId},
+				bson.M{"$set": update},
+			)
+			if err == nil && result.MatchedCount == 0 {
+				return errReferenceTargetNotFound
+			}
+			return err
+		})
+		if err == errReferenceTargetNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
 			return
 		}
-
-		if result.MatchedCount == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove organization"})
 			return
 		}
 
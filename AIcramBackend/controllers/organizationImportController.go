@@ -0,0 +1,386 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/database"
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const maxOrganizationImport = 100
+
+type assetImport struct {
+	Location string
+	Asset    *models.Asset
+}
+
+type organizationImport struct {
+	Location     string
+	Organization *models.Organization
+	Assets       []*assetImport
+}
+
+func importProblem(location string, message string) gin.H {
+	return gin.H{"location": location, "error": message}
+}
+
+// readOrganizationImport parses a JSON body, or an uploaded .json, .csv or .xlsx file. JSON has the shape
+// {"organization_items": [{...organization, "asset_items": [...]}]}. Tabular files hold one record per row:
+// a "record" column says "organization" or "asset", the other columns use the JSON field names, asset rows name
+// their organization in an "organization" column, and lists are separated by semicolons.
+func readOrganizationImport(c *gin.Context) ([]*organizationImport, []gin.H, error) {
+	var data []byte
+	fileName := "body.json"
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			return nil, nil, errors.New("No file uploaded")
+		}
+		if helper.TableKind(file.Filename) == "" {
+			return nil, nil, errors.New("file must be .csv, .xlsx or .json")
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return nil, nil, errors.New("Failed to open file")
+		}
+		defer src.Close()
+
+		if data, err = io.ReadAll(src); err != nil {
+			return nil, nil, errors.New("Failed to read file")
+		}
+		fileName = file.Filename
+	} else {
+		var err error
+		if data, err = io.ReadAll(c.Request.Body); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if helper.TableKind(fileName) == "json" {
+		var document struct {
+			Organization_items []*struct {
+				models.Organization
+				Asset_items []*models.Asset `json:"asset_items"`
+			} `json:"organization_items"`
+		}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, nil, err
+		}
+
+		items := []*organizationImport{}
+		problems := []gin.H{}
+		for i, item := range document.Organization_items {
+			location := fmt.Sprintf("organization_items[%d]", i)
+			if item == nil {
+				problems = append(problems, importProblem(location, "organization is required"))
+				continue
+			}
+
+			organization := item.Organization
+			imported := &organizationImport{Location: location, Organization: &organization}
+			for j, asset := range item.Asset_items {
+				assetLocation := fmt.Sprintf("%s.asset_items[%d]", location, j)
+				if asset == nil {
+					problems = append(problems, importProblem(assetLocation, "asset is required"))
+					continue
+				}
+				imported.Assets = append(imported.Assets, &assetImport{Location: assetLocation, Asset: asset})
+			}
+			items = append(items, imported)
+		}
+
+		return items, problems, nil
+	}
+
+	rows, err := helper.ReadTable(data, fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := []*organizationImport{}
+	byName := map[string]*organizationImport{}
+	problems := []gin.H{}
+	for _, row := range rows {
+		location := fmt.Sprintf("row %d", row.Row)
+		if strings.ToLower(row.Values["record"]) != "organization" {
+			continue
+		}
+
+		var organization models.Organization
+		if err := helper.DecodeRow(row.Values, &organization); err != nil {
+			problems = append(problems, importProblem(location, err.Error()))
+			continue
+		}
+		if organization.Name != nil {
+			if _, exists := byName[*organization.Name]; exists {
+				problems = append(problems, importProblem(location, "duplicate organization name"))
+				continue
+			}
+		}
+
+		imported := &organizationImport{Location: location, Organization: &organization}
+		if organization.Name != nil {
+			byName[*organization.Name] = imported
+		}
+		items = append(items, imported)
+	}
+
+	for _, row := range rows {
+		location := fmt.Sprintf("row %d", row.Row)
+		switch strings.ToLower(row.Values["record"]) {
+		case "organization":
+			continue
+		case "asset":
+		default:
+			problems = append(problems, importProblem(location, "record must be organization or asset"))
+			continue
+		}
+
+		imported, ok := byName[row.Values["organization"]]
+		if !ok {
+			problems = append(problems, importProblem(location, "organization not found in file"))
+			continue
+		}
+
+		var asset models.Asset
+		if err := helper.DecodeRow(row.Values, &asset); err != nil {
+			problems = append(problems, importProblem(location, err.Error()))
+			continue
+		}
+		imported.Assets = append(imported.Assets, &assetImport{Location: location, Asset: &asset})
+	}
+
+	return items, problems, nil
+}
+
+// prepareOrganizationImport assigns ids and owners and validates every record with the same rules as
+// CreateOrganization and CreateAsset. Dependencies name other assets of the same imported organization.
+func prepareOrganizationImport(ctx context.Context, c *gin.Context, items []*organizationImport) []gin.H {
+	problems := []gin.H{}
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	for _, item := range items {
+		organization := item.Organization
+		organization.ID = primitive.NewObjectID()
+		organization.Organization_id = organization.ID.Hex()
+		organization.Created_at = now
+		organization.Updated_at = now
+		if organization.Status == nil {
+			status := 1
+			organization.Status = &status
+		}
+
+		if c.GetString("user_type") != "ADMIN" {
+			userId := c.GetString("uid")
+			organization.User_id = &userId
+		} else {
+			var user models.User
+			err := userCollection.FindOne(ctx, bson.M{"username": organization.User_id}).Decode(&user)
+			if err != nil || user.Username == nil {
+				problems = append(problems, importProblem(item.Location, "user_error"))
+				organization.User_id = nil
+			} else {
+				userID := user.ID.Hex()
+				organization.User_id = &userID
+			}
+		}
+
+		// The embedded asset list is legacy; imported assets belong in asset_items.
+		if len(organization.Asset) > 0 {
+			problems = append(problems, importProblem(item.Location, "asset is not supported, use asset_items"))
+		}
+		organization.Asset = nil
+
+		if validationErr := organizationValidate.Struct(organization); validationErr != nil {
+			problems = append(problems, importProblem(item.Location, validationErr.Error()))
+		}
+		if !checkRegulationIds(ctx, organization.Regulation_id) {
+			problems = append(problems, importProblem(item.Location, "regulation_error"))
+		}
+		if len(item.Assets) > maxAssetImport {
+			problems = append(problems, importProblem(item.Location, fmt.Sprintf("an organization can import at most %d assets", maxAssetImport)))
+			continue
+		}
+
+		byName := map[string]string{}
+		for _, imported := range item.Assets {
+			asset := imported.Asset
+			asset.ID = primitive.NewObjectID()
+			asset.Asset_id = asset.ID.Hex()
+			asset.User_id = organization.User_id
+			asset.Organization_id = &organization.Organization_id
+			if asset.Status == nil {
+				status := 1
+				asset.Status = &status
+			}
+			asset.Created_at = now
+			asset.Updated_at = now
+			if asset.Name != nil {
+				if _, exists := byName[*asset.Name]; exists {
+					problems = append(problems, importProblem(imported.Location, "duplicate asset name"))
+				} else {
+					byName[*asset.Name] = asset.Asset_id
+				}
+			}
+		}
+
+		graph := helper.DependencyGraph{}
+		for _, imported := range item.Assets {
+			asset := imported.Asset
+			if validationErr := assetValidate.Struct(asset); validationErr != nil {
+				problems = append(problems, importProblem(imported.Location, validationErr.Error()))
+				continue
+			}
+			cpe, invalid := normalizeAssetCPE(asset.CPE)
+			if len(invalid) > 0 {
+				problems = append(problems, importProblem(imported.Location, "cpe_error"))
+				continue
+			}
+			asset.CPE = cpe
+
+			dependencies := []*string{}
+			resolved := true
+			for _, dependency := range asset.Dependency {
+				if dependency == nil || *dependency == "" {
+					continue
+				}
+				id, ok := byName[*dependency]
+				if !ok {
+					resolved = false
+					break
+				}
+				dependencies = append(dependencies, &id)
+			}
+			if !resolved {
+				problems = append(problems, importProblem(imported.Location, "dependency_error"))
+				continue
+			}
+			asset.Dependency = dependencies
+			graph[asset.Asset_id] = uniqueIds(dependencies)
+		}
+
+		for _, cycle := range graph.Cycles() {
+			problems = append(problems, gin.H{"location": item.Location, "error": "dependency_cycle", "cycle": cycle})
+		}
+	}
+
+	return problems
+}
+
+func transactionsUnsupported(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Code == 20
+}
+
+// insertOrganizationImport writes organizations and assets in one transaction. Standalone servers cannot run
+// transactions, so there a failed insert is undone by deleting what was written.
+func insertOrganizationImport(ctx context.Context, organizations []interface{}, organizationIds []string, assets []interface{}) error {
+	session, err := database.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		if _, err := organizationCollection.InsertMany(sessCtx, organizations); err != nil {
+			return nil, err
+		}
+		if len(assets) > 0 {
+			if _, err := assetCollection.InsertMany(sessCtx, assets); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if !transactionsUnsupported(err) {
+		return err
+	}
+
+	filter := bson.M{"organization_id": bson.M{"$in": organizationIds}}
+	if _, err := organizationCollection.InsertMany(ctx, organizations); err != nil {
+		organizationCollection.DeleteMany(ctx, filter)
+		return err
+	}
+	if len(assets) > 0 {
+		if _, err := assetCollection.InsertMany(ctx, assets); err != nil {
+			assetCollection.DeleteMany(ctx, filter)
+			organizationCollection.DeleteMany(ctx, filter)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportOrganizations creates organizations with their assets from a file or JSON body. With ?dry_run=true it
+// only reports the validation problems of each row; otherwise nothing is written unless every row is valid.
+func ImportOrganizations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		items, problems, err := readOrganizationImport(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(items) == 0 || len(items) > maxOrganizationImport {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("import must contain between 1 and %d organizations", maxOrganizationImport)})
+			return
+		}
+
+		problems = append(problems, prepareOrganizationImport(ctx, c, items)...)
+
+		organizations := []interface{}{}
+		organizationIds := []string{}
+		assets := []interface{}{}
+		for _, item := range items {
+			organizations = append(organizations, item.Organization)
+			organizationIds = append(organizationIds, item.Organization.Organization_id)
+			for _, imported := range item.Assets {
+				assets = append(assets, imported.Asset)
+			}
+		}
+
+		if c.Query("dry_run") == "true" {
+			c.JSON(http.StatusOK, gin.H{
+				"dry_run":            true,
+				"valid":              len(problems) == 0,
+				"organization_count": len(organizations),
+				"asset_count":        len(assets),
+				"errors":             problems,
+			})
+			return
+		}
+
+		if len(problems) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "import_error", "errors": problems})
+			return
+		}
+
+		if err := insertOrganizationImport(ctx, organizations, organizationIds, assets); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import organizations"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"inserted_count":   len(organizations),
+			"asset_count":      len(assets),
+			"organization_ids": organizationIds,
+		})
+	}
+}
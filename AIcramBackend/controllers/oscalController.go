@@ -0,0 +1,560 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+)
+
+const oscalVersion = "1.1.2"
+
+// oscalSystem names the properties and facets the export defines itself.
+const oscalSystem = "urn:aicram:oscal"
+
+var oscalNamespace = helper.NameUUID(helper.URLNamespace, oscalSystem)
+
+var oscalUUIDPattern = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[45][0-9A-Fa-f]{3}-[89ABab][0-9A-Fa-f]{3}-[0-9A-Fa-f]{12}$`)
+var oscalTokenPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+var oscalControlPattern = regexp.MustCompile(`^[a-z]{2}-\d+(?:\.\d+)?$`)
+
+var oscalMethods = map[string]bool{"EXAMINE": true, "INTERVIEW": true, "TEST": true, "UNKNOWN": true}
+var oscalRiskStatuses = map[string]bool{
+	"open": true, "investigating": true, "remediating": true, "deviation-requested": true, "deviation-approved": true, "closed": true,
+}
+
+type oscalProperty struct {
+	Name  string `json:"name"`
+	Ns    string `json:"ns,omitempty"`
+	Value string `json:"value"`
+}
+
+type oscalParty struct {
+	Uuid string `json:"uuid"`
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type oscalMetadata struct {
+	Title         string       `json:"title"`
+	Last_modified string       `json:"last-modified"`
+	Version       string       `json:"version"`
+	Oscal_version string       `json:"oscal-version"`
+	Parties       []oscalParty `json:"parties,omitempty"`
+}
+
+type oscalSelectControl struct {
+	Control_id string `json:"control-id"`
+}
+
+type oscalControlSelection struct {
+	Description      string               `json:"description,omitempty"`
+	Include_controls []oscalSelectControl `json:"include-controls,omitempty"`
+}
+
+type oscalReviewedControls struct {
+	Control_selections []oscalControlSelection `json:"control-selections"`
+}
+
+type oscalObservation struct {
+	Uuid        string          `json:"uuid"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description"`
+	Props       []oscalProperty `json:"props,omitempty"`
+	Methods     []string        `json:"methods"`
+	Types       []string        `json:"types,omitempty"`
+	Collected   string          `json:"collected"`
+}
+
+type oscalActor struct {
+	Type       string `json:"type"`
+	Actor_uuid string `json:"actor-uuid"`
+}
+
+type oscalOrigin struct {
+	Actors []oscalActor `json:"actors"`
+}
+
+type oscalFacet struct {
+	Name   string `json:"name"`
+	System string `json:"system"`
+	Value  string `json:"value"`
+}
+
+type oscalCharacterization struct {
+	Origin oscalOrigin  `json:"origin"`
+	Facets []oscalFacet `json:"facets"`
+}
+
+type oscalMitigatingFactor struct {
+	Uuid        string `json:"uuid"`
+	Description string `json:"description"`
+}
+
+type oscalRelatedObservation struct {
+	Observation_uuid string `json:"observation-uuid"`
+}
+
+type oscalRelatedRisk struct {
+	Risk_uuid string `json:"risk-uuid"`
+}
+
+type oscalRisk struct {
+	Uuid                 string                    `json:"uuid"`
+	Title                string                    `json:"title"`
+	Description          string                    `json:"description"`
+	Statement            string                    `json:"statement"`
+	Props                []oscalProperty           `json:"props,omitempty"`
+	Status               string                    `json:"status"`
+	Characterizations    []oscalCharacterization   `json:"characterizations,omitempty"`
+	Mitigating_factors   []oscalMitigatingFactor   `json:"mitigating-factors,omitempty"`
+	Related_observations []oscalRelatedObservation `json:"related-observations,omitempty"`
+}
+
+type oscalTargetStatus struct {
+	State string `json:"state"`
+}
+
+type oscalTarget struct {
+	Type      string            `json:"type"`
+	Target_id string            `json:"target-id"`
+	Status    oscalTargetStatus `json:"status"`
+}
+
+type oscalFinding struct {
+	Uuid                 string                    `json:"uuid"`
+	Title                string                    `json:"title"`
+	Description          string                    `json:"description"`
+	Target               oscalTarget               `json:"target"`
+	Related_observations []oscalRelatedObservation `json:"related-observations,omitempty"`
+	Related_risks        []oscalRelatedRisk        `json:"related-risks,omitempty"`
+}
+
+type oscalResult struct {
+	Uuid              string                `json:"uuid"`
+	Title             string                `json:"title"`
+	Description       string                `json:"description"`
+	Start             string                `json:"start"`
+	Reviewed_controls oscalReviewedControls `json:"reviewed-controls"`
+	Observations      []oscalObservation    `json:"observations,omitempty"`
+	Risks             []oscalRisk           `json:"risks,omitempty"`
+	Findings          []oscalFinding        `json:"findings,omitempty"`
+}
+
+type oscalResource struct {
+	Uuid        string `json:"uuid"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type oscalBackMatter struct {
+	Resources []oscalResource `json:"resources"`
+}
+
+type oscalImportAp struct {
+	Href string `json:"href"`
+}
+
+type oscalAssessmentResults struct {
+	Uuid        string           `json:"uuid"`
+	Metadata    oscalMetadata    `json:"metadata"`
+	Import_ap   oscalImportAp    `json:"import-ap"`
+	Results     []oscalResult    `json:"results"`
+	Back_matter *oscalBackMatter `json:"back-matter,omitempty"`
+}
+
+type oscalDocument struct {
+	Assessment_results oscalAssessmentResults `json:"assessment-results"`
+}
+
+func oscalUUID(name string) string {
+	return helper.NameUUID(oscalNamespace, name)
+}
+
+func oscalTime(value time.Time) string {
+	return value.UTC().Format(time.RFC3339)
+}
+
+// oscalControlId turns a NIST SP 800-53 code such as "AC-2" into the OSCAL catalog form "ac-2".
+func oscalControlId(code string) string {
+	return strings.ToLower(code)
+}
+
+func oscalRiskFacets(vulnerability *models.Vulnerability) []oscalFacet {
+	facets := []oscalFacet{}
+	add := func(name string, value *int) {
+		if value != nil {
+			facets = append(facets, oscalFacet{Name: name, System: oscalSystem, Value: strconv.Itoa(*value)})
+		}
+	}
+	add("impact", vulnerability.Impact)
+	add("likelihood", vulnerability.Likelihood)
+	add("residual-impact", vulnerability.New_impact)
+	add("residual-likelihood", vulnerability.New_likelihood)
+	if vulnerability.Loss != nil && vulnerability.Loss.Mean != nil {
+		facets = append(facets, oscalFacet{Name: "loss-mean", System: oscalSystem, Value: strconv.FormatFloat(*vulnerability.Loss.Mean, 'f', 2, 64)})
+	}
+
+	return facets
+}
+
+// buildOSCALResults maps the result to an OSCAL Assessment Results document: each vulnerability becomes an
+// observation and a risk mitigated by its controls, and each NIST SP 800-53 control named in Control.NIST
+// becomes a reviewed control with a finding that is satisfied only when every vulnerability it addresses is
+// treated. There is no assessment plan, so import-ap points at a back-matter resource describing the assessment.
+func buildOSCALResults(report *resultReport) oscalDocument {
+	result := report.Result
+	brand, _ := reportBrand()
+	party := oscalUUID("party/" + brand)
+	planResource := oscalUUID(result.Result_id + "/assessment-plan")
+	modified := result.Created_at
+	if result.Updated_at.After(modified) {
+		modified = result.Updated_at
+	}
+
+	assessment := oscalResult{
+		Uuid:        oscalUUID(result.Result_id + "/result"),
+		Title:       report.Title(),
+		Description: "Risk assessment result " + result.Result_id,
+		Start:       oscalTime(result.Created_at),
+	}
+	if result.Content != nil && textValue(result.Content.Summary) != "" {
+		assessment.Description = *result.Content.Summary
+	}
+
+	controls := []string{}
+	controlRisks := map[string][]string{}
+	controlObservations := map[string][]string{}
+	controlSatisfied := map[string]bool{}
+	if result.Content != nil {
+		for i, vulnerability := range result.Content.Vulnerability {
+			if vulnerability == nil {
+				continue
+			}
+			key := result.Result_id + "/vulnerability/" + strconv.Itoa(i)
+			name := textValue(vulnerability.Name)
+			if name == "" {
+				name = "Vulnerability " + strconv.Itoa(i+1)
+			}
+			description := textValue(vulnerability.Description)
+			if description == "" {
+				description = name
+			}
+
+			props := []oscalProperty{}
+			for _, cve := range stringValues(vulnerability.CVE) {
+				if cve = strings.ToUpper(cve); cvePattern.MatchString(cve) {
+					props = append(props, oscalProperty{Name: "cve-id", Ns: oscalSystem, Value: cve})
+				}
+			}
+			codes, _ := mitreTechniques(vulnerability.MITRE)
+			for _, code := range codes {
+				props = append(props, oscalProperty{Name: "attack-technique", Ns: oscalSystem, Value: code})
+			}
+			for _, assetId := range stringValues(vulnerability.Asset_id) {
+				props = append(props, oscalProperty{Name: "asset-id", Ns: oscalSystem, Value: assetId})
+			}
+
+			observation := oscalObservation{
+				Uuid: oscalUUID(key + "/observation"), Title: name, Description: description, Props: props,
+				Methods: []string{"EXAMINE"}, Types: []string{"finding"}, Collected: oscalTime(result.Created_at),
+			}
+			assessment.Observations = append(assessment.Observations, observation)
+
+			treated := vulnerabilityTreated(report.Matrix, vulnerability)
+			risk := oscalRisk{
+				Uuid: oscalUUID(key + "/risk"), Title: name, Description: description,
+				Statement: "Impact " + report.ScoreLabel("impact", vulnerability.Impact) + ", likelihood " + report.ScoreLabel("likelihood", vulnerability.Likelihood) +
+					"; residual impact " + report.ScoreLabel("impact", vulnerability.New_impact) + ", residual likelihood " + report.ScoreLabel("likelihood", vulnerability.New_likelihood) + ".",
+				Props: props, Status: "open",
+				Related_observations: []oscalRelatedObservation{{Observation_uuid: observation.Uuid}},
+			}
+			if treated {
+				risk.Status = "remediating"
+			}
+			if facets := oscalRiskFacets(vulnerability); len(facets) > 0 {
+				risk.Characterizations = []oscalCharacterization{{
+					Origin: oscalOrigin{Actors: []oscalActor{{Type: "party", Actor_uuid: party}}},
+					Facets: facets,
+				}}
+			}
+
+			for j, control := range vulnerability.Control {
+				if control == nil {
+					continue
+				}
+				risk.Mitigating_factors = append(risk.Mitigating_factors, oscalMitigatingFactor{
+					Uuid: oscalUUID(key + "/control/" + strconv.Itoa(j)), Description: controlLine(control),
+				})
+
+				nist, _ := controlCodes(control)
+				for _, code := range nist {
+					id := oscalControlId(code)
+					if _, ok := controlSatisfied[id]; !ok {
+						controls = append(controls, id)
+						controlSatisfied[id] = true
+					}
+					controlSatisfied[id] = controlSatisfied[id] && treated
+					controlRisks[id] = appendUnique(controlRisks[id], risk.Uuid)
+					controlObservations[id] = appendUnique(controlObservations[id], observation.Uuid)
+				}
+			}
+			assessment.Risks = append(assessment.Risks, risk)
+		}
+	}
+
+	selection := oscalControlSelection{}
+	if len(controls) == 0 {
+		selection.Description = "No NIST SP 800-53 controls are referenced by this result."
+	}
+	for _, id := range controls {
+		selection.Include_controls = append(selection.Include_controls, oscalSelectControl{Control_id: id})
+
+		finding := oscalFinding{
+			Uuid:        oscalUUID(result.Result_id + "/finding/" + id),
+			Title:       strings.ToUpper(id),
+			Description: "Control " + strings.ToUpper(id) + " is recommended for " + strconv.Itoa(len(controlRisks[id])) + " risk(s) in this assessment.",
+			Target:      oscalTarget{Type: "statement-id", Target_id: id + "_smt", Status: oscalTargetStatus{State: "not-satisfied"}},
+		}
+		if controlSatisfied[id] {
+			finding.Target.Status.State = "satisfied"
+		}
+		for _, uuid := range controlObservations[id] {
+			finding.Related_observations = append(finding.Related_observations, oscalRelatedObservation{Observation_uuid: uuid})
+		}
+		for _, uuid := range controlRisks[id] {
+			finding.Related_risks = append(finding.Related_risks, oscalRelatedRisk{Risk_uuid: uuid})
+		}
+		assessment.Findings = append(assessment.Findings, finding)
+	}
+	assessment.Reviewed_controls = oscalReviewedControls{Control_selections: []oscalControlSelection{selection}}
+
+	planDescription := "Assessment " + report.Title()
+	if report.Organization != nil && report.Organization.Name != nil {
+		planDescription += " of " + *report.Organization.Name
+	}
+
+	return oscalDocument{Assessment_results: oscalAssessmentResults{
+		Uuid: oscalUUID(result.Result_id + "/assessment-results"),
+		Metadata: oscalMetadata{
+			Title:         report.Title(),
+			Last_modified: oscalTime(modified),
+			Version:       oscalTime(modified),
+			Oscal_version: oscalVersion,
+			Parties:       []oscalParty{{Uuid: party, Type: "organization", Name: brand}},
+		},
+		Import_ap:   oscalImportAp{Href: "#" + planResource},
+		Results:     []oscalResult{assessment},
+		Back_matter: &oscalBackMatter{Resources: []oscalResource{{Uuid: planResource, Title: "Assessment plan", Description: planDescription}}},
+	}}
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
+
+// validateOSCALResults checks the document against the OSCAL Assessment Results model for the parts the export
+// produces: required fields, UUID and timestamp formats, allowed values, and that every UUID reference resolves.
+func validateOSCALResults(document oscalDocument) []string {
+	problems := []string{}
+	results := document.Assessment_results
+	seen := map[string]bool{}
+	checkUUID := func(location string, uuid string) {
+		if !oscalUUIDPattern.MatchString(uuid) {
+			problems = append(problems, location+": invalid uuid "+uuid)
+		} else if seen[uuid] {
+			problems = append(problems, location+": duplicate uuid "+uuid)
+		}
+		seen[uuid] = true
+	}
+	checkTime := func(location string, value string) {
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			problems = append(problems, location+": invalid date-time "+value)
+		}
+	}
+	required := func(location string, field string, value string) {
+		if strings.TrimSpace(value) == "" {
+			problems = append(problems, location+": "+field+" is required")
+		}
+	}
+	checkProps := func(location string, props []oscalProperty) {
+		for _, prop := range props {
+			if !oscalTokenPattern.MatchString(prop.Name) || strings.TrimSpace(prop.Value) == "" {
+				problems = append(problems, location+": invalid prop "+prop.Name)
+			}
+		}
+	}
+
+	checkUUID("assessment-results", results.Uuid)
+	required("metadata", "title", results.Metadata.Title)
+	required("metadata", "version", results.Metadata.Version)
+	checkTime("metadata last-modified", results.Metadata.Last_modified)
+	if results.Metadata.Oscal_version != oscalVersion {
+		problems = append(problems, "metadata: oscal-version must be "+oscalVersion)
+	}
+	parties := map[string]bool{}
+	for _, party := range results.Metadata.Parties {
+		checkUUID("party", party.Uuid)
+		if party.Type != "person" && party.Type != "organization" {
+			problems = append(problems, "party "+party.Uuid+": invalid type "+party.Type)
+		}
+		parties[party.Uuid] = true
+	}
+
+	resources := map[string]bool{}
+	if results.Back_matter != nil {
+		for _, resource := range results.Back_matter.Resources {
+			checkUUID("resource", resource.Uuid)
+			resources[resource.Uuid] = true
+		}
+	}
+	required("import-ap", "href", results.Import_ap.Href)
+	if strings.HasPrefix(results.Import_ap.Href, "#") && !resources[strings.TrimPrefix(results.Import_ap.Href, "#")] {
+		problems = append(problems, "import-ap: href does not resolve to a back-matter resource")
+	}
+
+	if len(results.Results) == 0 {
+		problems = append(problems, "assessment-results: at least one result is required")
+	}
+	for _, result := range results.Results {
+		location := "result " + result.Uuid
+		checkUUID(location, result.Uuid)
+		required(location, "title", result.Title)
+		required(location, "description", result.Description)
+		checkTime(location+" start", result.Start)
+
+		reviewed := map[string]bool{}
+		if len(result.Reviewed_controls.Control_selections) == 0 {
+			problems = append(problems, location+": reviewed-controls needs a control selection")
+		}
+		for _, selection := range result.Reviewed_controls.Control_selections {
+			for _, control := range selection.Include_controls {
+				if !oscalControlPattern.MatchString(control.Control_id) {
+					problems = append(problems, location+": invalid control-id "+control.Control_id)
+				}
+				reviewed[control.Control_id] = true
+			}
+		}
+
+		observations := map[string]bool{}
+		for _, observation := range result.Observations {
+			at := "observation " + observation.Uuid
+			checkUUID(at, observation.Uuid)
+			required(at, "description", observation.Description)
+			checkTime(at+" collected", observation.Collected)
+			checkProps(at, observation.Props)
+			if len(observation.Methods) == 0 {
+				problems = append(problems, at+": methods is required")
+			}
+			for _, method := range observation.Methods {
+				if !oscalMethods[method] {
+					problems = append(problems, at+": invalid method "+method)
+				}
+			}
+			observations[observation.Uuid] = true
+		}
+
+		risks := map[string]bool{}
+		for _, risk := range result.Risks {
+			at := "risk " + risk.Uuid
+			checkUUID(at, risk.Uuid)
+			required(at, "title", risk.Title)
+			required(at, "description", risk.Description)
+			required(at, "statement", risk.Statement)
+			checkProps(at, risk.Props)
+			if !oscalRiskStatuses[risk.Status] {
+				problems = append(problems, at+": invalid status "+risk.Status)
+			}
+			for _, characterization := range risk.Characterizations {
+				if len(characterization.Origin.Actors) == 0 || len(characterization.Facets) == 0 {
+					problems = append(problems, at+": characterization needs an origin actor and a facet")
+				}
+				for _, actor := range characterization.Origin.Actors {
+					if actor.Type != "tool" && actor.Type != "assessment-platform" && actor.Type != "party" {
+						problems = append(problems, at+": invalid actor type "+actor.Type)
+					}
+					if actor.Type == "party" && !parties[actor.Actor_uuid] {
+						problems = append(problems, at+": actor "+actor.Actor_uuid+" is not a metadata party")
+					}
+				}
+				for _, facet := range characterization.Facets {
+					if !oscalTokenPattern.MatchString(facet.Name) || facet.System == "" || facet.Value == "" {
+						problems = append(problems, at+": invalid facet "+facet.Name)
+					}
+				}
+			}
+			for _, factor := range risk.Mitigating_factors {
+				checkUUID(at+" mitigating-factor", factor.Uuid)
+				required(at+" mitigating-factor", "description", factor.Description)
+			}
+			for _, related := range risk.Related_observations {
+				if !observations[related.Observation_uuid] {
+					problems = append(problems, at+": related observation "+related.Observation_uuid+" does not exist")
+				}
+			}
+			risks[risk.Uuid] = true
+		}
+
+		for _, finding := range result.Findings {
+			at := "finding " + finding.Uuid
+			checkUUID(at, finding.Uuid)
+			required(at, "title", finding.Title)
+			required(at, "description", finding.Description)
+			if finding.Target.Type != "statement-id" && finding.Target.Type != "objective-id" {
+				problems = append(problems, at+": invalid target type "+finding.Target.Type)
+			}
+			if !reviewed[strings.TrimSuffix(finding.Target.Target_id, "_smt")] {
+				problems = append(problems, at+": target "+finding.Target.Target_id+" is not a reviewed control")
+			}
+			if finding.Target.Status.State != "satisfied" && finding.Target.Status.State != "not-satisfied" {
+				problems = append(problems, at+": invalid target state "+finding.Target.Status.State)
+			}
+			for _, related := range finding.Related_observations {
+				if !observations[related.Observation_uuid] {
+					problems = append(problems, at+": related observation "+related.Observation_uuid+" does not exist")
+				}
+			}
+			for _, related := range finding.Related_risks {
+				if !risks[related.Risk_uuid] {
+					problems = append(problems, at+": related risk "+related.Risk_uuid+" does not exist")
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+func GetResultOSCAL() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		report, ok := loadResultReport(c, ctx)
+		if !ok {
+			return
+		}
+
+		document := buildOSCALResults(report)
+		if problems := validateOSCALResults(document); len(problems) > 0 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "export_error", "detail": "generated OSCAL document is invalid", "errors": problems})
+			return
+		}
+
+		c.Header("Content-Disposition", "attachment; filename=\"result-"+report.Result.Result_id+".oscal.json\"")
+		c.JSON(http.StatusOK, document)
+	}
+}
@@ -0,0 +1,547 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/database"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var regulationCollection *mongo.Collection = database.OpenCollection(database.Client, "regulation")
+
+type requirementSeed struct {
+	Code        string
+	Title       string
+	Description string
+	NIST        []string
+	ISO         []string
+}
+
+type regulationSeed struct {
+	Key         string
+	Name        string
+	Version     string
+	Description string
+	Requirement []requirementSeed
+}
+
+// Requirements cross-reference NIST SP 800-53 Rev. 5 controls or families (e.g. "AC-2" or "AC") and
+// ISO/IEC 27001:2022 Annex A controls or themes (e.g. "A.8.24" or "A.8"); controls in results are mapped through them.
+var regulationCatalog = []regulationSeed{
+	{
+		Key:         "gdpr",
+		Name:        "GDPR",
+		Version:     "Regulation (EU) 2016/679",
+		Description: "EU General Data Protection Regulation governing the processing of personal data.",
+		Requirement: []requirementSeed{
+			{"Art. 5", "Principles of processing", "Process personal data lawfully, fairly and transparently, for specified purposes, minimised, accurate, retained no longer than necessary and kept secure.", []string{"PT", "PM-5"}, []string{"A.5.34"}},
+			{"Art. 15-22", "Data subject rights", "Enable access, rectification, erasure, restriction, portability and objection requests to be handled in time.", []string{"PT-2", "PT-5", "PM-22"}, []string{"A.5.34"}},
+			{"Art. 25", "Data protection by design and by default", "Build data protection into processing systems and only process the personal data necessary by default.", []string{"SA-8", "PT-2", "SA-15"}, []string{"A.8.25", "A.8.27", "A.8.11"}},
+			{"Art. 28", "Processors", "Use only processors giving sufficient guarantees and bind them by contract.", []string{"SR", "SA-9", "PS-7"}, []string{"A.5.19", "A.5.20", "A.5.21", "A.5.22"}},
+			{"Art. 30", "Records of processing activities", "Maintain a record of processing activities under the controller's responsibility.", []string{"PM-5", "CM-8", "PT-3"}, []string{"A.5.9", "A.5.12"}},
+			{"Art. 32", "Security of processing", "Implement appropriate technical and organisational measures such as encryption, pseudonymisation, resilience and regular testing.", []string{"SC-8", "SC-13", "SC-28", "AC", "IA", "CP", "CA-2", "RA-5"}, []string{"A.8.24", "A.8.5", "A.8.13", "A.8.14", "A.5.15", "A.8.8"}},
+			{"Art. 33", "Breach notification to the authority", "Notify the supervisory authority of a personal data breach within 72 hours where feasible.", []string{"IR-6", "IR-4", "IR-8"}, []string{"A.5.24", "A.5.25", "A.5.26"}},
+			{"Art. 34", "Communication of a breach to data subjects", "Inform affected data subjects without undue delay when a breach is likely to result in a high risk.", []string{"IR-6", "IR-7"}, []string{"A.5.26"}},
+			{"Art. 35", "Data protection impact assessment", "Assess the impact of high-risk processing on the protection of personal data before it starts.", []string{"RA-3", "RA-8", "PT-2"}, []string{"A.5.34"}},
+			{"Art. 44-49", "International transfers", "Transfer personal data outside the EU only with adequate safeguards.", []string{"PT-2", "SA-9", "AC-21"}, []string{"A.5.14", "A.5.34"}},
+		},
+	},
+	{
+		Key:         "hipaa",
+		Name:        "HIPAA Security Rule",
+		Version:     "45 CFR Part 164 Subpart C",
+		Description: "US safeguards for electronic protected health information held by covered entities and business associates.",
+		Requirement: []requirementSeed{
+			{"164.308(a)(1)", "Security management process", "Conduct risk analysis and risk management, apply sanctions and review information system activity.", []string{"RA-3", "RA-2", "PM-9", "AU-6", "PS-8"}, []string{"A.5.1", "A.5.7"}},
+			{"164.308(a)(3)", "Workforce security", "Ensure workforce members have appropriate access and prevent others from obtaining it.", []string{"PS", "AC-2"}, []string{"A.6.1", "A.6.5", "A.5.18"}},
+			{"164.308(a)(4)", "Information access management", "Authorize access to ePHI consistent with the minimum necessary standard.", []string{"AC-2", "AC-3", "AC-6"}, []string{"A.5.15", "A.5.18", "A.8.2"}},
+			{"164.308(a)(5)", "Security awareness and training", "Train the workforce on security, malware protection, log-in monitoring and password management.", []string{"AT"}, []string{"A.6.3"}},
+			{"164.308(a)(6)", "Security incident procedures", "Identify, respond to, mitigate and document security incidents.", []string{"IR"}, []string{"A.5.24", "A.5.25", "A.5.26", "A.5.27"}},
+			{"164.308(a)(7)", "Contingency plan", "Maintain data backup, disaster recovery and emergency mode operation plans.", []string{"CP"}, []string{"A.5.29", "A.5.30", "A.8.13", "A.8.14"}},
+			{"164.308(b)(1)", "Business associate contracts", "Obtain satisfactory assurances from business associates that they safeguard ePHI.", []string{"SA-9", "SR"}, []string{"A.5.19", "A.5.20"}},
+			{"164.310(a)(1)", "Facility access controls", "Limit physical access to information systems and the facilities housing them.", []string{"PE"}, []string{"A.7"}},
+			{"164.310(d)(1)", "Device and media controls", "Govern receipt, movement, reuse and disposal of hardware and electronic media.", []string{"MP", "CM-8"}, []string{"A.7.10", "A.7.14", "A.8.10"}},
+			{"164.312(a)(1)", "Access control", "Allow access only to authorized persons or software, with unique user IDs, emergency access, automatic logoff and encryption.", []string{"AC", "SC-28"}, []string{"A.8.2", "A.8.3", "A.8.5", "A.8.24"}},
+			{"164.312(b)", "Audit controls", "Record and examine activity in systems containing ePHI.", []string{"AU"}, []string{"A.8.15", "A.8.16"}},
+			{"164.312(c)(1)", "Integrity", "Protect ePHI from improper alteration or destruction.", []string{"SI-7", "SC-28"}, []string{"A.8.24"}},
+			{"164.312(d)", "Person or entity authentication", "Verify that a person or entity seeking access is the one claimed.", []string{"IA"}, []string{"A.5.17", "A.8.5"}},
+			{"164.312(e)(1)", "Transmission security", "Guard against unauthorized access to ePHI transmitted over networks.", []string{"SC-8", "SC-13"}, []string{"A.8.20", "A.8.21", "A.8.24"}},
+		},
+	},
+	{
+		Key:         "pci-dss",
+		Name:        "PCI DSS",
+		Version:     "4.0",
+		Description: "Payment Card Industry Data Security Standard for entities that store, process or transmit cardholder data.",
+		Requirement: []requirementSeed{
+			{"1", "Install and maintain network security controls", "Control traffic between trusted and untrusted networks with firewalls and equivalent controls.", []string{"SC-7", "CA-3", "AC-4"}, []string{"A.8.20", "A.8.21", "A.8.22"}},
+			{"2", "Apply secure configurations to all system components", "Harden systems and change vendor defaults.", []string{"CM-2", "CM-6", "CM-7"}, []string{"A.8.9"}},
+			{"3", "Protect stored account data", "Minimise retention and render stored account data unreadable.", []string{"SC-28", "SC-12", "MP-6", "SI-12"}, []string{"A.8.10", "A.8.11", "A.8.24"}},
+			{"4", "Protect cardholder data with strong cryptography during transmission", "Encrypt cardholder data sent over open, public networks.", []string{"SC-8", "SC-13"}, []string{"A.8.24", "A.5.14"}},
+			{"5", "Protect all systems and networks from malicious software", "Deploy and maintain anti-malware mechanisms and anti-phishing controls.", []string{"SI-3", "SI-8"}, []string{"A.8.7"}},
+			{"6", "Develop and maintain secure systems and software", "Follow secure development, patch known vulnerabilities and protect public-facing web applications.", []string{"SA-11", "SA-15", "SI-2", "RA-5", "CM-3"}, []string{"A.8.8", "A.8.25", "A.8.26", "A.8.28", "A.8.29", "A.8.32"}},
+			{"7", "Restrict access by business need to know", "Grant access to system components and data only as needed.", []string{"AC-3", "AC-6"}, []string{"A.5.15", "A.8.3"}},
+			{"8", "Identify users and authenticate access", "Assign unique IDs, use strong authentication and multi-factor authentication.", []string{"IA", "AC-2"}, []string{"A.5.16", "A.5.17", "A.8.5"}},
+			{"9", "Restrict physical access to cardholder data", "Control physical access to facilities, media and point-of-interaction devices.", []string{"PE", "MP"}, []string{"A.7"}},
+			{"10", "Log and monitor all access", "Record, protect and review audit logs of access to system components and cardholder data.", []string{"AU", "SI-4"}, []string{"A.8.15", "A.8.16", "A.8.17"}},
+			{"11", "Test security of systems and networks regularly", "Run vulnerability scans, penetration tests and detect intrusions and unauthorized changes.", []string{"CA-8", "RA-5", "SI-4", "SI-7"}, []string{"A.8.8", "A.8.16", "A.8.34"}},
+			{"12", "Support information security with organizational policies and programs", "Maintain policies, risk assessment, awareness, third-party management and incident response.", []string{"PL", "PM", "RA-3", "AT", "IR-8", "SR"}, []string{"A.5.1", "A.5.19", "A.5.24", "A.6.3"}},
+		},
+	},
+	{
+		Key:         "soc2",
+		Name:        "SOC 2",
+		Version:     "2017 Trust Services Criteria",
+		Description: "AICPA Trust Services Criteria for security, availability, processing integrity, confidentiality and privacy.",
+		Requirement: []requirementSeed{
+			{"CC1", "Control environment", "Demonstrate commitment to integrity, board oversight, structure and accountability.", []string{"PM", "PS"}, []string{"A.5.2", "A.5.4", "A.6.1", "A.6.4"}},
+			{"CC2", "Communication and information", "Obtain and communicate quality information internally and externally to support controls.", []string{"PM", "AT", "PL-4"}, []string{"A.5.1", "A.6.3"}},
+			{"CC3", "Risk assessment", "Specify objectives and identify, analyse and respond to risks including fraud and change.", []string{"RA", "PM-9"}, []string{"A.5.7"}},
+			{"CC4", "Monitoring activities", "Evaluate controls and communicate deficiencies in a timely manner.", []string{"CA", "PM-31"}, []string{"A.5.35", "A.5.36"}},
+			{"CC5", "Control activities", "Select and deploy control activities through policies and technology.", []string{"PL", "PM"}, []string{"A.5.1", "A.5.37"}},
+			{"CC6", "Logical and physical access controls", "Restrict logical and physical access, manage credentials, protect against external threats and encrypt data.", []string{"AC", "IA", "PE", "SC-8", "SC-28"}, []string{"A.5.15", "A.5.18", "A.7", "A.8.2", "A.8.5", "A.8.24"}},
+			{"CC7", "System operations", "Detect and monitor for vulnerabilities and anomalies and respond to security incidents.", []string{"SI-4", "RA-5", "IR", "AU-6"}, []string{"A.5.24", "A.5.26", "A.8.8", "A.8.16"}},
+			{"CC8", "Change management", "Authorize, design, test, approve and implement changes to infrastructure and software.", []string{"CM-3", "CM-4", "SA-10"}, []string{"A.8.32"}},
+			{"CC9", "Risk mitigation", "Mitigate risks from business disruption and from vendors and business partners.", []string{"CP", "SR", "SA-9"}, []string{"A.5.19", "A.5.22", "A.5.30"}},
+			{"A1", "Availability", "Manage capacity, environmental protections, backup and recovery to meet availability commitments.", []string{"CP", "PE-11", "SC-5"}, []string{"A.8.6", "A.8.13", "A.8.14"}},
+			{"C1", "Confidentiality", "Identify, protect and dispose of confidential information.", []string{"SC-28", "MP-6", "SI-12"}, []string{"A.5.12", "A.8.10", "A.8.24"}},
+			{"PI1", "Processing integrity", "Ensure processing is complete, valid, accurate, timely and authorized.", []string{"SI-10", "SI-7"}, []string{"A.8.28"}},
+			{"P1-P8", "Privacy", "Provide notice, choice and consent, and manage collection, use, retention, disclosure and quality of personal information.", []string{"PT"}, []string{"A.5.34"}},
+		},
+	},
+	{
+		Key:         "iso-27001",
+		Name:        "ISO/IEC 27001",
+		Version:     "2022",
+		Description: "Requirements for an information security management system and its Annex A reference controls.",
+		Requirement: []requirementSeed{
+			{"4", "Context of the organization", "Determine internal and external issues, interested parties and the scope of the ISMS.", []string{"PM-11"}, nil},
+			{"5", "Leadership", "Top management commitment, an information security policy and assigned roles and responsibilities.", []string{"PM-1", "PM-2"}, []string{"A.5.1", "A.5.2"}},
+			{"6.1", "Risk assessment and treatment", "Define and apply a risk assessment process and a risk treatment plan with a statement of applicability.", []string{"RA", "PM-9"}, []string{"A.5.7"}},
+			{"7", "Support", "Provide resources, competence, awareness, communication and documented information.", []string{"AT", "PM-13"}, []string{"A.6.3"}},
+			{"8", "Operation", "Plan and control ISMS processes and perform risk assessments at planned intervals.", []string{"RA-3", "PM-9"}, nil},
+			{"9", "Performance evaluation", "Monitor and measure, run internal audits and hold management reviews.", []string{"CA", "AU-6"}, []string{"A.5.35", "A.5.36"}},
+			{"10", "Improvement", "Address nonconformities with corrective action and improve the ISMS continually.", []string{"PM-4", "CA-5"}, nil},
+			{"A.5", "Organizational controls", "Policies, roles, asset and access management, supplier relationships, incident management and continuity.", []string{"PM", "PL", "SR", "IR", "CP"}, []string{"A.5"}},
+			{"A.6", "People controls", "Screening, terms of employment, awareness, discipline and remote working.", []string{"PS", "AT"}, []string{"A.6"}},
+			{"A.7", "Physical controls", "Physical perimeters, entry, secure areas, equipment and media.", []string{"PE", "MP"}, []string{"A.7"}},
+			{"A.8", "Technological controls", "Endpoint, access, authentication, malware, vulnerability, logging, network, cryptography and secure development controls.", []string{"AC", "IA", "AU", "CM", "SC", "SI", "SA", "RA-5"}, []string{"A.8"}},
+		},
+	},
+	{
+		Key:         "nist-csf",
+		Name:        "NIST Cybersecurity Framework",
+		Version:     "2.0",
+		Description: "NIST CSF core functions Govern, Identify, Protect, Detect, Respond and Recover with their categories.",
+		Requirement: []requirementSeed{
+			{"GV.OC", "Organizational Context", "Understand the mission, stakeholders and legal requirements surrounding cybersecurity risk decisions.", []string{"PM-11", "PM-8"}, []string{"A.5.31"}},
+			{"GV.RM", "Risk Management Strategy", "Establish and communicate risk priorities, appetite and tolerance.", []string{"PM-9", "RA-7"}, []string{"A.5.7"}},
+			{"GV.RR", "Roles, Responsibilities, and Authorities", "Establish accountability and roles for cybersecurity risk management.", []string{"PM-2", "PS-9"}, []string{"A.5.2", "A.5.4"}},
+			{"GV.PO", "Policy", "Establish, communicate and enforce cybersecurity policy.", []string{"PL-1", "PM-1"}, []string{"A.5.1"}},
+			{"GV.OV", "Oversight", "Review and adjust the risk management strategy using results of activities.", []string{"PM-4", "CA-7"}, []string{"A.5.35"}},
+			{"GV.SC", "Cybersecurity Supply Chain Risk Management", "Identify, assess and manage supply chain risks.", []string{"SR", "SA-9"}, []string{"A.5.19", "A.5.20", "A.5.21", "A.5.22"}},
+			{"ID.AM", "Asset Management", "Inventory and manage hardware, software, services, data and systems by criticality.", []string{"CM-8", "PM-5"}, []string{"A.5.9", "A.5.10", "A.5.12"}},
+			{"ID.RA", "Risk Assessment", "Identify vulnerabilities, threats, likelihood and impact to determine risk.", []string{"RA", "SI-5"}, []string{"A.5.7", "A.8.8"}},
+			{"ID.IM", "Improvement", "Identify improvements from evaluations, tests and exercises.", []string{"CA-5", "PM-4"}, []string{"A.5.27"}},
+			{"PR.AA", "Identity Management, Authentication, and Access Control", "Limit access to authorized users, services and hardware.", []string{"AC", "IA", "PE-2", "PE-3"}, []string{"A.5.15", "A.5.16", "A.5.17", "A.5.18", "A.8.5"}},
+			{"PR.AT", "Awareness and Training", "Provide awareness and training so personnel can perform cybersecurity tasks.", []string{"AT"}, []string{"A.6.3"}},
+			{"PR.DS", "Data Security", "Protect the confidentiality, integrity and availability of data at rest, in transit and in use.", []string{"SC-8", "SC-28", "SI-7", "CP-9", "MP"}, []string{"A.8.10", "A.8.11", "A.8.12", "A.8.13", "A.8.24"}},
+			{"PR.PS", "Platform Security", "Manage hardware, software and services securely, including configuration, maintenance and logging.", []string{"CM", "SI-2", "SA-10", "AU-2", "MA"}, []string{"A.8.8", "A.8.9", "A.8.15", "A.8.19", "A.8.32"}},
+			{"PR.IR", "Technology Infrastructure Resilience", "Protect networks and environments and maintain resilience and capacity.", []string{"SC-7", "CP-2", "SC-5", "PE-11"}, []string{"A.8.6", "A.8.14", "A.8.20", "A.8.22"}},
+			{"DE.CM", "Continuous Monitoring", "Monitor assets, networks, personnel and external providers to find adverse events.", []string{"SI-4", "AU-6", "CA-7", "PE-6"}, []string{"A.8.16", "A.7.4"}},
+			{"DE.AE", "Adverse Event Analysis", "Analyse anomalies and indicators of compromise to characterize events.", []string{"AU-6", "IR-4", "SI-4"}, []string{"A.5.25", "A.8.16"}},
+			{"RS.MA", "Incident Management", "Execute the incident response plan and triage and escalate incidents.", []string{"IR-4", "IR-8"}, []string{"A.5.24", "A.5.26"}},
+			{"RS.AN", "Incident Analysis", "Investigate incidents to establish root cause and preserve evidence.", []string{"IR-4", "AU-7"}, []string{"A.5.27", "A.5.28"}},
+			{"RS.CO", "Incident Response Reporting and Communication", "Coordinate response activities with internal and external stakeholders.", []string{"IR-6", "IR-7"}, []string{"A.5.5", "A.6.8"}},
+			{"RS.MI", "Incident Mitigation", "Contain and eradicate incidents.", []string{"IR-4"}, []string{"A.5.26"}},
+			{"RC.RP", "Incident Recovery Plan Execution", "Restore affected systems and services and verify their integrity.", []string{"CP-10", "IR-4"}, []string{"A.5.29", "A.5.30"}},
+			{"RC.CO", "Incident Recovery Communication", "Coordinate restoration activities and communicate recovery progress.", []string{"CP-2", "IR-6"}, []string{"A.5.26"}},
+		},
+	},
+}
+
+var requirementSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+func requirementId(regulationKey string, code string) string {
+	return regulationKey + "-" + strings.Trim(requirementSlug.ReplaceAllString(strings.ToLower(code), "-"), "-")
+}
+
+func seedStrings(values []string) []*string {
+	pointers := []*string{}
+	for i := range values {
+		pointers = append(pointers, &values[i])
+	}
+
+	return pointers
+}
+
+func SeedRegulations() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	upsert := true
+	for _, regulation := range regulationCatalog {
+		requirements := []*models.Requirement{}
+		for _, seed := range regulation.Requirement {
+			requirement := seed
+			id := requirementId(regulation.Key, requirement.Code)
+			requirements = append(requirements, &models.Requirement{
+				Requirement_id: &id,
+				Code:           &requirement.Code,
+				Title:          &requirement.Title,
+				Description:    &requirement.Description,
+				NIST:           seedStrings(requirement.NIST),
+				ISO:            seedStrings(requirement.ISO),
+			})
+		}
+
+		now := time.Now()
+		_, err := regulationCollection.UpdateOne(
+			ctx,
+			bson.M{"regulation_id": regulation.Key},
+			bson.M{
+				"$set": bson.M{
+					"name":        regulation.Name,
+					"version":     regulation.Version,
+					"description": regulation.Description,
+					"requirement": requirements,
+					"updated_at":  now,
+				},
+				"$setOnInsert": bson.M{
+					"_id":        primitive.NewObjectID(),
+					"created_at": now,
+				},
+			},
+			&options.UpdateOptions{Upsert: &upsert},
+		)
+		if err != nil {
+			log.Printf("Failed to seed regulation %s: %v", regulation.Key, err)
+		}
+	}
+}
+
+func GetRegulations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		cursor, err := regulationCollection.Find(
+			ctx,
+			bson.M{},
+			options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetProjection(bson.M{"requirement": 0}),
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing regulation items"})
+			return
+		}
+
+		var regulations []bson.M
+		if err = cursor.All(ctx, &regulations); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing regulation items"})
+			return
+		}
+		if regulations == nil {
+			regulations = []bson.M{}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"total_count":      len(regulations),
+			"regulation_items": regulations,
+		})
+	}
+}
+
+func GetRegulation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var regulation models.Regulation
+		err := regulationCollection.FindOne(ctx, bson.M{"regulation_id": c.Param("regulation_id")}).Decode(&regulation)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "regulation not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching regulation"})
+			return
+		}
+
+		c.JSON(http.StatusOK, regulation)
+	}
+}
+
+func loadRegulations(ctx context.Context, filter bson.M) ([]models.Regulation, error) {
+	cursor, err := regulationCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	var regulations []models.Regulation
+	if err = cursor.All(ctx, &regulations); err != nil {
+		return nil, err
+	}
+
+	return regulations, nil
+}
+
+// checkRegulationIds reports whether every id names a regulation in the catalog.
+func checkRegulationIds(ctx context.Context, ids []*string) bool {
+	unique := uniqueIds(ids)
+	if len(unique) != len(ids) {
+		return false
+	}
+	if len(unique) == 0 {
+		return true
+	}
+
+	count, err := regulationCollection.CountDocuments(ctx, bson.M{"regulation_id": bson.M{"$in": unique}})
+
+	return err == nil && int(count) == len(unique)
+}
+
+var nistControlCode = regexp.MustCompile(`\b([A-Z]{2})-(\d+)`)
+
+// controlCodes extracts NIST SP 800-53 controls ("AC-2") and ISO 27001 Annex A controls ("A.8.24") from free text.
+// ISO references need at least a theme and a control number, so neither "clause 5" nor a bare "A.5" names a
+// whole theme.
+func controlCodes(control *models.Control) ([]string, []string) {
+	nist := []string{}
+	if control.NIST != nil {
+		for _, match := range nistControlCode.FindAllStringSubmatch(strings.ToUpper(*control.NIST), -1) {
+			nist = append(nist, match[1]+"-"+match[2])
+		}
+	}
+
+	iso := []string{}
+	if control.ISO != nil {
+		iso = helper.ISOCodes(*control.ISO)
+	}
+
+	return nist, iso
+}
+
+// referenceMatches accepts an exact control, a family reference ("AC" for "AC-2") or a parent ISO
+// reference ("A.8" for "A.8.24").
+func referenceMatches(reference string, code string, separator string) bool {
+	return reference == code || strings.HasPrefix(code, reference+separator)
+}
+
+func requirementMatches(requirement *models.Requirement, nist []string, iso []string) bool {
+	for _, reference := range requirement.NIST {
+		for _, code := range nist {
+			if reference != nil && referenceMatches(*reference, code, "-") {
+				return true
+			}
+		}
+	}
+
+	for _, reference := range requirement.ISO {
+		for _, code := range iso {
+			if reference != nil && referenceMatches(*reference, code, ".") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchedRequirements returns the ids of the requirements cross-referencing the control's NIST or ISO codes.
+func matchedRequirements(control *models.Control, regulations []models.Regulation) map[string]bool {
+	matched := map[string]bool{}
+	nist, iso := controlCodes(control)
+	for _, regulation := range regulations {
+		for _, requirement := range regulation.Requirement {
+			if requirement != nil && requirement.Requirement_id != nil && requirementMatches(requirement, nist, iso) {
+				matched[*requirement.Requirement_id] = true
+			}
+		}
+	}
+
+	return matched
+}
+
+// mapControlRequirements sets the requirements each control satisfies: the explicit ids given by the client,
+// which must exist in the catalog, plus every requirement cross-referencing the control's NIST or ISO codes.
+func mapControlRequirements(ctx context.Context, content *models.Content) (bool, error) {
+	if content == nil {
+		return true, nil
+	}
+
+	regulations, err := loadRegulations(ctx, bson.M{})
+	if err != nil {
+		return false, err
+	}
+
+	known := map[string]bool{}
+	for _, regulation := range regulations {
+		for _, requirement := range regulation.Requirement {
+			if requirement != nil && requirement.Requirement_id != nil {
+				known[*requirement.Requirement_id] = true
+			}
+		}
+	}
+
+	for _, vulnerability := range content.Vulnerability {
+		if vulnerability == nil {
+			continue
+		}
+		for _, control := range vulnerability.Control {
+			if control == nil {
+				continue
+			}
+
+			mapped := map[string]bool{}
+			for _, id := range control.Requirement {
+				if id == nil || !known[*id] {
+					return false, nil
+				}
+				mapped[*id] = true
+			}
+
+			for id := range matchedRequirements(control, regulations) {
+				mapped[id] = true
+			}
+
+			ids := make([]string, 0, len(mapped))
+			for id := range mapped {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+
+			control.Requirement = []*string{}
+			for i := range ids {
+				control.Requirement = append(control.Requirement, &ids[i])
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// resultRequirementControls indexes the controls of a result by the requirement ids they satisfy.
+func resultRequirementControls(content *models.Content) map[string][]gin.H {
+	addressed := map[string][]gin.H{}
+	if content == nil {
+		return addressed
+	}
+
+	for i, vulnerability := range content.Vulnerability {
+		if vulnerability == nil {
+			continue
+		}
+		for _, control := range vulnerability.Control {
+			if control == nil {
+				continue
+			}
+			for _, id := range control.Requirement {
+				if id == nil {
+					continue
+				}
+				addressed[*id] = append(addressed[*id], gin.H{
+					"vulnerability": i,
+					"name":          control.Name,
+					"nist":          control.NIST,
+					"iso":           control.ISO,
+				})
+			}
+		}
+	}
+
+	return addressed
+}
+
+// GetResultRequirements reports, for the regulations selected by the assessed organization (or every regulation
+// a control maps to when none is selected), which requirements the result's controls address.
+func GetResultRequirements() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resultId := c.Param("result_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		result, ok := findResult(c, ctx, resultId)
+		if !ok {
+			return
+		}
+
+		addressed := resultRequirementControls(result.Content)
+
+		selected := []string{}
+		if result.Assessment_id != nil {
+			var assessment models.Assessment
+			if err := assessmentCollection.FindOne(ctx, bson.M{"assessment_id": result.Assessment_id}).Decode(&assessment); err == nil && assessment.Organization_id != nil {
+				var organization models.Organization
+				if err := organizationCollection.FindOne(ctx, bson.M{"organization_id": assessment.Organization_id}).Decode(&organization); err == nil {
+					selected = uniqueIds(organization.Regulation_id)
+				}
+			}
+		}
+
+		filter := bson.M{}
+		if len(selected) > 0 {
+			filter["regulation_id"] = bson.M{"$in": selected}
+		}
+		regulations, err := loadRegulations(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing regulation items"})
+			return
+		}
+
+		items := []gin.H{}
+		for _, regulation := range regulations {
+			requirements := []gin.H{}
+			addressedCount := 0
+			for _, requirement := range regulation.Requirement {
+				if requirement == nil || requirement.Requirement_id == nil {
+					continue
+				}
+				controls := addressed[*requirement.Requirement_id]
+				if len(controls) > 0 {
+					addressedCount++
+				}
+				requirements = append(requirements, gin.H{
+					"requirement_id": requirement.Requirement_id,
+					"code":           requirement.Code,
+					"title":          requirement.Title,
+					"addressed":      len(controls) > 0,
+					"control":        controls,
+				})
+			}
+
+			if len(selected) == 0 && addressedCount == 0 {
+				continue
+			}
+
+			items = append(items, gin.H{
+				"regulation_id":   regulation.Regulation_id,
+				"name":            regulation.Name,
+				"version":         regulation.Version,
+				"total_count":     len(requirements),
+				"addressed_count": addressedCount,
+				"requirement":     requirements,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"result_id":        result.Result_id,
+			"selected":         len(selected) > 0,
+			"regulation_items": items,
+		})
+	}
+}
@@ -0,0 +1,427 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// resultReport gathers everything a report on a result shows. Assessment, Organization and Matrix are nil
+// when the result no longer links to them.
+type resultReport struct {
+	Result        models.Result
+	Assessment    *models.Assessment
+	Organization  *models.Organization
+	Regulations   []models.Regulation
+	Matrix        *models.Matrix
+	MatrixVersion int
+	Generated_at  time.Time
+}
+
+// findResult loads a result the caller may view. It returns false when an error response has already been written.
+func findResult(c *gin.Context, ctx context.Context, resultId string) (*models.Result, bool) {
+	var result models.Result
+	err := resultCollection.FindOne(ctx, bson.M{"result_id": resultId}).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "result not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching result"})
+		return nil, false
+	}
+
+	if c.GetString("user_type") != "ADMIN" {
+		if result.User_id == nil || *result.User_id != c.GetString("uid") || (result.Status != nil && *result.Status != 1 && *result.Status != 2) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this result"})
+			return nil, false
+		}
+	}
+
+	return &result, true
+}
+
+func loadResultReport(c *gin.Context, ctx context.Context) (*resultReport, bool) {
+	found, ok := findResult(c, ctx, c.Param("result_id"))
+	if !ok {
+		return nil, false
+	}
+	result := *found
+
+	report := &resultReport{Result: result, Generated_at: time.Now()}
+	if result.Assessment_id != nil {
+		var assessment models.Assessment
+		if err := assessmentCollection.FindOne(ctx, bson.M{"assessment_id": result.Assessment_id}).Decode(&assessment); err == nil {
+			report.Assessment = &assessment
+		}
+	}
+	if report.Assessment != nil && report.Assessment.Organization_id != nil {
+		var organization models.Organization
+		if err := organizationCollection.FindOne(ctx, bson.M{"organization_id": report.Assessment.Organization_id}).Decode(&organization); err == nil {
+			report.Organization = &organization
+			if selected := uniqueIds(organization.Regulation_id); len(selected) > 0 {
+				report.Regulations, _ = loadRegulations(ctx, bson.M{"regulation_id": bson.M{"$in": selected}})
+			}
+		}
+	}
+	if matrixVersion, _, _, err := resultMatrixVersion(ctx, result); err == nil {
+		report.Matrix = matrixVersion.Matrix
+		report.MatrixVersion = matrixVersion.Version
+	}
+
+	return report, true
+}
+
+func (r *resultReport) Title() string {
+	if r.Assessment != nil && r.Assessment.Name != nil {
+		return *r.Assessment.Name
+	}
+
+	return "Result " + r.Result.Result_id
+}
+
+func (r *resultReport) Vulnerabilities() []*models.Vulnerability {
+	vulnerabilities := []*models.Vulnerability{}
+	if r.Result.Content == nil {
+		return vulnerabilities
+	}
+	for _, vulnerability := range r.Result.Content.Vulnerability {
+		if vulnerability != nil {
+			vulnerabilities = append(vulnerabilities, vulnerability)
+		}
+	}
+
+	return vulnerabilities
+}
+
+// Heatmap counts vulnerabilities per likelihood (rows, highest first) and impact (columns) level, using the
+// residual scores when residual is true.
+func (r *resultReport) Heatmap(residual bool) ([]string, []string, [][]int) {
+	if r.Matrix == nil {
+		return nil, nil, nil
+	}
+
+	rows := []string{}
+	for i := len(r.Matrix.Likelihood) - 1; i >= 0; i-- {
+		rows = append(rows, levelName(r.Matrix.Likelihood[i]))
+	}
+	columns := []string{}
+	for _, level := range r.Matrix.Impact {
+		columns = append(columns, levelName(level))
+	}
+
+	counts := make([][]int, len(rows))
+	for i := range counts {
+		counts[i] = make([]int, len(columns))
+	}
+	for _, vulnerability := range r.Vulnerabilities() {
+		impact, likelihood := vulnerability.Impact, vulnerability.Likelihood
+		if residual {
+			impact, likelihood = vulnerability.New_impact, vulnerability.New_likelihood
+		}
+
+		column := levelIndex(r.Matrix.Impact, impact)
+		row := levelIndex(r.Matrix.Likelihood, likelihood)
+		if column >= 0 && row >= 0 {
+			counts[len(rows)-1-row][column]++
+		}
+	}
+
+	return rows, columns, counts
+}
+
+func levelIndex(levels []*models.Level, value *int) int {
+	level := findLevel(levels, value)
+	for i := range levels {
+		if level != nil && levels[i] == level {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func levelName(level *models.Level) string {
+	if level == nil || level.Label == nil {
+		return ""
+	}
+
+	return *level.Label
+}
+
+// ScoreLabel names the level scored on an axis, e.g. "High (4)", falling back to the raw value.
+func (r *resultReport) ScoreLabel(axis string, value *int) string {
+	if value == nil {
+		return "-"
+	}
+
+	if r.Matrix != nil {
+		levels := r.Matrix.Impact
+		if axis == "likelihood" {
+			levels = r.Matrix.Likelihood
+		}
+		if level := findLevel(levels, value); level != nil && level.Label != nil {
+			return fmt.Sprintf("%s (%d)", *level.Label, *value)
+		}
+	}
+
+	return strconv.Itoa(*value)
+}
+
+func joinValues(values []*string, separator string) string {
+	parts := []string{}
+	for _, value := range values {
+		if value != nil && strings.TrimSpace(*value) != "" {
+			parts = append(parts, strings.TrimSpace(*value))
+		}
+	}
+
+	return strings.Join(parts, separator)
+}
+
+func textValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+func numberValue(value *int) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*value)
+}
+
+// formatAmount renders a monetary amount rounded to whole units with thousands separators.
+func formatAmount(value *float64) string {
+	if value == nil {
+		return ""
+	}
+
+	digits := strconv.FormatFloat(*value, 'f', 0, 64)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+
+	return sign + digits
+}
+
+func (r *resultReport) RegulationNames() string {
+	names := []string{}
+	if r.Organization != nil {
+		for _, regulation := range r.Organization.Regulation {
+			if regulation != nil && *regulation != "" {
+				names = append(names, *regulation)
+			}
+		}
+	}
+	for _, regulation := range r.Regulations {
+		if regulation.Name != nil {
+			names = append(names, *regulation.Name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func controlLine(control *models.Control) string {
+	line := textValue(control.Name)
+	if description := textValue(control.Description); description != "" {
+		line += " – " + description
+	}
+
+	references := []string{}
+	if nist := textValue(control.NIST); nist != "" {
+		references = append(references, "NIST "+nist)
+	}
+	if iso := textValue(control.ISO); iso != "" {
+		references = append(references, "ISO "+iso)
+	}
+	if len(references) > 0 {
+		line += " (" + strings.Join(references, "; ") + ")"
+	}
+
+	return line
+}
+
+func levelLine(level *models.Level) string {
+	line := fmt.Sprintf("%s (%s)", levelName(level), numberValue(level.Value))
+	if level.Min != nil || level.Max != nil {
+		line += fmt.Sprintf(" [%s – %s]", floatText(level.Min), floatText(level.Max))
+	}
+	if description := textValue(level.Description); description != "" {
+		line += ": " + description
+	}
+
+	return line
+}
+
+func floatText(value *float64) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+var reportFileName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func reportAttachment(c *gin.Context, report *resultReport, extension string) {
+	name := strings.Trim(reportFileName.ReplaceAllString(report.Title(), "-"), "-")
+	if name == "" {
+		name = report.Result.Result_id
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.%s\"", name, extension))
+}
+
+func reportBrand() (string, helper.Color) {
+	brand := os.Getenv("REPORT_BRAND_NAME")
+	if brand == "" {
+		brand = "AICRAM"
+	}
+
+	return brand, helper.ParseColor(os.Getenv("REPORT_BRAND_COLOR"), helper.Color{0.12, 0.31, 0.47})
+}
+
+func buildResultPDF(report *resultReport) []byte {
+	brand, color := reportBrand()
+	pdf := helper.NewPDF(report.Title(), brand, color)
+
+	pdf.Heading("Risk Assessment Report")
+	pdf.Field("Assessment", report.Title())
+	if report.Organization != nil {
+		pdf.Field("Organization", textValue(report.Organization.Name))
+	}
+	if report.Matrix != nil {
+		pdf.Field("Risk matrix", fmt.Sprintf("%s (version %d)", textValue(report.Matrix.Name), report.MatrixVersion))
+	}
+	pdf.Field("Result", report.Result.Result_id)
+	pdf.Field("Generated", report.Generated_at.Format("2 January 2006 15:04 MST"))
+
+	if report.Assessment != nil {
+		pdf.Heading("Situation")
+		pdf.Paragraph(textValue(report.Assessment.Situation))
+		pdf.Field("Assets", joinValues(report.Assessment.Asset, ", "))
+		pdf.Field("Threats", joinValues(report.Assessment.Threat, ", "))
+		pdf.Field("Constraints", textValue(report.Assessment.Constraint))
+	}
+
+	if organization := report.Organization; organization != nil {
+		pdf.Heading("Organization Profile")
+		pdf.Field("Name", textValue(organization.Name))
+		pdf.Field("Industry", textValue(organization.Industry))
+		pdf.Field("Country", textValue(organization.Country))
+		pdf.Field("Employees", numberValue(organization.Employees))
+		pdf.Field("Customers", numberValue(organization.Customers))
+		pdf.Field("Revenue", formatAmount(organization.Revenue))
+		pdf.Field("Regulations", report.RegulationNames())
+		pdf.Field("Description", textValue(organization.Description))
+		pdf.Field("Structure", textValue(organization.Structure))
+		pdf.Field("Architecture", textValue(organization.Architecture))
+		pdf.Field("Security measures", textValue(organization.Measure))
+		pdf.Field("Constraints", textValue(organization.Constraint))
+	}
+
+	if matrix := report.Matrix; matrix != nil {
+		pdf.Heading("Risk Matrix")
+		pdf.Field("Name", textValue(matrix.Name))
+		pdf.Field("Levels", fmt.Sprintf("%d x %d", len(matrix.Likelihood), len(matrix.Impact)))
+		pdf.Field("Description", textValue(matrix.Description))
+		pdf.Subheading("Impact")
+		for _, level := range matrix.Impact {
+			if level != nil {
+				pdf.Bullet(levelLine(level))
+			}
+		}
+		pdf.Subheading("Likelihood")
+		for _, level := range matrix.Likelihood {
+			if level != nil {
+				pdf.Bullet(levelLine(level))
+			}
+		}
+
+		pdf.Heading("Risk Heatmaps")
+		rows, columns, counts := report.Heatmap(false)
+		pdf.Heatmap("Inherent risk", rows, columns, counts)
+		rows, columns, counts = report.Heatmap(true)
+		pdf.Heatmap("Residual risk", rows, columns, counts)
+	}
+
+	pdf.Heading("Vulnerabilities")
+	vulnerabilities := report.Vulnerabilities()
+	if len(vulnerabilities) == 0 {
+		pdf.Paragraph("No vulnerabilities were identified.")
+	}
+	for i, vulnerability := range vulnerabilities {
+		pdf.Subheading(fmt.Sprintf("%d. %s", i+1, textValue(vulnerability.Name)))
+		pdf.Paragraph(textValue(vulnerability.Description))
+		pdf.Field("Inherent risk", fmt.Sprintf("Impact %s, likelihood %s",
+			report.ScoreLabel("impact", vulnerability.Impact), report.ScoreLabel("likelihood", vulnerability.Likelihood)))
+		pdf.Field("Residual risk", fmt.Sprintf("Impact %s, likelihood %s",
+			report.ScoreLabel("impact", vulnerability.New_impact), report.ScoreLabel("likelihood", vulnerability.New_likelihood)))
+		pdf.Field("CVE", joinValues(vulnerability.CVE, ", "))
+		pdf.Field("MITRE ATT&CK", joinValues(vulnerability.MITRE, ", "))
+		if vulnerability.Loss != nil {
+			pdf.Field("Annual loss (mean)", formatAmount(vulnerability.Loss.Mean))
+		}
+		if vulnerability.New_loss != nil {
+			pdf.Field("Residual loss (mean)", formatAmount(vulnerability.New_loss.Mean))
+		}
+
+		controls := []*models.Control{}
+		for _, control := range vulnerability.Control {
+			if control != nil {
+				controls = append(controls, control)
+			}
+		}
+		if len(controls) > 0 {
+			pdf.Subheading("Recommended controls")
+			for _, control := range controls {
+				pdf.Bullet(controlLine(control))
+			}
+		}
+		pdf.Space(6)
+	}
+
+	if content := report.Result.Content; content != nil && textValue(content.Summary) != "" {
+		pdf.Heading("Summary")
+		pdf.Paragraph(*content.Summary)
+	}
+
+	return pdf.Bytes()
+}
+
+func GetResultPDF() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		report, ok := loadResultReport(c, ctx)
+		if !ok {
+			return
+		}
+
+		reportAttachment(c, report, "pdf")
+		c.Data(http.StatusOK, "application/pdf", buildResultPDF(report))
+	}
+}
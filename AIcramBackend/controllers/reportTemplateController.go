@@ -0,0 +1,768 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"user-athentication-golang/database"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var reportTemplateCollection *mongo.Collection = database.OpenCollection(database.Client, "report_template")
+var reportTemplateValidate = validator.New()
+
+const defaultReportTemplateKey = "default"
+
+// A report template runs for at most reportRenderTimeout and may produce at most reportOutputLimit bytes. It
+// may hold at most reportNodeLimit parse nodes and take at most reportStepLimit range iterations and
+// template calls.
+const reportRenderTimeout = 10 * time.Second
+const reportOutputLimit = 5 << 20
+const reportNodeLimit = 10000
+const reportStepLimit = 200000
+
+// reportStepFunc names the budget check added to templates; calling it directly only spends budget.
+const reportStepFunc = "reportStep"
+
+var errReportTooLarge = fmt.Errorf("report exceeds %d bytes", reportOutputLimit)
+var errReportTooManySteps = fmt.Errorf("report takes more than %d loop iterations", reportStepLimit)
+
+// defaultReportTemplate is seeded once as the built-in template; admins may edit it afterwards.
+const defaultReportTemplate = `# Risk Assessment Report
+
+| | |
+|---|---|
+| Assessment | {{.Title}} |
+{{- with .Organization}}
+| Organization | {{text .Name}} |
+{{- end}}
+{{- with .Matrix}}
+| Risk matrix | {{text .Name}} (version {{$.MatrixVersion}}) |
+{{- end}}
+| Result | {{.Result.Result_id}} |
+| Generated | {{date .Generated_at "2 January 2006"}} |
+{{with .Assessment}}
+## Situation
+
+{{text .Situation}}
+{{with join .Asset ", "}}
+**Assets:** {{.}}
+{{end}}{{with join .Threat ", "}}
+**Threats:** {{.}}
+{{end}}{{with text .Constraint}}
+**Constraints:** {{.}}
+{{end}}{{end}}
+{{- with .Organization}}
+## Organization Profile
+
+| | |
+|---|---|
+| Industry | {{text .Industry}} |
+| Country | {{text .Country}} |
+| Employees | {{number .Employees}} |
+| Customers | {{number .Customers}} |
+| Revenue | {{amount .Revenue}} |
+| Regulations | {{$.RegulationNames}} |
+{{with text .Description}}
+{{.}}
+{{end}}{{end}}
+{{- with .Matrix}}
+## Risk Matrix
+
+{{text .Description}}
+
+### Impact
+{{range .Impact}}
+- {{level .}}
+{{- end}}
+
+### Likelihood
+{{range .Likelihood}}
+- {{level .}}
+{{- end}}
+
+## Risk Heatmaps
+
+### Inherent risk
+
+{{$.HeatmapTable false}}
+### Residual risk
+
+{{$.HeatmapTable true}}
+{{- end}}
+## Vulnerabilities
+{{range $i, $v := .Vulnerabilities}}
+### {{inc $i}}. {{text $v.Name}}
+
+{{text $v.Description}}
+
+- **Inherent risk:** impact {{$.ScoreLabel "impact" $v.Impact}}, likelihood {{$.ScoreLabel "likelihood" $v.Likelihood}}
+- **Residual risk:** impact {{$.ScoreLabel "impact" $v.New_impact}}, likelihood {{$.ScoreLabel "likelihood" $v.New_likelihood}}
+{{- with join $v.CVE ", "}}
+- **CVE:** {{.}}
+{{- end}}
+{{- with join $v.MITRE ", "}}
+- **MITRE ATT&CK:** {{.}}
+{{- end}}
+{{- with $v.Loss}}
+- **Annual loss (mean):** {{amount .Mean}}
+{{- end}}
+{{- if $v.Control}}
+
+**Recommended controls**
+{{range $v.Control}}{{if .}}
+- {{control .}}
+{{- end}}{{end}}
+{{- end}}
+{{else}}
+No vulnerabilities were identified.
+{{end}}
+{{- with .Result.Content}}{{with text .Summary}}
+## Summary
+
+{{.}}
+{{end}}{{end}}`
+
+var reportTemplateFuncs = template.FuncMap{
+	"text":    textValue,
+	"number":  numberValue,
+	"amount":  formatAmount,
+	"join":    joinValues,
+	"control": controlLine,
+	"level":   levelLine,
+	"inc":     func(i int) int { return i + 1 },
+	"date":    func(t time.Time, layout string) string { return t.Format(layout) },
+}
+
+// reportBudget bounds a template run. Execute cannot be interrupted, so parseReportTemplate makes every range
+// iteration and template call go through step, which fails once the budget's context ends or the run has
+// taken more than reportStepLimit steps. Loops that never write are stopped this way too.
+type reportBudget struct {
+	ctx   context.Context
+	steps int
+}
+
+func (b *reportBudget) step() (string, error) {
+	if err := b.ctx.Err(); err != nil {
+		return "", err
+	}
+	b.steps++
+	if b.steps > reportStepLimit {
+		return "", errReportTooManySteps
+	}
+
+	return "", nil
+}
+
+// parseReportTemplate parses body, rejects templates that are too large or range over a number, and
+// instruments the rest with budget.
+func parseReportTemplate(body string, budget *reportBudget) (*template.Template, error) {
+	parsed, err := template.New("report").
+		Funcs(reportTemplateFuncs).
+		Funcs(template.FuncMap{reportStepFunc: budget.step}).
+		Option("missingkey=zero").
+		Parse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := 0
+	for _, t := range parsed.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		if err := instrumentReportNode(t.Tree, t.Tree.Root, &nodes); err != nil {
+			return nil, err
+		}
+		t.Tree.Root.Nodes = append([]parse.Node{reportStepNode(t.Tree, t.Tree.Root.Pos)}, t.Tree.Root.Nodes...)
+	}
+
+	return parsed, nil
+}
+
+// instrumentReportNode counts the nodes under node and adds a step call to the start of every range body.
+func instrumentReportNode(tree *parse.Tree, node parse.Node, nodes *int) error {
+	if node == nil {
+		return nil
+	}
+	*nodes++
+	if *nodes > reportNodeLimit {
+		return fmt.Errorf("template has more than %d nodes", reportNodeLimit)
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := instrumentReportNode(tree, child, nodes); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return instrumentReportNode(tree, n.Pipe, nodes)
+	case *parse.TemplateNode:
+		return instrumentReportNode(tree, n.Pipe, nodes)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, command := range n.Cmds {
+			if err := instrumentReportNode(tree, command, nodes); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := instrumentReportNode(tree, arg, nodes); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		return instrumentReportNode(tree, n.Node, nodes)
+	case *parse.IfNode:
+		return instrumentReportBranch(tree, &n.BranchNode, nodes)
+	case *parse.WithNode:
+		return instrumentReportBranch(tree, &n.BranchNode, nodes)
+	case *parse.RangeNode:
+		if n.Pipe != nil && len(n.Pipe.Cmds) == 1 && len(n.Pipe.Cmds[0].Args) == 1 {
+			if _, ok := n.Pipe.Cmds[0].Args[0].(*parse.NumberNode); ok {
+				return fmt.Errorf("range over a number is not supported: %s", n.Pipe)
+			}
+		}
+		if err := instrumentReportBranch(tree, &n.BranchNode, nodes); err != nil {
+			return err
+		}
+		n.List.Nodes = append([]parse.Node{reportStepNode(tree, n.List.Pos)}, n.List.Nodes...)
+	}
+
+	return nil
+}
+
+func instrumentReportBranch(tree *parse.Tree, branch *parse.BranchNode, nodes *int) error {
+	if err := instrumentReportNode(tree, branch.Pipe, nodes); err != nil {
+		return err
+	}
+	if err := instrumentReportNode(tree, branch.List, nodes); err != nil {
+		return err
+	}
+	if branch.ElseList != nil {
+		return instrumentReportNode(tree, branch.ElseList, nodes)
+	}
+
+	return nil
+}
+
+// reportStepNode builds the action {{reportStep}}, which prints nothing.
+func reportStepNode(tree *parse.Tree, pos parse.Pos) parse.Node {
+	command := &parse.CommandNode{
+		NodeType: parse.NodeCommand,
+		Pos:      pos,
+		Args:     []parse.Node{parse.NewIdentifier(reportStepFunc).SetTree(tree).SetPos(pos)},
+	}
+
+	return &parse.ActionNode{
+		NodeType: parse.NodeAction,
+		Pos:      pos,
+		Pipe:     &parse.PipeNode{NodeType: parse.NodePipe, Pos: pos, Cmds: []*parse.CommandNode{command}},
+	}
+}
+
+// HeatmapTable renders Heatmap as a Markdown table for report templates.
+func (r *resultReport) HeatmapTable(residual bool) string {
+	rows, columns, counts := r.Heatmap(residual)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Likelihood / Impact |")
+	for _, column := range columns {
+		sb.WriteString(" " + column + " |")
+	}
+	sb.WriteString("\n|---|" + strings.Repeat("---|", len(columns)) + "\n")
+	for i, row := range rows {
+		sb.WriteString("| " + row + " |")
+		for _, count := range counts[i] {
+			cell := ""
+			if count > 0 {
+				cell = strconv.Itoa(count)
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func SeedReportTemplates() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	upsert := true
+	now := time.Now()
+	id := primitive.NewObjectID()
+	_, err := reportTemplateCollection.UpdateOne(
+		ctx,
+		bson.M{"key": defaultReportTemplateKey},
+		bson.M{
+			"$setOnInsert": bson.M{
+				"_id":         id,
+				"template_id": id.Hex(),
+				"user_id":     nil,
+				"name":        "Default report",
+				"status":      1,
+				"description": "Situation, organization profile, matrix, heatmaps, vulnerabilities with controls and summary.",
+				"body":        defaultReportTemplate,
+				"key":         defaultReportTemplateKey,
+				"created_at":  now,
+				"updated_at":  now,
+			},
+		},
+		&options.UpdateOptions{Upsert: &upsert},
+	)
+	if err != nil {
+		log.Printf("Failed to seed report template %s: %v", defaultReportTemplateKey, err)
+	}
+}
+
+func isBuiltinReportTemplate(reportTemplate models.ReportTemplate) bool {
+	return reportTemplate.Key != nil && *reportTemplate.Key != ""
+}
+
+func canViewReportTemplate(c *gin.Context, reportTemplate models.ReportTemplate) bool {
+	if c.GetString("user_type") == "ADMIN" || isBuiltinReportTemplate(reportTemplate) {
+		return true
+	}
+
+	return reportTemplate.User_id != nil && *reportTemplate.User_id == c.GetString("uid") &&
+		(reportTemplate.Status == nil || *reportTemplate.Status == 1)
+}
+
+// canEditReportTemplate lets owners edit their active templates; built-in templates are edited by admins only.
+func canEditReportTemplate(c *gin.Context, reportTemplate models.ReportTemplate) bool {
+	if c.GetString("user_type") == "ADMIN" {
+		return true
+	}
+
+	return !isBuiltinReportTemplate(reportTemplate) && canViewReportTemplate(c, reportTemplate)
+}
+
+func GetReportTemplates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		recordPerPage, err := strconv.Atoi(c.Query("recordPerPage"))
+		if err != nil || recordPerPage < 1 {
+			recordPerPage = 10
+		}
+
+		page, err1 := strconv.Atoi(c.Query("page"))
+		if err1 != nil || page < 1 {
+			page = 1
+		}
+
+		startIndex := (page - 1) * recordPerPage
+		if queryStartIndex, err := strconv.Atoi(c.Query("startIndex")); err == nil && queryStartIndex >= 0 {
+			startIndex = queryStartIndex
+		}
+
+		matchCriteria := bson.M{}
+		if c.GetString("user_type") != "ADMIN" {
+			matchCriteria = bson.M{"$or": bson.A{
+				bson.M{"user_id": c.GetString("uid"), "status": 1},
+				bson.M{"key": bson.M{"$nin": bson.A{nil, ""}}},
+			}}
+		} else if queryUserId := c.Query("user_id"); queryUserId != "" {
+			matchCriteria["user_id"] = queryUserId
+		}
+
+		pipeline := mongo.Pipeline{
+			bson.D{{Key: "$match", Value: matchCriteria}},
+			bson.D{{Key: "$project", Value: bson.M{"body": 0}}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "key", Value: -1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}}},
+			bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: nil}, {Key: "total_count", Value: bson.M{"$sum": 1}}, {Key: "data", Value: bson.M{"$push": "$$ROOT"}}}}},
+			bson.D{{Key: "$project", Value: bson.D{
+				{Key: "_id", Value: 0},
+				{Key: "total_count", Value: 1},
+				{Key: "template_items", Value: bson.M{"$slice": bson.A{"$data", startIndex, recordPerPage}}},
+			}}},
+		}
+
+		result, err := reportTemplateCollection.Aggregate(ctx, pipeline)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing template items"})
+			return
+		}
+
+		var alltemplates []bson.M
+		if err = result.All(ctx, &alltemplates); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing template items"})
+			return
+		}
+
+		if len(alltemplates) == 0 {
+			c.JSON(http.StatusOK, gin.H{
+				"total_count":    0,
+				"template_items": []bson.M{},
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, alltemplates[0])
+	}
+}
+
+func findReportTemplate(c *gin.Context, ctx context.Context, templateId string) (*models.ReportTemplate, bool) {
+	var reportTemplate models.ReportTemplate
+	err := reportTemplateCollection.FindOne(ctx, bson.M{"template_id": templateId}).Decode(&reportTemplate)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching template"})
+		return nil, false
+	}
+
+	if !canViewReportTemplate(c, reportTemplate) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this template"})
+		return nil, false
+	}
+
+	return &reportTemplate, true
+}
+
+func GetReportTemplate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		reportTemplate, ok := findReportTemplate(c, ctx, c.Param("template_id"))
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, reportTemplate)
+	}
+}
+
+func CreateReportTemplate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var reportTemplate models.ReportTemplate
+		if err := c.BindJSON(&reportTemplate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if reportTemplate.Status == nil {
+			status := 1
+			reportTemplate.Status = &status
+		}
+
+		validationErr := reportTemplateValidate.Struct(reportTemplate)
+		if validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		if _, err := parseReportTemplate(*reportTemplate.Body, &reportBudget{ctx: ctx}); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "template_error", "detail": err.Error()})
+			return
+		}
+
+		if c.GetString("user_type") != "ADMIN" {
+			userId := c.GetString("uid")
+			reportTemplate.User_id = &userId
+		} else {
+			var user models.User
+			err := userCollection.FindOne(ctx, bson.M{"username": reportTemplate.User_id}).Decode(&user)
+			if err != nil || user.Username == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "user_error"})
+				return
+			}
+			userID := user.ID.Hex()
+			reportTemplate.User_id = &userID
+		}
+
+		reportTemplate.Key = nil
+		reportTemplate.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		reportTemplate.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		reportTemplate.ID = primitive.NewObjectID()
+		reportTemplate.Template_id = reportTemplate.ID.Hex()
+
+		if _, err := reportTemplateCollection.InsertOne(ctx, reportTemplate); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "template item was not created"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"template_id": reportTemplate.Template_id})
+	}
+}
+
+func UpdateReportTemplate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		templateId := c.Param("template_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		reportTemplate, ok := findReportTemplate(c, ctx, templateId)
+		if !ok {
+			return
+		}
+		if !canEditReportTemplate(c, *reportTemplate) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to update this template"})
+			return
+		}
+
+		var updateData models.ReportTemplate
+		if err := c.BindJSON(&updateData); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if c.GetString("user_type") != "ADMIN" || isBuiltinReportTemplate(*reportTemplate) {
+			updateData.Status = nil
+		}
+
+		merged := *reportTemplate
+		update := bson.M{}
+		if updateData.Name != nil {
+			merged.Name = updateData.Name
+			update["name"] = updateData.Name
+		}
+		if updateData.Status != nil {
+			merged.Status = updateData.Status
+			update["status"] = updateData.Status
+		}
+		if updateData.Description != nil {
+			merged.Description = updateData.Description
+			update["description"] = updateData.Description
+		}
+		if updateData.Body != nil {
+			merged.Body = updateData.Body
+			update["body"] = updateData.Body
+		}
+
+		if validationErr := reportTemplateValidate.Struct(merged); validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		if _, err := parseReportTemplate(*merged.Body, &reportBudget{ctx: ctx}); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "template_error", "detail": err.Error()})
+			return
+		}
+
+		update["updated_at"] = time.Now().Format(time.RFC3339)
+
+		result, err := reportTemplateCollection.UpdateOne(
+			ctx,
+			bson.M{"template_id": templateId},
+			bson.M{"$set": update},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update template"})
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func DeleteReportTemplate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := helper.CheckUserType(c, "ADMIN"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		templateId := c.Param("template_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		reportTemplate, ok := findReportTemplate(c, ctx, templateId)
+		if !ok {
+			return
+		}
+		if isBuiltinReportTemplate(*reportTemplate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "built-in templates cannot be deleted"})
+			return
+		}
+
+		result, err := reportTemplateCollection.DeleteOne(ctx, bson.M{"template_id": templateId})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete template"})
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func RemoveReportTemplate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		templateId := c.Param("template_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		reportTemplate, ok := findReportTemplate(c, ctx, templateId)
+		if !ok {
+			return
+		}
+		if isBuiltinReportTemplate(*reportTemplate) || !canEditReportTemplate(c, *reportTemplate) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to remove this template"})
+			return
+		}
+
+		result, err := reportTemplateCollection.UpdateOne(
+			ctx,
+			bson.M{"template_id": templateId},
+			bson.M{"$set": bson.M{
+				"status":     2,
+				"updated_at": time.Now().Format(time.RFC3339),
+			}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove template"})
+			return
+		}
+
+		c.JSON(http.StatusOK, result.ModifiedCount)
+	}
+}
+
+// renderResultReport executes the template chosen by ?template_id=, or the built-in default, against a result.
+// It returns false when a response has already been written.
+func renderResultReport(c *gin.Context, ctx context.Context) (*resultReport, string, bool) {
+	report, ok := loadResultReport(c, ctx)
+	if !ok {
+		return nil, "", false
+	}
+
+	var reportTemplate *models.ReportTemplate
+	if templateId := c.Query("template_id"); templateId != "" {
+		if reportTemplate, ok = findReportTemplate(c, ctx, templateId); !ok {
+			return nil, "", false
+		}
+	} else {
+		var builtin models.ReportTemplate
+		if err := reportTemplateCollection.FindOne(ctx, bson.M{"key": defaultReportTemplateKey}).Decode(&builtin); err != nil {
+			body := defaultReportTemplate
+			builtin.Body = &body
+		}
+		reportTemplate = &builtin
+	}
+
+	renderCtx, cancel := context.WithTimeout(c.Request.Context(), reportRenderTimeout)
+	defer cancel()
+
+	parsed, err := parseReportTemplate(textValue(reportTemplate.Body), &reportBudget{ctx: renderCtx})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "template_error", "detail": err.Error()})
+		return nil, "", false
+	}
+
+	out := &reportWriter{ctx: renderCtx, limit: reportOutputLimit}
+	done := make(chan error, 1)
+	go func() {
+		done <- parsed.Execute(out, report)
+	}()
+
+	select {
+	case err = <-done:
+	case <-renderCtx.Done():
+		err = renderCtx.Err()
+	}
+	if err != nil {
+		if renderCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("report took longer than %s to render", reportRenderTimeout)
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "template_error", "detail": err.Error()})
+		return nil, "", false
+	}
+
+	return report, out.String(), true
+}
+
+// reportWriter buffers template output, failing once it outgrows limit or its context ends.
+type reportWriter struct {
+	ctx   context.Context
+	limit int
+	buf   bytes.Buffer
+}
+
+func (w *reportWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if w.buf.Len()+len(p) > w.limit {
+		return 0, errReportTooLarge
+	}
+
+	return w.buf.Write(p)
+}
+
+func (w *reportWriter) String() string {
+	return w.buf.String()
+}
+
+func GetResultMarkdown() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		report, markdown, ok := renderResultReport(c, ctx)
+		if !ok {
+			return
+		}
+
+		reportAttachment(c, report, "md")
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(markdown))
+	}
+}
+
+func GetResultDOCX() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		report, markdown, ok := renderResultReport(c, ctx)
+		if !ok {
+			return
+		}
+
+		_, color := reportBrand()
+		document, err := helper.MarkdownToDOCX(report.Title(), markdown, color)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to build document: %v", err)})
+			return
+		}
+
+		reportAttachment(c, report, "docx")
+		c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", document)
+	}
+}
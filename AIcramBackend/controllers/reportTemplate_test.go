@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseReportTemplateRejectsNumberRange(t *testing.T) {
+	for _, body := range []string{
+		"{{range 1000000000}}{{end}}",
+		"{{range $i := 1000000000}}{{end}}",
+	} {
+		if _, err := parseReportTemplate(body, &reportBudget{ctx: context.Background()}); err == nil {
+			t.Errorf("%s: parsed, want an error", body)
+		}
+	}
+}
+
+func TestReportBudgetStopsSilentLoops(t *testing.T) {
+	for _, body := range []string{
+		"{{$n := 1000000000}}{{range $n}}{{end}}",
+		"{{range .Organization.Employees}}{{end}}",
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), reportRenderTimeout)
+		parsed, err := parseReportTemplate(body, &reportBudget{ctx: ctx})
+		if err != nil {
+			cancel()
+			t.Fatalf("%s: %v", body, err)
+		}
+
+		report := fixtureReport()
+		employees := 1000000000
+		report.Organization.Employees = &employees
+
+		start := time.Now()
+		err = parsed.Execute(&reportWriter{ctx: ctx, limit: reportOutputLimit}, report)
+		cancel()
+		if err == nil {
+			t.Errorf("%s: executed, want the step limit error", body)
+		}
+		if elapsed := time.Since(start); elapsed > reportRenderTimeout/2 {
+			t.Errorf("%s: took %s", body, elapsed)
+		}
+	}
+}
+
+func TestReportBudgetAllowsDefaultTemplate(t *testing.T) {
+	parsed, err := parseReportTemplate(defaultReportTemplate, &reportBudget{ctx: context.Background()})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	out := &reportWriter{ctx: context.Background(), limit: reportOutputLimit}
+	if err := parsed.Execute(out, fixtureReport()); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if out.String() == "" {
+		t.Error("default template rendered nothing")
+	}
+}
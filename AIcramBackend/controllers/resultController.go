@@ -145,7 +145,16 @@ func GetResult() gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(http.StatusOK, result)
+		attackItems, err := resultAttackItems(ctx, result.Content)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing attack items"})
+			return
+		}
+
+		c.JSON(http.StatusOK, struct {
+			models.Result
+			Attack_items []gin.H `json:"attack_items"`
+		}{result, attackItems})
 	}
 }
 
@@ -197,6 +206,35 @@ func CreateResult() gin.HandlerFunc {
 			result.User_id = &userID
 		}
 
+		if !checkVulnerabilityAssets(ctx, result.Content, *result.User_id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "asset_error"})
+			return
+		}
+
+		if invalid, err := normalizeResultControls(ctx, result.Content); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while validating controls"})
+			return
+		} else if len(invalid) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "control_error", "detail": invalid})
+			return
+		}
+
+		if ok, err := mapControlRequirements(ctx, result.Content); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while mapping requirements"})
+			return
+		} else if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "requirement_error"})
+			return
+		}
+
+		if malformed := malformedAttackIds(result.Content); len(malformed) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mitre_error", "detail": malformed})
+			return
+		}
+
+		var err error
+		result.Matrix_id = nil
+		result.Matrix_version = nil
 		if result.Assessment_id != nil && *result.Assessment_id != "" {
 			var assessment models.Assessment
 			errAssessment := assessmentCollection.FindOne(context.TODO(), bson.M{"assessment_id": result.Assessment_id}).Decode(&assessment)
@@ -209,6 +247,11 @@ func CreateResult() gin.HandlerFunc {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "assessment_error"})
 				return
 			}
+			result.Matrix_id, result.Matrix_version, err = pinResultMatrix(ctx, assessment)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "matrix_error"})
+				return
+			}
 		}
 
 		if result.Status == nil {
@@ -216,11 +259,21 @@ func CreateResult() gin.HandlerFunc {
 			result.Status = &status
 		}
 
+		if result.Content != nil {
+			result.Content.Component, err = resultComponents(ctx, result.Assessment_id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while matching components"})
+				return
+			}
+		}
+
 		result.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		result.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		result.ID = primitive.NewObjectID()
 		result.Result_id = result.ID.Hex()
 
+		saveQuantifiedResult(ctx, &result)
+
 		_, insertErr := resultCollection.InsertOne(ctx, result)
 		if insertErr != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create result"})
@@ -285,6 +338,39 @@ func UpdateResult() gin.HandlerFunc {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "assessment_error"})
 				return
 			}
+			if existingResult.Assessment_id == nil || *existingResult.Assessment_id != assessment.Assessment_id {
+				updateData.Matrix_id, updateData.Matrix_version, err = pinResultMatrix(ctx, assessment)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "matrix_error"})
+					return
+				}
+			}
+		}
+
+		if updateData.Content != nil && !checkVulnerabilityAssets(ctx, updateData.Content, *existingResult.User_id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "asset_error"})
+			return
+		}
+
+		if invalid, err := normalizeResultControls(ctx, updateData.Content); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while validating controls"})
+			return
+		} else if len(invalid) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "control_error", "detail": invalid})
+			return
+		}
+
+		if ok, err := mapControlRequirements(ctx, updateData.Content); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while mapping requirements"})
+			return
+		} else if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "requirement_error"})
+			return
+		}
+
+		if malformed := malformedAttackIds(updateData.Content); len(malformed) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mitre_error", "detail": malformed})
+			return
 		}
 
 		update := bson.M{}
@@ -299,11 +385,40 @@ func UpdateResult() gin.HandlerFunc {
 		}
 		if updateData.Assessment_id != nil {
 			update["assessment_id"] = updateData.Assessment_id
+			if existingResult.Assessment_id == nil || *existingResult.Assessment_id != *updateData.Assessment_id {
+				update["matrix_id"] = updateData.Matrix_id
+				update["matrix_version"] = updateData.Matrix_version
+			}
 		}
 		if updateData.Content != nil {
 			update["content"] = updateData.Content
 		}
 
+		if updateData.Content != nil || updateData.Assessment_id != nil {
+			quantified := existingResult
+			if updateData.Assessment_id != nil {
+				quantified.Assessment_id = updateData.Assessment_id
+				if _, ok := update["matrix_id"]; ok {
+					quantified.Matrix_id = updateData.Matrix_id
+					quantified.Matrix_version = updateData.Matrix_version
+				}
+			}
+			if updateData.Content != nil {
+				quantified.Content = updateData.Content
+			}
+			if quantified.Content != nil {
+				quantified.Content.Component, err = resultComponents(ctx, quantified.Assessment_id)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while matching components"})
+					return
+				}
+			}
+			saveQuantifiedResult(ctx, &quantified)
+			if quantified.Content != nil {
+				update["content"] = quantified.Content
+			}
+		}
+
 		update["updated_at"] = time.Now().Format(time.RFC3339)
 
 		result, err := resultCollection.UpdateOne(
@@ -409,7 +524,7 @@ func RemoveResult() gin.HandlerFunc {
 
 func CreateContent() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var _, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
 		body, err := c.GetRawData()
@@ -418,12 +533,93 @@ func CreateContent() gin.HandlerFunc {
 			return
 		}
 
+		// Only a JSON object can carry assessment and organization ids; any other body is passed on as received.
+		var payload map[string]interface{}
+		if json.Unmarshal(body, &payload) != nil {
+			payload = nil
+		}
+
+		components := []*models.Component{}
+		documentCount := 0
+		if payload != nil {
+			// Documents are the files attached to the assessment, never ids taken from the request.
+			var fileIds []string
+			assessmentId, _ := payload["assessment_id"].(string)
+			delete(payload, "assessment_id")
+			delete(payload, "file")
+			if assessmentId != "" {
+				var assessment models.Assessment
+				err := assessmentCollection.FindOne(ctx, bson.M{"assessment_id": assessmentId}).Decode(&assessment)
+				if err != nil {
+					if err == mongo.ErrNoDocuments {
+						c.JSON(http.StatusNotFound, gin.H{"error": "assessment not found"})
+						return
+					}
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching assessment"})
+					return
+				}
+				if c.GetString("user_type") != "ADMIN" && (assessment.User_id == nil || *assessment.User_id != c.GetString("uid")) {
+					c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to analyze this assessment"})
+					return
+				}
+				fileIds = stringValues(assessment.File)
+			}
+
+			// Components of the organization's assets with known CVEs are matched here rather than left to the AI.
+			organizationId, _ := payload["organization_id"].(string)
+			var assetIds []string
+			if ids, ok := payload["asset_id"].([]interface{}); ok {
+				for _, id := range ids {
+					if assetId, ok := id.(string); ok && assetId != "" {
+						assetIds = append(assetIds, assetId)
+					}
+				}
+			}
+			delete(payload, "organization_id")
+			delete(payload, "asset_id")
+
+			if organizationId != "" {
+				if _, ok := assetOrganization(ctx, &organizationId, c.GetString("uid"), c.GetString("user_type")); !ok {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "organization_error"})
+					return
+				}
+				components, err = organizationComponents(ctx, organizationId, assetIds)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while matching components"})
+					return
+				}
+				payload["known_vulnerability"] = components
+			}
+
+			if len(fileIds) > 0 {
+				documents, err := loadFileTexts(ctx, fileIds, c.GetString("uid"), c.GetString("user_type"))
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while loading documents"})
+					return
+				}
+				payload["document"] = documents
+				documentCount = len(documents)
+			}
+		}
+
+		// Document text can run to hundreds of thousands of characters, so only its count is logged.
+		var logged interface{} = json.RawMessage(body)
+		if payload != nil {
+			trimmed := map[string]interface{}{}
+			for key, value := range payload {
+				if key != "document" {
+					trimmed[key] = value
+				}
+			}
+			logged = trimmed
+		}
+
 		var prettyJSON []byte
-		prettyJSON, err = json.MarshalIndent(json.RawMessage(body), "", "  ")
+		prettyJSON, err = json.MarshalIndent(logged, "", "  ")
 		if err != nil {
 			log.Printf("Error formatting JSON: %s", err)
 		} else {
-			log.Printf("\n\nData input:\n\n%s\n\n", string(prettyJSON))
+			log.Printf("\n\nData input (%d documents):\n\n%s\n\n", documentCount, string(prettyJSON))
 		}
 
 		time.Sleep(10 * time.Second)
@@ -433,6 +629,7 @@ func CreateContent() gin.HandlerFunc {
 			"vulnerability": []string{},
 			"summary":       "",
 			"message":       "No response from AI",
+			"component":     components,
 		}
 
 		c.JSON(http.StatusOK, response)
@@ -0,0 +1,454 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/database"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var riskSnapshotCollection *mongo.Collection = database.OpenCollection(database.Client, "risk_snapshot")
+
+const snapshotDateLayout = "2006-01-02"
+const topRiskCount = 5
+const maxTrendBuckets = 1000
+
+func EnsureRiskSnapshotIndex() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	unique := true
+	_, err := riskSnapshotCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "assessment_id", Value: 1}, {Key: "date", Value: 1}}, Options: &options.IndexOptions{Unique: &unique}},
+		{Keys: bson.D{{Key: "assessment_id", Value: 1}, {Key: "date", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("Failed to create risk snapshot index: %v", err)
+	}
+}
+
+// riskPosition places impact × likelihood on the matrix as a share of its largest product, counting levels by
+// position so that matrices of any size and scale compare. It is false when either value is not a level.
+func riskPosition(matrix *models.Matrix, impact *int, likelihood *int) (float64, bool) {
+	if matrix == nil || len(matrix.Impact) == 0 || len(matrix.Likelihood) == 0 {
+		return 0, false
+	}
+	column, row := levelIndex(matrix.Impact, impact), levelIndex(matrix.Likelihood, likelihood)
+	if column < 0 || row < 0 {
+		return 0, false
+	}
+
+	return float64((column+1)*(row+1)) / float64(len(matrix.Impact)*len(matrix.Likelihood)), true
+}
+
+// riskLevel bands a score by its position in the matrix: the product of the impact and likelihood ranks divided
+// by the largest product. On a 5x5 matrix this gives 1-4 low, 5-9 medium, 10-15 high and 16-25 critical.
+func riskLevel(matrix *models.Matrix, impact *int, likelihood *int) string {
+	position, ok := riskPosition(matrix, impact, likelihood)
+	if !ok {
+		return "unrated"
+	}
+
+	switch {
+	case position < 0.2:
+		return "low"
+	case position < 0.4:
+		return "medium"
+	case position < 0.64:
+		return "high"
+	default:
+		return "critical"
+	}
+}
+
+func countLevel(counts *models.RiskLevelCount, level string) {
+	switch level {
+	case "low":
+		counts.Low++
+	case "medium":
+		counts.Medium++
+	case "high":
+		counts.High++
+	case "critical":
+		counts.Critical++
+	default:
+		counts.Unrated++
+	}
+}
+
+// resultMatrices resolves the matrix version each result was scored with.
+func resultMatrices(ctx context.Context, results []models.Result) map[string]*models.Matrix {
+	matrices := map[string]*models.Matrix{}
+	for _, result := range results {
+		if matrixVersion, _, _, err := resultMatrixVersion(ctx, result); err == nil && matrixVersion != nil {
+			matrices[result.Result_id] = matrixVersion.Matrix
+		}
+	}
+
+	return matrices
+}
+
+// positionScore is riskPosition as a whole percentage, or nil when the values are not levels of the matrix.
+func positionScore(matrix *models.Matrix, impact *int, likelihood *int) *int {
+	position, ok := riskPosition(matrix, impact, likelihood)
+	if !ok {
+		return nil
+	}
+	score := int(math.Round(position * 100))
+
+	return &score
+}
+
+// riskSnapshot aggregates the vulnerabilities of the results. Each vulnerability scores its position on the matrix
+// its result was scored with (0-100, see riskPosition), so that results on matrices of different sizes and scales
+// add up; residual falls back to inherent for untreated ones, as in the result comparison. Top risks are the
+// vulnerabilities with the highest residual risk.
+func riskSnapshot(results []models.Result, matrices map[string]*models.Matrix) models.RiskSnapshot {
+	snapshot := models.RiskSnapshot{
+		Result_id:      []*string{},
+		Inherent_level: &models.RiskLevelCount{},
+		Residual_level: &models.RiskLevelCount{},
+		Top_risk:       []*models.TopRisk{},
+	}
+	for i := range results {
+		result := &results[i]
+		snapshot.Result_id = append(snapshot.Result_id, &result.Result_id)
+		if result.Content == nil {
+			continue
+		}
+		matrix := matrices[result.Result_id]
+		for _, vulnerability := range result.Content.Vulnerability {
+			if vulnerability == nil {
+				continue
+			}
+			inherent := positionScore(matrix, vulnerability.Impact, vulnerability.Likelihood)
+			inherentLevel := riskLevel(matrix, vulnerability.Impact, vulnerability.Likelihood)
+			residual, residualLevel := inherent, inherentLevel
+			if vulnerability.New_impact != nil && vulnerability.New_likelihood != nil {
+				residual = positionScore(matrix, vulnerability.New_impact, vulnerability.New_likelihood)
+				residualLevel = riskLevel(matrix, vulnerability.New_impact, vulnerability.New_likelihood)
+			}
+
+			snapshot.Vulnerability_count++
+			if inherent != nil {
+				snapshot.Inherent_score += *inherent
+			}
+			if residual != nil {
+				snapshot.Residual_score += *residual
+			}
+			countLevel(snapshot.Inherent_level, inherentLevel)
+			countLevel(snapshot.Residual_level, residualLevel)
+			snapshot.Top_risk = append(snapshot.Top_risk, &models.TopRisk{
+				Result_id:      result.Result_id,
+				Assessment_id:  result.Assessment_id,
+				Name:           vulnerability.Name,
+				Inherent_score: inherent,
+				Residual_score: residual,
+				Inherent_level: inherentLevel,
+				Residual_level: residualLevel,
+			})
+		}
+	}
+
+	score := func(value *int) int {
+		if value == nil {
+			return -1
+		}
+		return *value
+	}
+	sort.SliceStable(snapshot.Top_risk, func(i, j int) bool {
+		a, b := snapshot.Top_risk[i], snapshot.Top_risk[j]
+		if score(a.Residual_score) != score(b.Residual_score) {
+			return score(a.Residual_score) > score(b.Residual_score)
+		}
+		if score(a.Inherent_score) != score(b.Inherent_score) {
+			return score(a.Inherent_score) > score(b.Inherent_score)
+		}
+		if textValue(a.Name) != textValue(b.Name) {
+			return textValue(a.Name) < textValue(b.Name)
+		}
+		return a.Result_id < b.Result_id
+	})
+	if len(snapshot.Top_risk) > topRiskCount {
+		snapshot.Top_risk = snapshot.Top_risk[:topRiskCount]
+	}
+
+	return snapshot
+}
+
+func saveRiskSnapshot(ctx context.Context, snapshot models.RiskSnapshot, now time.Time) error {
+	_, err := riskSnapshotCollection.UpdateOne(
+		ctx,
+		bson.M{"organization_id": snapshot.Organization_id, "assessment_id": snapshot.Assessment_id, "date": snapshot.Date},
+		bson.M{
+			"$set": bson.M{
+				"result_id":           snapshot.Result_id,
+				"vulnerability_count": snapshot.Vulnerability_count,
+				"inherent_score":      snapshot.Inherent_score,
+				"residual_score":      snapshot.Residual_score,
+				"inherent_level":      snapshot.Inherent_level,
+				"residual_level":      snapshot.Residual_level,
+				"top_risk":            snapshot.Top_risk,
+				"updated_at":          now,
+			},
+			"$setOnInsert": bson.M{"_id": snapshot.ID, "snapshot_id": snapshot.ID.Hex(), "created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+// SnapshotRisk records today's risk of every active organization from the latest active result of each of its
+// active assessments, plus one snapshot per assessment. Running it again on the same day replaces that day's
+// snapshots, so the stored value of a past day is its state when the day ended.
+func SnapshotRisk() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	date := now.UTC().Format(snapshotDateLayout)
+
+	cursor, err := organizationCollection.Find(ctx, bson.M{"status": 1}, options.Find().SetProjection(bson.M{"organization_id": 1}))
+	if err != nil {
+		log.Printf("Failed to list organizations for risk snapshots: %v", err)
+		return
+	}
+	var organizations []models.Organization
+	if err = cursor.All(ctx, &organizations); err != nil {
+		log.Printf("Failed to list organizations for risk snapshots: %v", err)
+		return
+	}
+
+	for _, organization := range organizations {
+		snapshotOrganizationRisk(organization.Organization_id, date, now)
+	}
+}
+
+// snapshotOrganizationRisk saves the snapshots of one organization under its own deadline, so that a slow
+// organization does not leave the rest without snapshots.
+func snapshotOrganizationRisk(organizationId string, date string, now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	results, err := latestOrganizationResults(ctx, organizationId)
+	if err != nil {
+		log.Printf("Failed to load results of organization %s: %v", organizationId, err)
+		return
+	}
+	matrices := resultMatrices(ctx, results)
+
+	snapshots := []models.RiskSnapshot{riskSnapshot(results, matrices)}
+	for _, result := range results {
+		snapshot := riskSnapshot([]models.Result{result}, matrices)
+		snapshot.Assessment_id = result.Assessment_id
+		snapshots = append(snapshots, snapshot)
+	}
+	for _, snapshot := range snapshots {
+		snapshot.ID = primitive.NewObjectID()
+		snapshot.Organization_id = organizationId
+		snapshot.Date = date
+		if err := saveRiskSnapshot(ctx, snapshot, now); err != nil {
+			log.Printf("Failed to save risk snapshot of organization %s: %v", organizationId, err)
+		}
+	}
+}
+
+func StartRiskSnapshots(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			SnapshotRisk()
+			<-ticker.C
+		}
+	}()
+}
+
+// bucketStart returns the first day of the bucket holding day: the day itself, the Monday of its week or the
+// first of its month.
+func bucketStart(day time.Time, bucket string) time.Time {
+	switch bucket {
+	case "week":
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "month":
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func nextBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// riskTrend answers ?from=&to= (YYYY-MM-DD, the last 30 days by default) and ?bucket=day|week|month. Each bucket
+// reports the last snapshot taken within it; buckets without snapshots have no values.
+func riskTrend(c *gin.Context, ctx context.Context, filter bson.M) (gin.H, bool) {
+	bucket := c.DefaultQuery("bucket", "day")
+	if bucket != "day" && bucket != "week" && bucket != "month" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bucket must be day, week or month"})
+		return nil, false
+	}
+
+	today, _ := time.Parse(snapshotDateLayout, time.Now().UTC().Format(snapshotDateLayout))
+	to, from := today, today.AddDate(0, 0, -29)
+	var err error
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse(snapshotDateLayout, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date (YYYY-MM-DD)"})
+			return nil, false
+		}
+		from = to.AddDate(0, 0, -29)
+	}
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse(snapshotDateLayout, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date (YYYY-MM-DD)"})
+			return nil, false
+		}
+	}
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return nil, false
+	}
+
+	starts := []time.Time{}
+	for start := bucketStart(from, bucket); !start.After(to); start = nextBucket(start, bucket) {
+		starts = append(starts, start)
+		if len(starts) > maxTrendBuckets {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date range has more than " + strconv.Itoa(maxTrendBuckets) + " buckets"})
+			return nil, false
+		}
+	}
+
+	filter["date"] = bson.M{"$gte": from.Format(snapshotDateLayout), "$lte": to.Format(snapshotDateLayout)}
+	cursor, err := riskSnapshotCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "date", Value: 1}}))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing risk snapshots"})
+		return nil, false
+	}
+	var snapshots []models.RiskSnapshot
+	if err = cursor.All(ctx, &snapshots); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing risk snapshots"})
+		return nil, false
+	}
+
+	latest := map[string]models.RiskSnapshot{}
+	for _, snapshot := range snapshots {
+		day, err := time.Parse(snapshotDateLayout, snapshot.Date)
+		if err != nil {
+			continue
+		}
+		latest[bucketStart(day, bucket).Format(snapshotDateLayout)] = snapshot
+	}
+
+	series := []gin.H{}
+	for _, start := range starts {
+		end := nextBucket(start, bucket).AddDate(0, 0, -1)
+		point := gin.H{
+			"start":               start.Format(snapshotDateLayout),
+			"end":                 end.Format(snapshotDateLayout),
+			"date":                nil,
+			"vulnerability_count": nil,
+			"inherent_score":      nil,
+			"residual_score":      nil,
+			"inherent_level":      nil,
+			"residual_level":      nil,
+			"top_risk":            nil,
+		}
+		if snapshot, ok := latest[start.Format(snapshotDateLayout)]; ok {
+			point["date"] = snapshot.Date
+			point["vulnerability_count"] = snapshot.Vulnerability_count
+			point["inherent_score"] = snapshot.Inherent_score
+			point["residual_score"] = snapshot.Residual_score
+			point["inherent_level"] = snapshot.Inherent_level
+			point["residual_level"] = snapshot.Residual_level
+			point["top_risk"] = snapshot.Top_risk
+		}
+		series = append(series, point)
+	}
+
+	return gin.H{
+		"from":   from.Format(snapshotDateLayout),
+		"to":     to.Format(snapshotDateLayout),
+		"bucket": bucket,
+		"series": series,
+	}, true
+}
+
+func GetOrganizationRiskTrend() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organizationId := c.Param("organization_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		if _, ok := assetOrganization(ctx, &organizationId, c.GetString("uid"), c.GetString("user_type")); !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this organization"})
+			return
+		}
+
+		trend, ok := riskTrend(c, ctx, bson.M{"organization_id": organizationId, "assessment_id": nil})
+		if !ok {
+			return
+		}
+		trend["organization_id"] = organizationId
+
+		c.JSON(http.StatusOK, trend)
+	}
+}
+
+func GetAssessmentRiskTrend() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		assessmentId := c.Param("assessment_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var assessment models.Assessment
+		err := assessmentCollection.FindOne(ctx, bson.M{"assessment_id": assessmentId}).Decode(&assessment)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "assessment not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching assessment"})
+			return
+		}
+
+		if c.GetString("user_type") != "ADMIN" {
+			if assessment.User_id == nil || *assessment.User_id != c.GetString("uid") || (assessment.Status != nil && *assessment.Status != 1) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to view this assessment"})
+				return
+			}
+		}
+
+		trend, ok := riskTrend(c, ctx, bson.M{"assessment_id": assessmentId})
+		if !ok {
+			return
+		}
+		trend["organization_id"] = assessment.Organization_id
+		trend["assessment_id"] = assessmentId
+
+		c.JSON(http.StatusOK, trend)
+	}
+}
@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	helper "user-athentication-golang/helpers"
+)
+
+const stixTimestamp = "2006-01-02T15:04:05.000Z"
+
+var mitreTechniqueCode = regexp.MustCompile(`\bT\d{4}(?:\.\d{3})?\b`)
+var stixTypePattern = regexp.MustCompile(`^[a-z0-9-]{3,250}$`)
+
+type stixExternalReference struct {
+	Source_name string `json:"source_name"`
+	External_id string `json:"external_id,omitempty"`
+	Url         string `json:"url,omitempty"`
+}
+
+// stixObject covers the STIX 2.1 object types the export produces; custom properties carry the x_aicram_ prefix.
+type stixObject struct {
+	Type                string                  `json:"type"`
+	Spec_version        string                  `json:"spec_version"`
+	Id                  string                  `json:"id"`
+	Created             string                  `json:"created"`
+	Modified            string                  `json:"modified"`
+	Name                string                  `json:"name,omitempty"`
+	Description         string                  `json:"description,omitempty"`
+	Report_types        []string                `json:"report_types,omitempty"`
+	Published           string                  `json:"published,omitempty"`
+	Object_refs         []string                `json:"object_refs,omitempty"`
+	Relationship_type   string                  `json:"relationship_type,omitempty"`
+	Source_ref          string                  `json:"source_ref,omitempty"`
+	Target_ref          string                  `json:"target_ref,omitempty"`
+	External_references []stixExternalReference `json:"external_references,omitempty"`
+	Impact              *int                    `json:"x_aicram_impact,omitempty"`
+	Likelihood          *int                    `json:"x_aicram_likelihood,omitempty"`
+	Residual_impact     *int                    `json:"x_aicram_residual_impact,omitempty"`
+	Residual_likelihood *int                    `json:"x_aicram_residual_likelihood,omitempty"`
+	Loss_mean           *float64                `json:"x_aicram_loss_mean,omitempty"`
+	Residual_loss_mean  *float64                `json:"x_aicram_residual_loss_mean,omitempty"`
+	Asset_id            []string                `json:"x_aicram_asset_id,omitempty"`
+}
+
+type stixBundle struct {
+	Type    string        `json:"type"`
+	Id      string        `json:"id"`
+	Objects []*stixObject `json:"objects"`
+}
+
+func stixId(kind string, name string) string {
+	return kind + "--" + helper.NameUUID(helper.STIXNamespace, name)
+}
+
+func stixTime(value time.Time) string {
+	return value.UTC().Format(stixTimestamp)
+}
+
+// mitreTechniques splits MITRE references such as "T1190 Exploit Public-Facing Application" into technique
+// IDs and the names written after them; tactics and other non-technique references are left out.
+func mitreTechniques(values []*string) ([]string, map[string]string) {
+	codes := []string{}
+	names := map[string]string{}
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		text := strings.ToUpper(*value)
+		matches := mitreTechniqueCode.FindAllStringIndex(text, -1)
+		for _, match := range matches {
+			code := text[match[0]:match[1]]
+			if _, ok := names[code]; ok {
+				continue
+			}
+			codes = append(codes, code)
+			names[code] = ""
+			if len(matches) == 1 {
+				names[code] = strings.Trim((*value)[match[1]:], " -:")
+			}
+		}
+	}
+
+	return codes, names
+}
+
+func mitreTechniqueUrl(code string) string {
+	return "https://attack.mitre.org/techniques/" + strings.ReplaceAll(code, ".", "/") + "/"
+}
+
+func stringValues(values []*string) []string {
+	items := []string{}
+	for _, value := range values {
+		if value != nil && strings.TrimSpace(*value) != "" {
+			items = append(items, strings.TrimSpace(*value))
+		}
+	}
+
+	return items
+}
+
+// buildSTIXBundle maps the result to vulnerability, attack-pattern and course-of-action objects linked by
+// "targets" and "mitigates" relationships, all collected under one report. Identifiers are derived from the
+// result so repeated exports produce the same objects; attack patterns depend only on the technique.
+func buildSTIXBundle(report *resultReport) stixBundle {
+	result := report.Result
+	created := stixTime(result.Created_at)
+	modified := created
+	if result.Updated_at.After(result.Created_at) {
+		modified = stixTime(result.Updated_at)
+	}
+
+	bundle := stixBundle{Type: "bundle", Id: stixId("bundle", result.Result_id+"/bundle"), Objects: []*stixObject{}}
+	patterns := map[string]*stixObject{}
+	relationships := []*stixObject{}
+	relate := func(source string, kind string, target string) {
+		relationships = append(relationships, &stixObject{
+			Type: "relationship", Spec_version: "2.1", Id: stixId("relationship", source+"/"+kind+"/"+target),
+			Created: created, Modified: modified, Relationship_type: kind, Source_ref: source, Target_ref: target,
+		})
+	}
+
+	if result.Content != nil {
+		for i, vulnerability := range result.Content.Vulnerability {
+			if vulnerability == nil {
+				continue
+			}
+
+			object := &stixObject{
+				Type: "vulnerability", Spec_version: "2.1", Id: stixId("vulnerability", result.Result_id+"/vulnerability/"+strconv.Itoa(i)),
+				Created: created, Modified: modified, Name: textValue(vulnerability.Name), Description: textValue(vulnerability.Description),
+				Impact: vulnerability.Impact, Likelihood: vulnerability.Likelihood,
+				Residual_impact: vulnerability.New_impact, Residual_likelihood: vulnerability.New_likelihood,
+				Asset_id: stringValues(vulnerability.Asset_id),
+			}
+			if object.Name == "" {
+				object.Name = "Vulnerability " + strconv.Itoa(i+1)
+			}
+			if vulnerability.Loss != nil {
+				object.Loss_mean = vulnerability.Loss.Mean
+			}
+			if vulnerability.New_loss != nil {
+				object.Residual_loss_mean = vulnerability.New_loss.Mean
+			}
+			for _, cve := range stringValues(vulnerability.CVE) {
+				if cve = strings.ToUpper(cve); cvePattern.MatchString(cve) {
+					object.External_references = append(object.External_references, stixExternalReference{
+						Source_name: "cve", External_id: cve, Url: "https://nvd.nist.gov/vuln/detail/" + cve,
+					})
+				}
+			}
+			bundle.Objects = append(bundle.Objects, object)
+
+			codes, names := mitreTechniques(vulnerability.MITRE)
+			for _, code := range codes {
+				pattern, ok := patterns[code]
+				if !ok {
+					pattern = &stixObject{
+						Type: "attack-pattern", Spec_version: "2.1", Id: stixId("attack-pattern", "mitre-attack/"+code),
+						Created: created, Modified: modified, Name: code,
+						External_references: []stixExternalReference{{Source_name: "mitre-attack", External_id: code, Url: mitreTechniqueUrl(code)}},
+					}
+					patterns[code] = pattern
+					bundle.Objects = append(bundle.Objects, pattern)
+				}
+				if pattern.Name == code && names[code] != "" {
+					pattern.Name = names[code]
+				}
+				relate(pattern.Id, "targets", object.Id)
+			}
+
+			for j, control := range vulnerability.Control {
+				if control == nil {
+					continue
+				}
+				action := &stixObject{
+					Type: "course-of-action", Spec_version: "2.1", Id: stixId("course-of-action", result.Result_id+"/control/"+strconv.Itoa(i)+"/"+strconv.Itoa(j)),
+					Created: created, Modified: modified, Name: textValue(control.Name), Description: textValue(control.Description),
+				}
+				if action.Name == "" {
+					action.Name = "Control " + strconv.Itoa(j+1)
+				}
+				nist, iso := controlCodes(control)
+				for _, code := range nist {
+					action.External_references = append(action.External_references, stixExternalReference{Source_name: "NIST SP 800-53", External_id: code})
+				}
+				for _, code := range iso {
+					action.External_references = append(action.External_references, stixExternalReference{Source_name: "ISO/IEC 27001", External_id: code})
+				}
+				bundle.Objects = append(bundle.Objects, action)
+				relate(action.Id, "mitigates", object.Id)
+			}
+		}
+	}
+	bundle.Objects = append(bundle.Objects, relationships...)
+
+	if len(bundle.Objects) > 0 {
+		refs := []string{}
+		for _, object := range bundle.Objects {
+			refs = append(refs, object.Id)
+		}
+		summary := ""
+		if result.Content != nil {
+			summary = textValue(result.Content.Summary)
+		}
+		bundle.Objects = append(bundle.Objects, &stixObject{
+			Type: "report", Spec_version: "2.1", Id: stixId("report", result.Result_id+"/report"),
+			Created: created, Modified: modified, Name: report.Title(), Description: summary,
+			Report_types: []string{"vulnerability"}, Published: created, Object_refs: refs,
+		})
+	}
+
+	return bundle
+}
+
+// stixRelationships lists the relationship types the export may use between each pair of object types.
+var stixRelationships = map[string]string{
+	"attack-pattern/targets/vulnerability":     "targets",
+	"course-of-action/mitigates/vulnerability": "mitigates",
+}
+
+// validateSTIXBundle checks the bundle against the STIX 2.1 rules for the object types it contains: identifier
+// form, required properties, timestamp format and order, and that every reference resolves inside the bundle.
+func validateSTIXBundle(bundle stixBundle) []string {
+	problems := []string{}
+	if bundle.Type != "bundle" {
+		problems = append(problems, "bundle: type must be bundle")
+	}
+	if !strings.HasPrefix(bundle.Id, "bundle--") || !helper.IsUUID(strings.TrimPrefix(bundle.Id, "bundle--")) {
+		problems = append(problems, "bundle: invalid id "+bundle.Id)
+	}
+
+	types := map[string]string{}
+	for _, object := range bundle.Objects {
+		types[object.Id] = object.Type
+	}
+
+	seen := map[string]bool{}
+	for _, object := range bundle.Objects {
+		location := object.Type + " " + object.Id
+		if !stixTypePattern.MatchString(object.Type) || !strings.HasPrefix(object.Id, object.Type+"--") || !helper.IsUUID(strings.TrimPrefix(object.Id, object.Type+"--")) {
+			problems = append(problems, location+": invalid id")
+		}
+		if seen[object.Id] {
+			problems = append(problems, location+": duplicate id")
+		}
+		seen[object.Id] = true
+		if object.Spec_version != "2.1" {
+			problems = append(problems, location+": spec_version must be 2.1")
+		}
+
+		created, createdErr := time.Parse(stixTimestamp, object.Created)
+		modified, modifiedErr := time.Parse(stixTimestamp, object.Modified)
+		if createdErr != nil || modifiedErr != nil {
+			problems = append(problems, location+": created and modified must be UTC timestamps")
+		} else if modified.Before(created) {
+			problems = append(problems, location+": modified is before created")
+		}
+
+		for _, reference := range object.External_references {
+			if reference.Source_name == "" {
+				problems = append(problems, location+": external reference without source_name")
+			}
+			if reference.Source_name == "cve" && !cvePattern.MatchString(reference.External_id) {
+				problems = append(problems, location+": invalid CVE "+reference.External_id)
+			}
+			if reference.Source_name == "mitre-attack" && !mitreTechniqueCode.MatchString(reference.External_id) {
+				problems = append(problems, location+": invalid ATT&CK technique "+reference.External_id)
+			}
+		}
+
+		switch object.Type {
+		case "vulnerability", "attack-pattern", "course-of-action":
+			if strings.TrimSpace(object.Name) == "" {
+				problems = append(problems, location+": name is required")
+			}
+		case "report":
+			if strings.TrimSpace(object.Name) == "" {
+				problems = append(problems, location+": name is required")
+			}
+			if _, err := time.Parse(stixTimestamp, object.Published); err != nil {
+				problems = append(problems, location+": published must be a UTC timestamp")
+			}
+			if len(object.Object_refs) == 0 {
+				problems = append(problems, location+": object_refs is required")
+			}
+			for _, ref := range object.Object_refs {
+				if _, ok := types[ref]; !ok {
+					problems = append(problems, location+": object_refs entry "+ref+" is not in the bundle")
+				}
+			}
+		case "relationship":
+			pair := types[object.Source_ref] + "/" + object.Relationship_type + "/" + types[object.Target_ref]
+			if _, ok := stixRelationships[pair]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: %s %s %s is not an allowed relationship", location, object.Source_ref, object.Relationship_type, object.Target_ref))
+			}
+		default:
+			problems = append(problems, location+": unexpected object type")
+		}
+	}
+
+	return problems
+}
+
+func GetResultSTIX() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		report, ok := loadResultReport(c, ctx)
+		if !ok {
+			return
+		}
+
+		bundle := buildSTIXBundle(report)
+		if problems := validateSTIXBundle(bundle); len(problems) > 0 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "export_error", "detail": "generated STIX bundle is invalid", "errors": problems})
+			return
+		}
+
+		c.Header("Content-Disposition", "attachment; filename=\"result-"+report.Result.Result_id+".stix.json\"")
+		c.Header("Content-Type", "application/stix+json;version=2.1")
+		c.JSON(http.StatusOK, bundle)
+	}
+}
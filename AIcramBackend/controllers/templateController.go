@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type matrixTemplate struct {
+	Key          string
+	Name         string
+	Description  string
+	Quantitative bool
+	Impact       []*models.Level
+	Likelihood   []*models.Level
+}
+
+func templateLevel(value int, label string, description string) *models.Level {
+	return &models.Level{Value: &value, Label: &label, Description: &description}
+}
+
+// rangeLevel is a level of a quantitative template: a loss fraction for impact or an annual frequency for likelihood.
+func rangeLevel(value int, label string, description string, min float64, max float64) *models.Level {
+	level := templateLevel(value, label, description)
+	level.Min = &min
+	level.Max = &max
+
+	return level
+}
+
+// The smaller templates keep the level values they were first seeded with, so results scored on them stay valid.
+var matrixTemplates = []matrixTemplate{
+	{
+		Key:         "nist-sp-800-30",
+		Name:        "NIST SP 800-30 Qualitative (5x5)",
+		Description: "Qualitative impact and likelihood scales adapted from NIST SP 800-30 Rev. 1, Appendices G and H.",
+		Impact: []*models.Level{
+			templateLevel(1, "Very Low", "the threat event could be expected to have a negligible adverse effect on organizational operations, assets, individuals, other organizations, or the Nation."),
+			templateLevel(2, "Low", "the threat event could be expected to have a limited adverse effect, such as degraded mission capability with noticeably reduced effectiveness, minor damage to assets, minor financial loss, or minor harm to individuals."),
+			templateLevel(3, "Moderate", "the threat event could be expected to have a serious adverse effect, such as significant degradation of mission capability, significant damage to assets, significant financial loss, or significant harm to individuals that does not involve loss of life."),
+			templateLevel(4, "High", "the threat event could be expected to have a severe or catastrophic adverse effect, such as severe degradation or loss of mission capability, major damage to assets, major financial loss, or severe harm to individuals."),
+			templateLevel(5, "Very High", "the threat event could be expected to have multiple severe or catastrophic adverse effects on organizational operations, assets, individuals, other organizations, or the Nation."),
+		},
+		Likelihood: []*models.Level{
+			templateLevel(1, "Very Low", "adversary is highly unlikely to initiate the threat event, or a non-adversarial error, accident, or act of nature is highly unlikely to occur (less than once every 10 years)."),
+			templateLevel(2, "Low", "adversary is unlikely to initiate the threat event, or the non-adversarial event is unlikely to occur (more than once every 10 years but less than once a year)."),
+			templateLevel(3, "Moderate", "adversary is somewhat likely to initiate the threat event, or the non-adversarial event is somewhat likely to occur (between 1 and 10 times a year)."),
+			templateLevel(4, "High", "adversary is highly likely to initiate the threat event, or the non-adversarial event is highly likely to occur (between 10 and 100 times a year)."),
+			templateLevel(5, "Very High", "adversary is almost certain to initiate the threat event, or the non-adversarial event is almost certain to occur (more than 100 times a year)."),
+		},
+	},
+	{
+		Key:         "iso-27005",
+		Name:        "ISO/IEC 27005 Style (5x5)",
+		Description: "Five-level consequence and likelihood scales in the style of ISO/IEC 27005 information security risk assessment.",
+		Impact: []*models.Level{
+			templateLevel(1, "Insignificant", "no noticeable effect on confidentiality, integrity or availability; no regulatory or reputational consequence."),
+			templateLevel(2, "Minor", "limited disruption of a non-critical service or exposure of internal information; handled within normal operations."),
+			templateLevel(3, "Moderate", "disruption of a business process for up to a day, exposure of confidential data to a limited audience, or a reportable compliance finding."),
+			templateLevel(4, "Major", "prolonged outage of a critical service, breach of personal or customer data, regulatory penalties or lasting reputational damage."),
+			templateLevel(5, "Catastrophic", "loss of critical assets or data at scale, threat to business continuity, severe legal or regulatory sanctions."),
+		},
+		Likelihood: []*models.Level{
+			templateLevel(1, "Rare", "may occur only in exceptional circumstances; no known history of occurrence."),
+			templateLevel(2, "Unlikely", "could occur at some time; vulnerability is difficult to exploit and requires significant resources."),
+			templateLevel(3, "Possible", "might occur at some time; vulnerability is known and exploitable with moderate effort."),
+			templateLevel(4, "Likely", "will probably occur in most circumstances; vulnerability is easy to exploit and threat sources are motivated."),
+			templateLevel(5, "Almost Certain", "expected to occur in most circumstances; active exploitation is observed or occurrence is frequent."),
+		},
+	},
+	{
+		Key:          "fair-quantitative",
+		Name:         "FAIR Quantitative (5x5)",
+		Description:  "Quantitative scale in the style of FAIR: likelihood maps to annualized event frequency and impact to the fraction of exposed asset value lost per event.",
+		Quantitative: true,
+		Impact: []*models.Level{
+			rangeLevel(1, "Very Low", "less than 0.1% of exposed asset value lost per event.", 0, 0.001),
+			rangeLevel(2, "Low", "0.1% to 1% of exposed asset value lost per event.", 0.001, 0.01),
+			rangeLevel(3, "Moderate", "1% to 5% of exposed asset value lost per event.", 0.01, 0.05),
+			rangeLevel(4, "High", "5% to 20% of exposed asset value lost per event.", 0.05, 0.2),
+			rangeLevel(5, "Very High", "20% to 100% of exposed asset value lost per event.", 0.2, 1),
+		},
+		Likelihood: []*models.Level{
+			rangeLevel(1, "Very Low", "less than once every 10 years.", 0, 0.1),
+			rangeLevel(2, "Low", "once every 10 years to once a year.", 0.1, 1),
+			rangeLevel(3, "Moderate", "1 to 10 times a year.", 1, 10),
+			rangeLevel(4, "High", "10 to 100 times a year.", 10, 100),
+			rangeLevel(5, "Very High", "more than 100 times a year.", 100, 365),
+		},
+	},
+	{
+		Key:         "simple-4x4",
+		Name:        "Simple Risk Matrix (4x4)",
+		Description: "A four-level matrix for teams that need more granularity than 3x3 without a neutral midpoint bias.",
+		Impact: []*models.Level{
+			templateLevel(2, "Low", "minimal effect on operations, data or customers."),
+			templateLevel(3, "Medium", "noticeable disruption or limited data exposure that can be recovered quickly."),
+			templateLevel(4, "High", "significant disruption, data breach or financial loss requiring escalation."),
+			templateLevel(5, "Extreme", "critical business impact, major breach or regulatory action."),
+		},
+		Likelihood: []*models.Level{
+			templateLevel(2, "Low", "not expected to occur within the next few years."),
+			templateLevel(3, "Medium", "could occur within the next year."),
+			templateLevel(4, "High", "likely to occur within the next few months."),
+			templateLevel(5, "Extreme", "expected to occur imminently or already occurring."),
+		},
+	},
+	{
+		Key:         "simple-3x3",
+		Name:        "Simple Risk Matrix (3x3)",
+		Description: "A lightweight three-level matrix for quick assessments and small organizations.",
+		Impact: []*models.Level{
+			templateLevel(2, "Low", "minor inconvenience with little or no lasting effect."),
+			templateLevel(3, "Medium", "moderate disruption or loss that can be absorbed by the business."),
+			templateLevel(4, "High", "serious disruption, data loss or financial damage."),
+		},
+		Likelihood: []*models.Level{
+			templateLevel(2, "Low", "unlikely to happen."),
+			templateLevel(3, "Medium", "could reasonably happen."),
+			templateLevel(4, "High", "expected to happen."),
+		},
+	},
+}
+
+func SeedMatrixTemplates() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	upsert := true
+	for _, template := range matrixTemplates {
+		key := template.Key
+		status := 1
+		levels := models.Matrix{Impact: template.Impact, Likelihood: template.Likelihood}
+		if template.Quantitative {
+			quantitative := quantitativeMatrixType
+			levels.Type = &quantitative
+		}
+		name := template.Name
+		description := template.Description
+		now := time.Now()
+		id := primitive.NewObjectID()
+
+		_, err := matrixCollection.UpdateOne(
+			ctx,
+			bson.M{"template": key},
+			bson.M{
+				"$set": bson.M{
+					"name":        &name,
+					"status":      &status,
+					"type":        matrixType(levels),
+					"description": &description,
+					"impact":      template.Impact,
+					"likelihood":  template.Likelihood,
+				},
+				"$setOnInsert": bson.M{
+					"_id":         id,
+					"matrix_id":   id.Hex(),
+					"user_id":     nil,
+					"template":    key,
+					"cloned_from": nil,
+					"created_at":  now,
+					"updated_at":  now,
+				},
+			},
+			&options.UpdateOptions{Upsert: &upsert},
+		)
+		if err != nil {
+			log.Printf("Failed to seed matrix template %s: %v", key, err)
+			continue
+		}
+
+		var matrix models.Matrix
+		if err := matrixCollection.FindOne(ctx, bson.M{"template": key}).Decode(&matrix); err != nil {
+			log.Printf("Failed to load matrix template %s: %v", key, err)
+			continue
+		}
+		if _, err := recordMatrixVersion(ctx, matrix, nil); err != nil {
+			log.Printf("Failed to version matrix template %s: %v", key, err)
+		}
+	}
+}
+
+func isTemplateMatrix(matrix models.Matrix) bool {
+	return matrix.Template != nil && *matrix.Template != ""
+}
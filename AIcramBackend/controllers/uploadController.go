@@ -0,0 +1,430 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"user-athentication-golang/database"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var uploadCollection *mongo.Collection = database.OpenCollection(database.Client, "upload")
+var uploadValidate = validator.New()
+
+const uploadExpiry = 24 * time.Hour
+const maxExtractSize int64 = 50 << 20
+
+func findUpload(c *gin.Context, ctx context.Context) (*models.Upload, bool) {
+	uploadId := c.Param("upload_id")
+
+	var upload models.Upload
+	err := uploadCollection.FindOne(ctx, bson.M{"upload_id": uploadId}).Decode(&upload)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching upload"})
+		return nil, false
+	}
+
+	if c.GetString("user_type") != "ADMIN" && (upload.User_id == nil || *upload.User_id != c.GetString("uid")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to access this upload"})
+		return nil, false
+	}
+
+	if upload.Expires_at.Before(time.Now()) && *upload.Status != 2 {
+		c.JSON(http.StatusGone, gin.H{"error": "upload expired"})
+		return nil, false
+	}
+
+	return &upload, true
+}
+
+func setUploadHeaders(c *gin.Context, upload *models.Upload) {
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(*upload.Size, 10))
+	c.Header("Upload-Expires", upload.Expires_at.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "no-store")
+}
+
+func CreateUpload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var upload models.Upload
+
+		if err := c.BindJSON(&upload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := uploadValidate.Struct(upload)
+		if validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		if *upload.Size > helper.MaxUploadSize() {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file exceeds the maximum upload size"})
+			return
+		}
+
+		userId := c.GetString("uid")
+		status := 1
+
+		upload.ID = primitive.NewObjectID()
+		upload.Upload_id = upload.ID.Hex()
+		upload.User_id = &userId
+		upload.Offset = 0
+		upload.Status = &status
+		upload.File_id = nil
+		if upload.File_type == nil {
+			fileType := "application/octet-stream"
+			upload.File_type = &fileType
+		}
+		upload.Created_at = time.Now()
+		upload.Updated_at = time.Now()
+		upload.Expires_at = time.Now().Add(uploadExpiry)
+
+		usage, err := reserveStorage(ctx, userId, c.GetString("user_type"), *upload.Size)
+		if err == errQuotaExceeded {
+			quotaExceededResponse(c, usage, *upload.Size)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while checking quota"})
+			return
+		}
+
+		if err := helper.CreateUploadPart(upload.Upload_id); err != nil {
+			releaseStorage(ctx, userId, *upload.Size)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create upload"})
+			return
+		}
+
+		_, insertErr := uploadCollection.InsertOne(ctx, upload)
+		if insertErr != nil {
+			helper.RemoveUploadPart(upload.Upload_id)
+			releaseStorage(ctx, userId, *upload.Size)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create upload"})
+			return
+		}
+
+		c.Header("Location", "/uploads/"+upload.Upload_id)
+		setUploadHeaders(c, &upload)
+		c.JSON(http.StatusCreated, upload)
+	}
+}
+
+func GetUploadOffset() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		upload, ok := findUpload(c, ctx)
+		if !ok {
+			return
+		}
+
+		setUploadHeaders(c, upload)
+		c.Status(http.StatusOK)
+	}
+}
+
+func GetUpload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		upload, ok := findUpload(c, ctx)
+		if !ok {
+			return
+		}
+
+		setUploadHeaders(c, upload)
+		c.JSON(http.StatusOK, upload)
+	}
+}
+
+func PatchUpload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		if c.ContentType() != "application/offset+octet-stream" {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "content type must be application/offset+octet-stream"})
+			return
+		}
+
+		offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Upload-Offset header"})
+			return
+		}
+
+		unlock := helper.LockUpload(c.Param("upload_id"))
+		defer unlock()
+
+		upload, ok := findUpload(c, ctx)
+		if !ok {
+			return
+		}
+
+		if *upload.Status != 1 {
+			c.JSON(http.StatusConflict, gin.H{"error": "upload already finalized"})
+			return
+		}
+
+		if offset != upload.Offset {
+			setUploadHeaders(c, upload)
+			c.JSON(http.StatusConflict, gin.H{"error": "offset_error", "offset": upload.Offset})
+			return
+		}
+
+		remaining := *upload.Size - upload.Offset
+		if c.Request.ContentLength > remaining {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "chunk exceeds the declared upload length"})
+			return
+		}
+
+		newOffset, writeErr := helper.AppendUploadPart(upload.Upload_id, offset, c.Request.Body, remaining)
+		if errors.Is(writeErr, helper.ErrUploadOffsetMismatch) && newOffset > *upload.Size {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "staged upload is corrupted"})
+			return
+		}
+
+		upload.Offset = newOffset
+		upload.Updated_at = time.Now()
+		upload.Expires_at = time.Now().Add(uploadExpiry)
+
+		_, err = uploadCollection.UpdateOne(
+			ctx,
+			bson.M{"upload_id": upload.Upload_id},
+			bson.M{"$set": bson.M{
+				"offset":     upload.Offset,
+				"updated_at": upload.Updated_at,
+				"expires_at": upload.Expires_at,
+			}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update upload"})
+			return
+		}
+
+		if errors.Is(writeErr, helper.ErrUploadOffsetMismatch) {
+			setUploadHeaders(c, upload)
+			c.JSON(http.StatusConflict, gin.H{"error": "offset_error", "offset": upload.Offset})
+			return
+		}
+
+		if writeErr != nil {
+			log.Printf("Upload %s interrupted at offset %d: %v", upload.Upload_id, newOffset, writeErr)
+			setUploadHeaders(c, upload)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to write chunk", "offset": upload.Offset})
+			return
+		}
+
+		setUploadHeaders(c, upload)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func FinalizeUpload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		unlock := helper.LockUpload(c.Param("upload_id"))
+		defer unlock()
+
+		upload, ok := findUpload(c, ctx)
+		if !ok {
+			return
+		}
+
+		if *upload.Status == 2 {
+			c.JSON(http.StatusOK, gin.H{"file_id": upload.File_id})
+			return
+		}
+
+		if upload.Offset != *upload.Size {
+			setUploadHeaders(c, upload)
+			c.JSON(http.StatusConflict, gin.H{"error": "upload incomplete", "offset": upload.Offset})
+			return
+		}
+
+		storage, err := helper.NewStorage()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to initialize storage"})
+			return
+		}
+
+		part, err := helper.OpenUploadPart(upload.Upload_id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open upload"})
+			return
+		}
+		defer part.Close()
+
+		stored, err := storage.Put(ctx, *upload.Original_name, part)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload to storage"})
+			return
+		}
+
+		var data []byte
+		if *upload.Size <= maxExtractSize && helper.IsExtractable(*upload.Original_name, *upload.File_type) {
+			data, err = os.ReadFile(part.Name())
+			if err != nil {
+				log.Printf("Failed to read upload %s for extraction: %v", upload.Upload_id, err)
+			}
+		}
+
+		fileRecord := newFileRecord(*upload.User_id, *upload.Original_name, *upload.File_type, *upload.Size, stored, data)
+
+		_, err = fileCollection.InsertOne(ctx, fileRecord)
+		if err != nil {
+			storage.Delete(ctx, stored.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file record"})
+			return
+		}
+
+		status := 2
+		_, err = uploadCollection.UpdateOne(
+			ctx,
+			bson.M{"upload_id": upload.Upload_id},
+			bson.M{"$set": bson.M{
+				"status":     status,
+				"file_id":    fileRecord.File_id,
+				"updated_at": time.Now(),
+			}},
+		)
+		if err != nil {
+			log.Printf("Failed to mark upload %s as finalized: %v", upload.Upload_id, err)
+		}
+
+		part.Close()
+		if err := helper.RemoveUploadPart(upload.Upload_id); err != nil {
+			log.Printf("Failed to remove staged upload %s: %v", upload.Upload_id, err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"file_id":   fileRecord.File_id,
+			"cloud_url": fileRecord.Cloud_url,
+			"extracted": fileRecord.Text != nil,
+		})
+	}
+}
+
+func DeleteUpload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		unlock := helper.LockUpload(c.Param("upload_id"))
+		defer unlock()
+
+		upload, ok := findUpload(c, ctx)
+		if !ok {
+			return
+		}
+
+		if err := helper.RemoveUploadPart(upload.Upload_id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete upload"})
+			return
+		}
+
+		result, err := uploadCollection.DeleteOne(ctx, bson.M{"upload_id": upload.Upload_id})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete upload"})
+			return
+		}
+
+		if *upload.Status == 1 && result.DeletedCount > 0 {
+			releaseStorage(ctx, *upload.User_id, *upload.Size)
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func ExpireUploads() {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Second)
+	defer cancel()
+
+	filter := bson.M{"status": 1, "expires_at": bson.M{"$lt": time.Now()}}
+
+	cursor, err := uploadCollection.Find(ctx, filter)
+	if err != nil {
+		log.Printf("Failed to list expired uploads: %v", err)
+		return
+	}
+
+	var uploads []models.Upload
+	if err = cursor.All(ctx, &uploads); err != nil {
+		log.Printf("Failed to list expired uploads: %v", err)
+		return
+	}
+
+	for _, upload := range uploads {
+		expireUpload(ctx, upload.Upload_id)
+	}
+
+	// Staged parts without a matching record (e.g. after a crash) are swept once they are well past expiry.
+	if _, err := helper.RemoveStaleUploadParts(2 * uploadExpiry); err != nil {
+		log.Printf("Failed to sweep stale uploads: %v", err)
+	}
+}
+
+// expireUpload removes one expired upload under its lock. The record is read again once the lock is held, since a
+// PATCH or finalize that was in flight may have extended or completed it in the meantime.
+func expireUpload(ctx context.Context, uploadId string) {
+	unlock := helper.LockUpload(uploadId)
+	defer unlock()
+
+	var upload models.Upload
+	err := uploadCollection.FindOne(ctx, bson.M{"upload_id": uploadId, "status": 1, "expires_at": bson.M{"$lt": time.Now()}}).Decode(&upload)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Failed to load expired upload %s: %v", uploadId, err)
+		}
+		return
+	}
+
+	if err := helper.RemoveUploadPart(upload.Upload_id); err != nil {
+		log.Printf("Failed to remove expired upload %s: %v", upload.Upload_id, err)
+		return
+	}
+	result, err := uploadCollection.DeleteOne(ctx, bson.M{"upload_id": upload.Upload_id, "status": 1})
+	if err == nil && result.DeletedCount > 0 && upload.User_id != nil {
+		releaseStorage(ctx, *upload.User_id, *upload.Size)
+	}
+}
+
+func StartUploadCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			ExpireUploads()
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,437 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-athentication-golang/database"
+
+	helper "user-athentication-golang/helpers"
+	"user-athentication-golang/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var usageCollection *mongo.Collection = database.OpenCollection(database.Client, "usage")
+
+var errQuotaExceeded = errors.New("quota_exceeded")
+
+const defaultStorageQuota int64 = 1 << 30
+
+// storageQuota is the quota of users without their own, from STORAGE_QUOTA (bytes).
+func storageQuota() int64 {
+	quota, err := strconv.ParseInt(os.Getenv("STORAGE_QUOTA"), 10, 64)
+	if err != nil || quota < 0 {
+		return defaultStorageQuota
+	}
+
+	return quota
+}
+
+func ensureUsage(ctx context.Context, userId string) (*models.Usage, error) {
+	now := time.Now()
+	upsert := true
+	after := options.After
+
+	var usage models.Usage
+	err := usageCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"user_id": userId},
+		bson.M{"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"user_id":    userId,
+			"bytes":      int64(0),
+			"file_count": int64(0),
+			"created_at": now,
+			"updated_at": now,
+		}},
+		&options.FindOneAndUpdateOptions{Upsert: &upsert, ReturnDocument: &after},
+	).Decode(&usage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+func usageQuota(usage *models.Usage) int64 {
+	if usage.Quota != nil {
+		return *usage.Quota
+	}
+
+	return storageQuota()
+}
+
+// reserveStorage atomically charges size bytes against the user's quota; admins are tracked but never blocked.
+func reserveStorage(ctx context.Context, userId string, userType string, size int64) (*models.Usage, error) {
+	usage, err := ensureUsage(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"user_id": userId}
+	if userType != "ADMIN" {
+		filter["bytes"] = bson.M{"$lte": usageQuota(usage) - size}
+	}
+
+	result, err := usageCollection.UpdateOne(
+		ctx,
+		filter,
+		bson.M{
+			"$inc": bson.M{"bytes": size, "file_count": 1},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return usage, err
+	}
+	if result.MatchedCount == 0 {
+		return usage, errQuotaExceeded
+	}
+
+	return usage, nil
+}
+
+func releaseStorage(ctx context.Context, userId string, size int64) {
+	if userId == "" {
+		return
+	}
+
+	_, err := usageCollection.UpdateOne(
+		ctx,
+		bson.M{"user_id": userId},
+		bson.M{
+			"$inc": bson.M{"bytes": -size, "file_count": -1},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		log.Printf("Failed to release storage for %s: %v", userId, err)
+	}
+}
+
+func storedBytes(file models.File) int64 {
+	if len(file.Variant) == 0 {
+		return file.Size
+	}
+
+	var total int64
+	for _, variant := range file.Variant {
+		if variant != nil {
+			total += variant.Bytes
+		}
+	}
+
+	return total
+}
+
+func quotaExceededResponse(c *gin.Context, usage *models.Usage, required int64) {
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error":    "quota_exceeded",
+		"message":  "storage quota exceeded",
+		"quota":    usageQuota(usage),
+		"used":     usage.Bytes,
+		"required": required,
+	})
+}
+
+func GetUsage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.Param("user_id")
+
+		if err := helper.MatchUserTypeToUid(c, userId); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		usage, err := ensureUsage(ctx, userId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching usage"})
+			return
+		}
+
+		quota := usageQuota(usage)
+		remaining := quota - usage.Bytes
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":    userId,
+			"bytes":      usage.Bytes,
+			"file_count": usage.File_count,
+			"quota":      quota,
+			"remaining":  remaining,
+			"updated_at": usage.Updated_at,
+		})
+	}
+}
+
+func UpdateQuota() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := helper.CheckUserType(c, "ADMIN"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userId := c.Param("user_id")
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var updateData models.Usage
+		if err := c.BindJSON(&updateData); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(updateData)
+		if validationErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		count, err := userCollection.CountDocuments(ctx, bson.M{"user_id": userId})
+		if err != nil || count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+
+		if _, err := ensureUsage(ctx, userId); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while fetching usage"})
+			return
+		}
+
+		result, err := usageCollection.UpdateOne(
+			ctx,
+			bson.M{"user_id": userId},
+			bson.M{"$set": bson.M{
+				"quota":      updateData.Quota,
+				"updated_at": time.Now(),
+			}},
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update quota"})
+			return
+		}
+
+		c.JSON(http.StatusOK, result.ModifiedCount)
+	}
+}
+
+func GetUsageReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := helper.CheckUserType(c, "ADMIN"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		bytesExpr := bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$variant", bson.A{}}}}, 0}},
+			bson.M{"$sum": "$variant.bytes"},
+			"$size",
+		}}
+
+		pipeline := mongo.Pipeline{
+			{{Key: "$group", Value: bson.M{
+				"_id":   bson.M{"user_id": bson.M{"$ifNull": bson.A{"$user_id", ""}}, "file_type": "$file_type"},
+				"bytes": bson.M{"$sum": bytesExpr},
+				"count": bson.M{"$sum": 1},
+			}}},
+			{{Key: "$sort", Value: bson.M{"bytes": -1}}},
+			{{Key: "$group", Value: bson.M{
+				"_id":        "$_id.user_id",
+				"bytes":      bson.M{"$sum": "$bytes"},
+				"file_count": bson.M{"$sum": "$count"},
+				"file_types": bson.M{"$push": bson.M{"file_type": "$_id.file_type", "bytes": "$bytes", "count": "$count"}},
+			}}},
+			{{Key: "$lookup", Value: bson.M{
+				"from":         "user",
+				"localField":   "_id",
+				"foreignField": "user_id",
+				"as":           "user",
+			}}},
+			{{Key: "$lookup", Value: bson.M{
+				"from":         "usage",
+				"localField":   "_id",
+				"foreignField": "user_id",
+				"as":           "usage",
+			}}},
+			{{Key: "$project", Value: bson.M{
+				"_id":        0,
+				"user_id":    "$_id",
+				"username":   bson.M{"$arrayElemAt": bson.A{"$user.username", 0}},
+				"bytes":      1,
+				"file_count": 1,
+				"file_types": 1,
+				"quota":      bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$usage.quota", 0}}, storageQuota()}},
+			}}},
+			{{Key: "$sort", Value: bson.M{"bytes": -1}}},
+		}
+
+		cursor, err := fileCollection.Aggregate(ctx, pipeline)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while building usage report"})
+			return
+		}
+
+		var userItems []bson.M
+		if err = cursor.All(ctx, &userItems); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while building usage report"})
+			return
+		}
+		if userItems == nil {
+			userItems = []bson.M{}
+		}
+
+		var totalBytes, totalCount int64
+		typeTotals := map[string]gin.H{}
+		typeOrder := []string{}
+		for _, item := range userItems {
+			totalBytes += toInt64(item["bytes"])
+			totalCount += toInt64(item["file_count"])
+
+			fileTypes, _ := item["file_types"].(bson.A)
+			for _, ft := range fileTypes {
+				entry, ok := ft.(bson.M)
+				if !ok {
+					continue
+				}
+				fileType, _ := entry["file_type"].(string)
+				total, exists := typeTotals[fileType]
+				if !exists {
+					total = gin.H{"file_type": fileType, "bytes": int64(0), "count": int64(0)}
+					typeTotals[fileType] = total
+					typeOrder = append(typeOrder, fileType)
+				}
+				total["bytes"] = total["bytes"].(int64) + toInt64(entry["bytes"])
+				total["count"] = total["count"].(int64) + toInt64(entry["count"])
+			}
+		}
+
+		fileTypeItems := []gin.H{}
+		for _, fileType := range typeOrder {
+			fileTypeItems = append(fileTypeItems, typeTotals[fileType])
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"total_bytes":     totalBytes,
+			"total_count":     totalCount,
+			"user_items":      userItems,
+			"file_type_items": fileTypeItems,
+		})
+	}
+}
+
+func RecalculateUsage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := helper.CheckUserType(c, "ADMIN"); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var ctx, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		cursor, err := fileCollection.Find(ctx, bson.M{"user_id": bson.M{"$nin": bson.A{nil, ""}}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing files"})
+			return
+		}
+
+		var files []models.File
+		if err = cursor.All(ctx, &files); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error occurred while listing files"})
+			return
+		}
+
+		totals := map[string]*models.Usage{}
+		for _, file := range files {
+			usage, ok := totals[*file.User_id]
+			if !ok {
+				usage = &models.Usage{}
+				totals[*file.User_id] = usage
+			}
+			usage.Bytes += storedBytes(file)
+			usage.File_count++
+		}
+
+		// Resumable uploads in progress hold a reservation until they finish or expire.
+		uploadCursor, err := uploadCollection.Find(ctx, bson.M{"status": 1})
+		if err == nil {
+			var uploads []models.Upload
+			if err = uploadCursor.All(ctx, &uploads); err == nil {
+				for _, upload := range uploads {
+					if upload.User_id == nil || upload.Size == nil {
+						continue
+					}
+					usage, ok := totals[*upload.User_id]
+					if !ok {
+						usage = &models.Usage{}
+						totals[*upload.User_id] = usage
+					}
+					usage.Bytes += *upload.Size
+					usage.File_count++
+				}
+			}
+		}
+
+		_, err = usageCollection.UpdateMany(ctx, bson.M{}, bson.M{"$set": bson.M{
+			"bytes":      int64(0),
+			"file_count": int64(0),
+			"updated_at": time.Now(),
+		}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset usage"})
+			return
+		}
+
+		for userId, total := range totals {
+			if _, err := ensureUsage(ctx, userId); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update usage"})
+				return
+			}
+			_, err := usageCollection.UpdateOne(ctx, bson.M{"user_id": userId}, bson.M{"$set": bson.M{
+				"bytes":      total.Bytes,
+				"file_count": total.File_count,
+				"updated_at": time.Now(),
+			}})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update usage"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"users_updated": len(totals)})
+	}
+}
+
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	case int:
+		return int64(v)
+	}
+
+	return 0
+}
@@ -15,7 +15,7 @@ import (
 func DBinstance() *mongo.Client {
 	err := godotenv.Load(".env")
 
-	if err != nil {
+	if err != nil && os.Getenv("MONGODB_URL") == "" {
 		log.Fatal("Error loading .env file")
 	}
 
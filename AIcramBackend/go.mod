@@ -11,8 +11,11 @@ require (
 	github.com/gin-gonic/gin v1.10.0
 	github.com/go-playground/validator/v10 v10.23.0
 	github.com/joho/godotenv v1.3.0
+	github.com/ledongthuc/pdf v0.0.0-20240201131950-da5b75280b06
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	go.mongodb.org/mongo-driver v1.4.5
 	golang.org/x/crypto v0.31.0
+	golang.org/x/image v0.23.0
 )
 
 require (
@@ -0,0 +1,205 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// AttackIDPattern matches ATT&CK tactic ("TA0001"), technique ("T1059", "T1059.001") and mitigation ("M1050") IDs.
+var AttackIDPattern = regexp.MustCompile(`\b(?:TA\d{4}|T\d{4}(?:\.\d{3})?|M\d{4})\b`)
+
+type attackReference struct {
+	SourceName string `json:"source_name"`
+	ExternalID string `json:"external_id"`
+	URL        string `json:"url"`
+}
+
+type attackPhase struct {
+	KillChainName string `json:"kill_chain_name"`
+	PhaseName     string `json:"phase_name"`
+}
+
+type attackObject struct {
+	Type               string            `json:"type"`
+	ID                 string            `json:"id"`
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	Revoked            bool              `json:"revoked"`
+	Deprecated         bool              `json:"x_mitre_deprecated"`
+	Shortname          string            `json:"x_mitre_shortname"`
+	Platforms          []string          `json:"x_mitre_platforms"`
+	Version            string            `json:"x_mitre_version"`
+	KillChainPhases    []attackPhase     `json:"kill_chain_phases"`
+	ExternalReferences []attackReference `json:"external_references"`
+	RelationshipType   string            `json:"relationship_type"`
+	SourceRef          string            `json:"source_ref"`
+	TargetRef          string            `json:"target_ref"`
+}
+
+// AttackEntry is one tactic, technique or mitigation of the dataset. Tactic and Mitigation hold ATT&CK IDs.
+type AttackEntry struct {
+	ID          string
+	Type        string
+	Name        string
+	Description string
+	URL         string
+	Shortname   string
+	Parent      string
+	Tactic      []string
+	Mitigation  []string
+	Platform    []string
+	Deprecated  bool
+}
+
+type AttackDataset struct {
+	Version string
+	Entries []AttackEntry
+}
+
+// attackReference returns the ATT&CK ID of the object. The ID has to fit the object type: legacy mitigations that
+// reuse technique IDs are ignored so they never overwrite the technique itself.
+func (o *attackObject) attackReference() (attackReference, bool) {
+	prefix := map[string]string{"x-mitre-tactic": "TA", "attack-pattern": "T", "course-of-action": "M"}[o.Type]
+	for _, reference := range o.ExternalReferences {
+		id := reference.ExternalID
+		if reference.SourceName != "mitre-attack" || !AttackIDPattern.MatchString(id) || !strings.HasPrefix(id, prefix) {
+			continue
+		}
+		if o.Type == "attack-pattern" && strings.HasPrefix(id, "TA") {
+			continue
+		}
+		return reference, true
+	}
+
+	return attackReference{}, false
+}
+
+// ReadAttackBundle parses an ATT&CK STIX 2.x bundle such as enterprise-attack.json. Revoked objects are left out
+// because ATT&CK replaces them with a different object; deprecated ones are kept and marked. Objects are decoded
+// one at a time, and only tactics, techniques, mitigations and their "mitigates" relationships are retained.
+func ReadAttackBundle(r io.Reader) (*AttackDataset, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, errors.New("bundle is not a JSON object")
+	}
+
+	objects := []attackObject{}
+	relationships := []attackObject{}
+	dataset := &AttackDataset{}
+	found := false
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if key, _ := token.(string); key != "objects" {
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		found = true
+		if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+			return nil, errors.New("objects is not an array")
+		}
+		for decoder.More() {
+			var object attackObject
+			if err := decoder.Decode(&object); err != nil {
+				return nil, err
+			}
+			if object.Revoked {
+				continue
+			}
+			switch object.Type {
+			case "x-mitre-collection":
+				dataset.Version = object.Version
+			case "x-mitre-tactic", "attack-pattern", "course-of-action":
+				objects = append(objects, object)
+			case "relationship":
+				if object.RelationshipType == "mitigates" {
+					relationships = append(relationships, object)
+				}
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+	if !found {
+		return nil, errors.New("bundle has no objects")
+	}
+
+	ids := map[string]string{}
+	tactics := map[string]string{}
+	for _, object := range objects {
+		if reference, ok := object.attackReference(); ok {
+			ids[object.ID] = reference.ExternalID
+			if object.Type == "x-mitre-tactic" {
+				tactics[object.Shortname] = reference.ExternalID
+			}
+		}
+	}
+
+	mitigations := map[string][]string{}
+	for _, relationship := range relationships {
+		source, target := ids[relationship.SourceRef], ids[relationship.TargetRef]
+		if strings.HasPrefix(source, "M") && strings.HasPrefix(target, "T") && !ContainsValue(mitigations[target], source) {
+			mitigations[target] = append(mitigations[target], source)
+		}
+	}
+
+	for _, object := range objects {
+		reference, ok := object.attackReference()
+		if !ok {
+			continue
+		}
+
+		entry := AttackEntry{
+			ID:          reference.ExternalID,
+			Name:        object.Name,
+			Description: object.Description,
+			URL:         reference.URL,
+			Platform:    object.Platforms,
+			Deprecated:  object.Deprecated,
+		}
+		switch object.Type {
+		case "x-mitre-tactic":
+			entry.Type = "tactic"
+			entry.Shortname = object.Shortname
+		case "course-of-action":
+			entry.Type = "mitigation"
+		default:
+			entry.Type = "technique"
+			if dot := strings.Index(entry.ID, "."); dot > 0 {
+				entry.Parent = entry.ID[:dot]
+			}
+			for _, phase := range object.KillChainPhases {
+				if tactic, ok := tactics[phase.PhaseName]; ok && phase.KillChainName == "mitre-attack" {
+					entry.Tactic = append(entry.Tactic, tactic)
+				}
+			}
+			entry.Mitigation = mitigations[entry.ID]
+			sort.Strings(entry.Mitigation)
+		}
+		dataset.Entries = append(dataset.Entries, entry)
+	}
+
+	return dataset, nil
+}
+
+// ContainsValue reports whether value is one of values.
+func ContainsValue(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
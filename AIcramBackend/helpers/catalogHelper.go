@@ -0,0 +1,181 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type oscalCatalogProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type oscalCatalogPart struct {
+	Name  string             `json:"name"`
+	Prose string             `json:"prose"`
+	Parts []oscalCatalogPart `json:"parts"`
+}
+
+type oscalCatalogControl struct {
+	ID       string                 `json:"id"`
+	Title    string                 `json:"title"`
+	Props    []oscalCatalogProperty `json:"props"`
+	Parts    []oscalCatalogPart     `json:"parts"`
+	Controls []oscalCatalogControl  `json:"controls"`
+}
+
+type oscalCatalogGroup struct {
+	ID       string                `json:"id"`
+	Title    string                `json:"title"`
+	Controls []oscalCatalogControl `json:"controls"`
+	Groups   []oscalCatalogGroup   `json:"groups"`
+}
+
+// CatalogEntry is one control of an imported catalog. Code is the label used in results ("AC-2", "AC-2(1)").
+type CatalogEntry struct {
+	Code        string
+	Title       string
+	Family      string
+	Parent      string
+	Description string
+	Withdrawn   bool
+}
+
+var oscalParameter = regexp.MustCompile(`\{\{\s*insert:\s*param,\s*[^}]*\}\}`)
+var nistOSCALId = regexp.MustCompile(`^([a-z]{2})-(\d+)(?:\.(\d+))?$`)
+var nistCode = regexp.MustCompile(`\b([A-Z]{2})-0*(\d+)(?:\s*\(\s*0*(\d+)\s*\)|\.0*(\d+))?`)
+var isoCode = regexp.MustCompile(`\b(?:A\s*\.?\s*)?(\d+(?:\.\d+)+)\b`)
+
+// NISTCode turns an OSCAL control id ("ac-2", "ac-2.1") into its SP 800-53 label ("AC-2", "AC-2(1)").
+func NISTCode(id string) string {
+	match := nistOSCALId.FindStringSubmatch(strings.ToLower(id))
+	if match == nil {
+		return ""
+	}
+
+	code := strings.ToUpper(match[1]) + "-" + trimNumber(match[2])
+	if match[3] != "" {
+		code += "(" + trimNumber(match[3]) + ")"
+	}
+
+	return code
+}
+
+// NISTCodes extracts SP 800-53 references written as "AC-2", "ac-02", "AC-2 (1)" or "ac-2.1" and returns them
+// in their canonical form.
+func NISTCodes(text string) []string {
+	codes := []string{}
+	for _, match := range nistCode.FindAllStringSubmatch(strings.ToUpper(text), -1) {
+		code := match[1] + "-" + trimNumber(match[2])
+		if enhancement := match[3] + match[4]; enhancement != "" {
+			code += "(" + trimNumber(enhancement) + ")"
+		}
+		codes = append(codes, code)
+	}
+
+	return codes
+}
+
+// ISOCodes extracts ISO/IEC 27001:2022 Annex A references ("A.8.8", "8.8", "A 5.15"). Numbers with more than two
+// levels, such as the 2013 edition's "A.12.6.1", are returned unchanged for the caller to flag as unverified.
+func ISOCodes(text string) []string {
+	codes := []string{}
+	for _, match := range isoCode.FindAllStringSubmatch(strings.ToUpper(text), -1) {
+		parts := strings.Split(match[1], ".")
+		for i := range parts {
+			parts[i] = trimNumber(parts[i])
+		}
+		codes = append(codes, "A."+strings.Join(parts, "."))
+	}
+
+	return codes
+}
+
+func trimNumber(value string) string {
+	if number, err := strconv.Atoi(value); err == nil {
+		return strconv.Itoa(number)
+	}
+
+	return value
+}
+
+func partProse(parts []oscalCatalogPart) []string {
+	lines := []string{}
+	for _, part := range parts {
+		if prose := strings.TrimSpace(oscalParameter.ReplaceAllString(part.Prose, "[organization-defined value]")); prose != "" {
+			lines = append(lines, prose)
+		}
+		lines = append(lines, partProse(part.Parts)...)
+	}
+
+	return lines
+}
+
+func catalogControls(controls []oscalCatalogControl, family string, parent string) []CatalogEntry {
+	entries := []CatalogEntry{}
+	for _, control := range controls {
+		code := NISTCode(control.ID)
+		if code == "" {
+			continue
+		}
+
+		entry := CatalogEntry{Code: code, Title: control.Title, Family: family, Parent: parent}
+		for _, prop := range control.Props {
+			if prop.Name == "status" && prop.Value == "withdrawn" {
+				entry.Withdrawn = true
+			}
+		}
+		for _, part := range control.Parts {
+			if part.Name == "statement" {
+				entry.Description = strings.Join(partProse([]oscalCatalogPart{part}), "\n")
+			}
+		}
+		entries = append(entries, entry)
+		entries = append(entries, catalogControls(control.Controls, family, code)...)
+	}
+
+	return entries
+}
+
+func catalogGroups(groups []oscalCatalogGroup) []CatalogEntry {
+	entries := []CatalogEntry{}
+	for _, group := range groups {
+		entries = append(entries, catalogControls(group.Controls, group.Title, "")...)
+		entries = append(entries, catalogGroups(group.Groups)...)
+	}
+
+	return entries
+}
+
+// ReadOSCALCatalog reads the NIST SP 800-53 catalog published in OSCAL JSON (NIST_SP-800-53_rev5_catalog.json),
+// returning every control and enhancement with the catalog version.
+func ReadOSCALCatalog(r io.Reader) ([]CatalogEntry, string, error) {
+	var document struct {
+		Catalog *struct {
+			Metadata struct {
+				Title   string `json:"title"`
+				Version string `json:"version"`
+			} `json:"metadata"`
+			Groups   []oscalCatalogGroup   `json:"groups"`
+			Controls []oscalCatalogControl `json:"controls"`
+		} `json:"catalog"`
+	}
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, "", err
+	}
+	if document.Catalog == nil {
+		return nil, "", errors.New("file is not an OSCAL catalog")
+	}
+
+	entries := catalogGroups(document.Catalog.Groups)
+	entries = append(entries, catalogControls(document.Catalog.Controls, "", "")...)
+	if len(entries) == 0 {
+		return nil, "", errors.New("catalog has no SP 800-53 controls")
+	}
+
+	return entries, document.Catalog.Metadata.Version, nil
+}
@@ -0,0 +1,209 @@
+package helper
+
+import (
+	"errors"
+	"strings"
+)
+
+const cpePrefix = "cpe:2.3:"
+
+// CPE component positions after the "cpe:2.3:" prefix.
+const (
+	CPEPart = iota
+	CPEVendor
+	CPEProduct
+	CPEVersion
+)
+
+const cpeComponents = 11
+
+// CPERange is the version range of an NVD CPE match criteria. Empty bounds are open.
+type CPERange struct {
+	StartIncluding string
+	StartExcluding string
+	EndIncluding   string
+	EndExcluding   string
+}
+
+func (r CPERange) empty() bool {
+	return r.StartIncluding == "" && r.StartExcluding == "" && r.EndIncluding == "" && r.EndExcluding == ""
+}
+
+// ParseCPE splits a CPE 2.3 formatted string into its eleven lowercased components. Escaped colons stay inside
+// their component, and missing trailing components are read as "*". Part must be a, o or h and vendor and product
+// must be named, since a wildcard there would match half of the dictionary.
+func ParseCPE(value string) ([]string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if !strings.HasPrefix(value, cpePrefix) {
+		return nil, errors.New("cpe must start with cpe:2.3:")
+	}
+
+	components := []string{}
+	var current strings.Builder
+	escaped := false
+	for _, char := range value[len(cpePrefix):] {
+		switch {
+		case escaped:
+			escaped = false
+			current.WriteRune(char)
+		case char == '\\':
+			escaped = true
+			current.WriteRune(char)
+		case char == ':':
+			components = append(components, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(char)
+		}
+	}
+	components = append(components, current.String())
+	if len(components) > cpeComponents {
+		return nil, errors.New("cpe has more than 11 components")
+	}
+	for len(components) < cpeComponents {
+		components = append(components, "*")
+	}
+	for i, component := range components {
+		if component == "" {
+			components[i] = "*"
+		}
+	}
+
+	if part := components[CPEPart]; part != "a" && part != "o" && part != "h" {
+		return nil, errors.New("cpe part must be a, o or h")
+	}
+	for _, component := range components[CPEVendor : CPEProduct+1] {
+		if component == "*" || component == "-" {
+			return nil, errors.New("cpe vendor and product must be named")
+		}
+	}
+
+	return components, nil
+}
+
+// FormatCPE joins parsed components back into a CPE 2.3 formatted string.
+func FormatCPE(components []string) string {
+	return cpePrefix + strings.Join(components, ":")
+}
+
+// CPEProductPrefix is the "cpe:2.3:part:vendor:product:" prefix shared by every version of the product.
+func CPEProductPrefix(components []string) string {
+	return FormatCPE(components[:CPEProduct+1]) + ":"
+}
+
+// MatchCPE reports whether an installed component matches an NVD match criteria. Components other than the version
+// match when equal or when either side is "*". When the installed version is not known, only criteria that cover
+// every version match.
+func MatchCPE(installed []string, criteria []string, versions CPERange) bool {
+	for i := 0; i < cpeComponents; i++ {
+		if i == CPEVersion {
+			continue
+		}
+		if installed[i] != criteria[i] && installed[i] != "*" && criteria[i] != "*" {
+			return false
+		}
+	}
+
+	version, wanted := installed[CPEVersion], criteria[CPEVersion]
+	if version == "*" || version == "-" {
+		return (wanted == "*" || wanted == version) && versions.empty()
+	}
+	if wanted != "*" {
+		return wanted != "-" && CompareVersions(version, wanted) == 0
+	}
+
+	if versions.StartIncluding != "" && CompareVersions(version, versions.StartIncluding) < 0 {
+		return false
+	}
+	if versions.StartExcluding != "" && CompareVersions(version, versions.StartExcluding) <= 0 {
+		return false
+	}
+	if versions.EndIncluding != "" && CompareVersions(version, versions.EndIncluding) > 0 {
+		return false
+	}
+	if versions.EndExcluding != "" && CompareVersions(version, versions.EndExcluding) >= 0 {
+		return false
+	}
+
+	return true
+}
+
+func versionTokens(version string) []string {
+	tokens := []string{}
+	start := -1
+	digits := false
+	for i, char := range version {
+		isDigit := char >= '0' && char <= '9'
+		isLetter := char >= 'a' && char <= 'z'
+		if start >= 0 && (!(isDigit || isLetter) || isDigit != digits) {
+			tokens = append(tokens, version[start:i])
+			start = -1
+		}
+		if start < 0 && (isDigit || isLetter) {
+			start = i
+			digits = isDigit
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, version[start:])
+	}
+
+	return tokens
+}
+
+func numericToken(token string) bool {
+	return token != "" && token[0] >= '0' && token[0] <= '9'
+}
+
+// extraToken returns 1 when a version with the extra trailing token sorts after the shorter one ("1.0.1", "1.1.1t")
+// and -1 for pre-releases ("1.0rc1", "2.0-beta").
+func extraToken(token string) int {
+	if !numericToken(token) {
+		for _, prefix := range []string{"alpha", "beta", "rc", "pre", "dev", "snapshot"} {
+			if strings.HasPrefix(token, prefix) {
+				return -1
+			}
+		}
+	}
+
+	return 1
+}
+
+// CompareVersions orders dotted versions such as "2.4.57", "1.1.1t" or "10.0-rc1". Numeric parts compare as
+// numbers and a number sorts after letters at the same position, so "1.0rc1" < "1.0" < "1.0.1" < "1.0.1a".
+// It returns -1, 0 or 1.
+func CompareVersions(a string, b string) int {
+	left, right := versionTokens(strings.ToLower(a)), versionTokens(strings.ToLower(b))
+	for i := 0; i < len(left) || i < len(right); i++ {
+		if i >= len(left) {
+			return -extraToken(right[i])
+		}
+		if i >= len(right) {
+			return extraToken(left[i])
+		}
+
+		x, y := left[i], right[i]
+		switch {
+		case numericToken(x) && numericToken(y):
+			x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+			if len(x) != len(y) {
+				if len(x) < len(y) {
+					return -1
+				}
+				return 1
+			}
+		case numericToken(x):
+			return 1
+		case numericToken(y):
+			return -1
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
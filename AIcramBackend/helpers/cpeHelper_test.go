@@ -0,0 +1,123 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0rc1", "1.0", -1},
+		{"1.0", "1.0.1", -1},
+		{"1.0.1", "1.0.1a", -1},
+		{"1.0rc1", "1.0rc2", -1},
+		{"1.0-beta", "1.0-rc1", -1},
+		{"2.0-beta", "2.0", -1},
+		{"2.4.57", "2.4.9", 1},
+		{"10.0", "9.9", 1},
+		{"1.01", "1.1", 0},
+		{"1.1.1t", "1.1.1s", 1},
+		{"1.0RC1", "1.0rc1", 0},
+		{"1.0a", "1.0.1", -1},
+	}
+
+	for _, test := range tests {
+		if got := CompareVersions(test.a, test.b); got != test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := CompareVersions(test.b, test.a); got != -test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestParseCPE(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+		err   bool
+	}{
+		{
+			value: "cpe:2.3:a:apache:http_server:2.4.49:*:*:*:*:*:*:*",
+			want:  []string{"a", "apache", "http_server", "2.4.49", "*", "*", "*", "*", "*", "*", "*"},
+		},
+		{
+			value: " CPE:2.3:O:Microsoft:Windows_10:1909 ",
+			want:  []string{"o", "microsoft", "windows_10", "1909", "*", "*", "*", "*", "*", "*", "*"},
+		},
+		{
+			value: `cpe:2.3:a:vendor:product\:name:1.0::*:*:*:*:*:*`,
+			want:  []string{"a", "vendor", `product\:name`, "1.0", "*", "*", "*", "*", "*", "*", "*"},
+		},
+		{value: "cpe:/a:apache:http_server:2.4.49", err: true},
+		{value: "cpe:2.3:x:apache:http_server:2.4.49", err: true},
+		{value: "cpe:2.3:a:*:http_server:2.4.49", err: true},
+		{value: "cpe:2.3:a:apache:-:2.4.49", err: true},
+		{value: "cpe:2.3:a:apache:http_server:1:2:3:4:5:6:7:8:9", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseCPE(test.value)
+		if test.err {
+			if err == nil {
+				t.Errorf("ParseCPE(%q) = %v, want an error", test.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseCPE(%q): %v", test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseCPE(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestMatchCPE(t *testing.T) {
+	const anyVersion = "cpe:2.3:a:apache:http_server:*:*:*:*:*:*:*:*"
+	tests := []struct {
+		name      string
+		installed string
+		criteria  string
+		versions  CPERange
+		want      bool
+	}{
+		{"every version", "cpe:2.3:a:apache:http_server:2.4.49", anyVersion, CPERange{}, true},
+		{"inside range", "cpe:2.3:a:apache:http_server:2.4.49", anyVersion, CPERange{StartIncluding: "2.4.0", EndExcluding: "2.4.51"}, true},
+		{"at excluded end", "cpe:2.3:a:apache:http_server:2.4.49", anyVersion, CPERange{EndExcluding: "2.4.49"}, false},
+		{"at included end", "cpe:2.3:a:apache:http_server:2.4.49", anyVersion, CPERange{EndIncluding: "2.4.49"}, true},
+		{"at excluded start", "cpe:2.3:a:apache:http_server:2.4.49", anyVersion, CPERange{StartExcluding: "2.4.49"}, false},
+		{"at included start", "cpe:2.3:a:apache:http_server:2.4.49", anyVersion, CPERange{StartIncluding: "2.4.49"}, true},
+		{"before start", "cpe:2.3:a:apache:http_server:2.2.34", anyVersion, CPERange{StartIncluding: "2.4.0"}, false},
+		{"pre-release before end", "cpe:2.3:a:apache:http_server:2.4.51rc1", anyVersion, CPERange{EndExcluding: "2.4.51"}, true},
+		{"exact version", "cpe:2.3:a:apache:http_server:2.4.49", "cpe:2.3:a:apache:http_server:2.4.49", CPERange{}, true},
+		{"other exact version", "cpe:2.3:a:apache:http_server:2.4.49", "cpe:2.3:a:apache:http_server:2.4.50", CPERange{}, false},
+		{"other product", "cpe:2.3:a:apache:tomcat:2.4.49", anyVersion, CPERange{}, false},
+		{"other target platform", "cpe:2.3:a:apache:http_server:2.4.49:*:*:*:*:linux", "cpe:2.3:a:apache:http_server:*:*:*:*:*:windows:*:*", CPERange{}, false},
+		{"unknown version, every version", "cpe:2.3:a:apache:http_server", anyVersion, CPERange{}, true},
+		{"unknown version, range", "cpe:2.3:a:apache:http_server", anyVersion, CPERange{EndExcluding: "2.4.51"}, false},
+		{"not applicable version", "cpe:2.3:a:apache:http_server:-", "cpe:2.3:a:apache:http_server:-", CPERange{}, true},
+		{"versioned against not applicable", "cpe:2.3:a:apache:http_server:2.4.49", "cpe:2.3:a:apache:http_server:-", CPERange{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			installed, err := ParseCPE(test.installed)
+			if err != nil {
+				t.Fatalf("ParseCPE(%q): %v", test.installed, err)
+			}
+			criteria, err := ParseCPE(test.criteria)
+			if err != nil {
+				t.Fatalf("ParseCPE(%q): %v", test.criteria, err)
+			}
+			if got := MatchCPE(installed, criteria, test.versions); got != test.want {
+				t.Errorf("MatchCPE(%q, %q, %+v) = %v, want %v", test.installed, test.criteria, test.versions, got, test.want)
+			}
+		})
+	}
+}
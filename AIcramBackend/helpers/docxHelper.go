@@ -0,0 +1,249 @@
+package helper
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	markdownTableSeparator = regexp.MustCompile(`^\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?$`)
+	markdownRuleLine       = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+	markdownOrdered        = regexp.MustCompile(`^\d+[.)]\s+`)
+	markdownInline         = regexp.MustCompile("\\*\\*([^*]+)\\*\\*|\\*([^*\\s][^*]*)\\*|`([^`]+)`")
+)
+
+func xmlText(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+
+	return buf.String()
+}
+
+// wordRuns converts **bold**, *italic* and `code` spans into runs; other Markdown is written literally.
+func wordRuns(s string, bold bool) string {
+	var sb strings.Builder
+	run := func(text string, runBold bool, italic bool, code bool) {
+		if text == "" {
+			return
+		}
+		sb.WriteString("<w:r>")
+		if runBold || italic || code {
+			sb.WriteString("<w:rPr>")
+			if code {
+				sb.WriteString(`<w:rFonts w:ascii="Consolas" w:hAnsi="Consolas"/>`)
+			}
+			if runBold {
+				sb.WriteString("<w:b/>")
+			}
+			if italic {
+				sb.WriteString("<w:i/>")
+			}
+			sb.WriteString("</w:rPr>")
+		}
+		fmt.Fprintf(&sb, `<w:t xml:space="preserve">%s</w:t></w:r>`, xmlText(text))
+	}
+
+	last := 0
+	for _, match := range markdownInline.FindAllStringSubmatchIndex(s, -1) {
+		run(s[last:match[0]], bold, false, false)
+		switch {
+		case match[2] >= 0:
+			run(s[match[2]:match[3]], true, false, false)
+		case match[4] >= 0:
+			run(s[match[4]:match[5]], bold, true, false)
+		case match[6] >= 0:
+			run(s[match[6]:match[7]], bold, false, true)
+		}
+		last = match[1]
+	}
+	run(s[last:], bold, false, false)
+
+	return sb.String()
+}
+
+func wordParagraph(style string, properties string, runs string) string {
+	pPr := properties
+	if style != "" {
+		pPr = fmt.Sprintf(`<w:pStyle w:val="%s"/>`, style) + pPr
+	}
+	if pPr != "" {
+		pPr = "<w:pPr>" + pPr + "</w:pPr>"
+	}
+
+	return "<w:p>" + pPr + runs + "</w:p>"
+}
+
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+
+	return cells
+}
+
+func wordTable(lines []string) string {
+	header := len(lines) > 1 && markdownTableSeparator.MatchString(strings.TrimSpace(lines[1]))
+	rows := [][]string{}
+	columns := 0
+	for i, line := range lines {
+		if header && i == 1 {
+			continue
+		}
+		cells := tableCells(line)
+		if len(cells) > columns {
+			columns = len(cells)
+		}
+		rows = append(rows, cells)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="ReportTable"/><w:tblW w:w="5000" w:type="pct"/></w:tblPr><w:tblGrid>`)
+	for i := 0; i < columns; i++ {
+		sb.WriteString(`<w:gridCol/>`)
+	}
+	sb.WriteString(`</w:tblGrid>`)
+	for i, cells := range rows {
+		sb.WriteString("<w:tr>")
+		for j := 0; j < columns; j++ {
+			text := ""
+			if j < len(cells) {
+				text = cells[j]
+			}
+			sb.WriteString("<w:tc><w:tcPr><w:tcW w:w=\"0\" w:type=\"auto\"/></w:tcPr>")
+			sb.WriteString(wordParagraph("", `<w:spacing w:before="40" w:after="40"/>`, wordRuns(text, header && i == 0)))
+			sb.WriteString("</w:tc>")
+		}
+		sb.WriteString("</w:tr>")
+	}
+	sb.WriteString("</w:tbl>")
+	// Word needs a paragraph between consecutive tables and before the end of the body.
+	sb.WriteString(wordParagraph("", "", ""))
+
+	return sb.String()
+}
+
+// markdownBody converts headings, paragraphs, bullet and numbered lists, block quotes, rules and pipe tables.
+func markdownBody(markdown string) string {
+	var sb strings.Builder
+	paragraph := []string{}
+	flush := func() {
+		if len(paragraph) > 0 {
+			sb.WriteString(wordParagraph("", "", wordRuns(strings.Join(paragraph, " "), false)))
+			paragraph = paragraph[:0]
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			if level > 3 {
+				level = 3
+			}
+			sb.WriteString(wordParagraph(fmt.Sprintf("Heading%d", level), "", wordRuns(strings.TrimSpace(strings.TrimLeft(trimmed, "#")), false)))
+		case strings.HasPrefix(trimmed, "|"):
+			flush()
+			table := []string{}
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				table = append(table, lines[i])
+			}
+			i--
+			sb.WriteString(wordTable(table))
+		case markdownRuleLine.MatchString(trimmed):
+			flush()
+			sb.WriteString(wordParagraph("", `<w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="BFBFBF"/></w:pBdr>`, ""))
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "), strings.HasPrefix(trimmed, "+ "):
+			flush()
+			indent := 0
+			if len(line)-len(strings.TrimLeft(line, " \t")) >= 2 {
+				indent = 1
+			}
+			sb.WriteString(wordParagraph("ListParagraph", fmt.Sprintf(`<w:numPr><w:ilvl w:val="%d"/><w:numId w:val="1"/></w:numPr>`, indent), wordRuns(trimmed[2:], false)))
+		case markdownOrdered.MatchString(trimmed):
+			flush()
+			sb.WriteString(wordParagraph("ListParagraph", `<w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr>`, wordRuns(markdownOrdered.ReplaceAllString(trimmed, ""), false)))
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			sb.WriteString(wordParagraph("Quote", "", wordRuns(strings.TrimSpace(strings.TrimPrefix(trimmed, ">")), false)))
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return sb.String()
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/><Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/><Override PartName="/word/numbering.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"/><Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/></Types>`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/></Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering" Target="numbering.xml"/></Relationships>`
+
+const docxNumbering = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="•"/><w:lvlJc w:val="left"/><w:pPr><w:ind w:left="720" w:hanging="360"/></w:pPr></w:lvl><w:lvl w:ilvl="1"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="◦"/><w:lvlJc w:val="left"/><w:pPr><w:ind w:left="1440" w:hanging="360"/></w:pPr></w:lvl></w:abstractNum><w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/><w:lvlJc w:val="left"/><w:pPr><w:ind w:left="720" w:hanging="360"/></w:pPr></w:lvl></w:abstractNum><w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num><w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num></w:numbering>`
+
+const docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:eastAsia="Calibri" w:cs="Calibri"/><w:sz w:val="21"/></w:rPr></w:rPrDefault><w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="276" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults><w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style><w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="360" w:after="120"/><w:pBdr><w:bottom w:val="single" w:sz="8" w:space="4" w:color="%[1]s"/></w:pBdr><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:color w:val="%[1]s"/><w:sz w:val="32"/></w:rPr></w:style><w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="80"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:color w:val="%[1]s"/><w:sz w:val="26"/></w:rPr></w:style><w:style w:type="paragraph" w:styleId="Heading3"><w:name w:val="heading 3"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="200" w:after="60"/><w:outlineLvl w:val="2"/></w:pPr><w:rPr><w:b/><w:sz w:val="22"/></w:rPr></w:style><w:style w:type="paragraph" w:styleId="ListParagraph"><w:name w:val="List Paragraph"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:after="40"/><w:ind w:left="720"/></w:pPr></w:style><w:style w:type="paragraph" w:styleId="Quote"><w:name w:val="Quote"/><w:basedOn w:val="Normal"/><w:pPr><w:ind w:left="567"/></w:pPr><w:rPr><w:i/><w:color w:val="595959"/></w:rPr></w:style><w:style w:type="table" w:styleId="ReportTable"><w:name w:val="Report Table"/><w:tblPr><w:tblBorders><w:top w:val="single" w:sz="4" w:color="BFBFBF"/><w:left w:val="single" w:sz="4" w:color="BFBFBF"/><w:bottom w:val="single" w:sz="4" w:color="BFBFBF"/><w:right w:val="single" w:sz="4" w:color="BFBFBF"/><w:insideH w:val="single" w:sz="4" w:color="BFBFBF"/><w:insideV w:val="single" w:sz="4" w:color="BFBFBF"/></w:tblBorders><w:tblCellMar><w:left w:w="80" w:type="dxa"/><w:right w:w="80" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style></w:styles>`
+
+// MarkdownToDOCX writes a Word document from the Markdown subset produced by report templates. Headings use the
+// given brand color.
+func MarkdownToDOCX(title string, markdown string, color Color) ([]byte, error) {
+	hex := fmt.Sprintf("%02X%02X%02X", int(color[0]*255+0.5), int(color[1]*255+0.5), int(color[2]*255+0.5))
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		markdownBody(markdown) +
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="567" w:footer="567" w:gutter="0"/></w:sectPr></w:body></w:document>`
+	core := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><dc:title>%s</dc:title><dcterms:created xsi:type="dcterms:W3CDTF">%s</dcterms:created></cp:coreProperties>`,
+		xmlText(title), time.Now().UTC().Format(time.RFC3339))
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	parts := []struct {
+		Name string
+		Body string
+	}{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRels},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/document.xml", document},
+		{"word/styles.xml", fmt.Sprintf(docxStyles, hex)},
+		{"word/numbering.xml", docxNumbering},
+		{"docProps/core.xml", core},
+	}
+	for _, part := range parts {
+		w, err := archive.Create(part.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(part.Body)); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,230 @@
+package helper
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const MaxExtractedTextLength = 200000
+
+var ErrUnsupportedDocument = errors.New("unsupported document type")
+
+func IsExtractable(fileName string, contentType string) bool {
+	return documentKind(fileName, contentType) != ""
+}
+
+func ExtractText(data []byte, fileName string, contentType string) (text string, err error) {
+	switch documentKind(fileName, contentType) {
+	case "pdf":
+		text, err = extractPDF(data)
+	case "docx":
+		text, err = extractDOCX(data)
+	case "markdown":
+		text, err = extractMarkdown(data)
+	case "text":
+		text, err = extractPlain(data)
+	default:
+		return "", ErrUnsupportedDocument
+	}
+	if err != nil {
+		return "", err
+	}
+
+	text = normalizeWhitespace(text)
+	if len(text) > MaxExtractedTextLength {
+		text = text[:MaxExtractedTextLength]
+		for !utf8.ValidString(text) {
+			text = text[:len(text)-1]
+		}
+	}
+
+	return text, nil
+}
+
+func documentKind(fileName string, contentType string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".pdf":
+		return "pdf"
+	case ".docx":
+		return "docx"
+	case ".md", ".markdown":
+		return "markdown"
+	case ".txt", ".text", ".log", ".csv":
+		return "text"
+	}
+
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch contentType {
+	case "application/pdf":
+		return "pdf"
+	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		return "docx"
+	case "text/markdown", "text/x-markdown":
+		return "markdown"
+	case "text/plain", "text/csv":
+		return "text"
+	}
+
+	return ""
+}
+
+func extractPDF(data []byte) (text string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed pdf: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, plain); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func extractDOCX(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, f := range archive.File {
+		if f.Name != "word/document.xml" {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+
+		return readWordXML(rc)
+	}
+
+	return "", errors.New("docx document body not found")
+}
+
+func readWordXML(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+	var sb strings.Builder
+	inText := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "cr":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteString("\n")
+			case "tc":
+				sb.WriteString("\t")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
+
+var (
+	markdownFence    = regexp.MustCompile("(?m)^\\s*(```|~~~).*$")
+	markdownImage    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownHeading  = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s*`)
+	markdownQuote    = regexp.MustCompile(`(?m)^\s*>\s?`)
+	markdownBullet   = regexp.MustCompile(`(?m)^(\s*)[-*+]\s+`)
+	markdownRule     = regexp.MustCompile(`(?m)^\s*([-*_]\s*){3,}$`)
+	markdownEmphasis = regexp.MustCompile(`(\*\*|__|~~|\*|` + "`" + `)`)
+	markdownHTML     = regexp.MustCompile(`<[^>]+>`)
+)
+
+func extractMarkdown(data []byte) (string, error) {
+	text, err := extractPlain(data)
+	if err != nil {
+		return "", err
+	}
+
+	text = markdownFence.ReplaceAllString(text, "")
+	text = markdownImage.ReplaceAllString(text, "$1")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownRule.ReplaceAllString(text, "")
+	text = markdownHeading.ReplaceAllString(text, "")
+	text = markdownQuote.ReplaceAllString(text, "")
+	text = markdownBullet.ReplaceAllString(text, "$1- ")
+	text = markdownHTML.ReplaceAllString(text, "")
+	text = markdownEmphasis.ReplaceAllString(text, "")
+
+	return text, nil
+}
+
+func extractPlain(data []byte) (string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return "", errors.New("text document is not valid utf-8")
+	}
+
+	return string(data), nil
+}
+
+var (
+	repeatedSpaces   = regexp.MustCompile(`[ \t\f\v]+`)
+	repeatedNewlines = regexp.MustCompile(`\n{3,}`)
+)
+
+func normalizeWhitespace(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = repeatedSpaces.ReplaceAllString(text, " ")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = strings.Join(lines, "\n")
+	text = repeatedNewlines.ReplaceAllString(text, "\n\n")
+
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,129 @@
+package helper
+
+import "sort"
+
+// DependencyGraph maps a node to the nodes it depends on. An edge A -> B means A depends on B,
+// so a compromise of B impacts A.
+type DependencyGraph map[string][]string
+
+type ImpactedNode struct {
+	ID    string
+	Depth int
+}
+
+func (g DependencyGraph) dependents() map[string][]string {
+	reverse := map[string][]string{}
+	for node, dependencies := range g {
+		for _, dependency := range dependencies {
+			reverse[dependency] = append(reverse[dependency], node)
+		}
+	}
+
+	return reverse
+}
+
+// BlastRadius returns every node transitively depending on one of the start nodes, including the start nodes
+// themselves at depth 0, ordered by depth and id. Cycles are visited once.
+func (g DependencyGraph) BlastRadius(start []string) []ImpactedNode {
+	reverse := g.dependents()
+	depth := map[string]int{}
+	queue := []string{}
+	for _, node := range start {
+		if _, seen := depth[node]; !seen {
+			depth[node] = 0
+			queue = append(queue, node)
+		}
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, dependent := range reverse[node] {
+			if _, seen := depth[dependent]; !seen {
+				depth[dependent] = depth[node] + 1
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	impacted := make([]ImpactedNode, 0, len(depth))
+	for node, d := range depth {
+		impacted = append(impacted, ImpactedNode{ID: node, Depth: d})
+	}
+	sort.Slice(impacted, func(i, j int) bool {
+		if impacted[i].Depth != impacted[j].Depth {
+			return impacted[i].Depth < impacted[j].Depth
+		}
+		return impacted[i].ID < impacted[j].ID
+	})
+
+	return impacted
+}
+
+// Cycles returns the strongly connected components that form a dependency cycle, including self-dependencies,
+// using Tarjan's algorithm. Each cycle and the list of cycles are sorted for stable output.
+func (g DependencyGraph) Cycles() [][]string {
+	nodes := make([]string, 0, len(g))
+	for node := range g {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	index := 0
+	indices := map[string]int{}
+	lowlink := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	cycles := [][]string{}
+
+	var connect func(node string)
+	connect = func(node string) {
+		indices[node] = index
+		lowlink[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		selfLoop := false
+		for _, dependency := range g[node] {
+			if dependency == node {
+				selfLoop = true
+			}
+			if _, visited := indices[dependency]; !visited {
+				connect(dependency)
+				if lowlink[dependency] < lowlink[node] {
+					lowlink[node] = lowlink[dependency]
+				}
+			} else if onStack[dependency] && indices[dependency] < lowlink[node] {
+				lowlink[node] = indices[dependency]
+			}
+		}
+
+		if lowlink[node] == indices[node] {
+			component := []string{}
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == node {
+					break
+				}
+			}
+			if len(component) > 1 || selfLoop {
+				sort.Strings(component)
+				cycles = append(cycles, component)
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		if _, visited := indices[node]; !visited {
+			connect(node)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+
+	return cycles
+}
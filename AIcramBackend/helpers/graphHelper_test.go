@@ -0,0 +1,124 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlastRadius(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph DependencyGraph
+		start []string
+		want  []ImpactedNode
+	}{
+		{
+			name:  "isolated node",
+			graph: DependencyGraph{"db": nil},
+			start: []string{"db"},
+			want:  []ImpactedNode{{ID: "db", Depth: 0}},
+		},
+		{
+			name:  "chain of dependents",
+			graph: DependencyGraph{"web": {"app"}, "app": {"db"}, "db": nil},
+			start: []string{"db"},
+			want:  []ImpactedNode{{ID: "db", Depth: 0}, {ID: "app", Depth: 1}, {ID: "web", Depth: 2}},
+		},
+		{
+			name:  "dependencies are not impacted",
+			graph: DependencyGraph{"web": {"app"}, "app": {"db"}},
+			start: []string{"app"},
+			want:  []ImpactedNode{{ID: "app", Depth: 0}, {ID: "web", Depth: 1}},
+		},
+		{
+			name:  "shortest depth wins",
+			graph: DependencyGraph{"web": {"app", "db"}, "app": {"db"}},
+			start: []string{"db"},
+			want:  []ImpactedNode{{ID: "db", Depth: 0}, {ID: "app", Depth: 1}, {ID: "web", Depth: 1}},
+		},
+		{
+			name:  "cycle is visited once",
+			graph: DependencyGraph{"a": {"b"}, "b": {"c"}, "c": {"a"}},
+			start: []string{"a"},
+			want:  []ImpactedNode{{ID: "a", Depth: 0}, {ID: "c", Depth: 1}, {ID: "b", Depth: 2}},
+		},
+		{
+			name:  "duplicate start nodes",
+			graph: DependencyGraph{"app": {"db", "cache"}},
+			start: []string{"db", "cache", "db"},
+			want:  []ImpactedNode{{ID: "cache", Depth: 0}, {ID: "db", Depth: 0}, {ID: "app", Depth: 1}},
+		},
+		{
+			name:  "unknown start node",
+			graph: DependencyGraph{"app": {"db"}},
+			start: []string{"missing"},
+			want:  []ImpactedNode{{ID: "missing", Depth: 0}},
+		},
+		{
+			name:  "no start nodes",
+			graph: DependencyGraph{"app": {"db"}},
+			start: nil,
+			want:  []ImpactedNode{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.graph.BlastRadius(test.start); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("BlastRadius(%v) = %v, want %v", test.start, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCycles(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph DependencyGraph
+		want  [][]string
+	}{
+		{
+			name:  "acyclic",
+			graph: DependencyGraph{"web": {"app"}, "app": {"db"}, "db": nil},
+			want:  [][]string{},
+		},
+		{
+			name:  "self dependency",
+			graph: DependencyGraph{"app": {"app", "db"}},
+			want:  [][]string{{"app"}},
+		},
+		{
+			name:  "two node cycle",
+			graph: DependencyGraph{"a": {"b"}, "b": {"a"}},
+			want:  [][]string{{"a", "b"}},
+		},
+		{
+			name:  "cycle reached through a tail",
+			graph: DependencyGraph{"web": {"c"}, "c": {"b"}, "b": {"a"}, "a": {"c"}},
+			want:  [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:  "separate cycles are sorted",
+			graph: DependencyGraph{"y": {"z"}, "z": {"y"}, "a": {"b"}, "b": {"a"}, "m": {"m"}},
+			want:  [][]string{{"a", "b"}, {"m"}, {"y", "z"}},
+		},
+		{
+			name:  "nested cycles form one component",
+			graph: DependencyGraph{"a": {"b"}, "b": {"a", "c"}, "c": {"b"}},
+			want:  [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:  "dependency missing from the graph",
+			graph: DependencyGraph{"app": {"db"}},
+			want:  [][]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.graph.Cycles(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Cycles() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package helper
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type NVDText struct {
+	Lang  string `json:"lang"`
+	Value string `json:"value"`
+}
+
+type NVDCVSSData struct {
+	Version      string  `json:"version"`
+	VectorString string  `json:"vectorString"`
+	BaseScore    float64 `json:"baseScore"`
+	BaseSeverity string  `json:"baseSeverity"`
+}
+
+type NVDMetric struct {
+	Source   string      `json:"source"`
+	Type     string      `json:"type"`
+	CvssData NVDCVSSData `json:"cvssData"`
+}
+
+type NVDWeakness struct {
+	Source      string    `json:"source"`
+	Type        string    `json:"type"`
+	Description []NVDText `json:"description"`
+}
+
+type NVDCPEMatch struct {
+	Vulnerable            bool   `json:"vulnerable"`
+	Criteria              string `json:"criteria"`
+	VersionStartIncluding string `json:"versionStartIncluding"`
+	VersionStartExcluding string `json:"versionStartExcluding"`
+	VersionEndIncluding   string `json:"versionEndIncluding"`
+	VersionEndExcluding   string `json:"versionEndExcluding"`
+}
+
+type NVDNode struct {
+	Operator string        `json:"operator"`
+	Negate   bool          `json:"negate"`
+	CpeMatch []NVDCPEMatch `json:"cpeMatch"`
+}
+
+type NVDConfiguration struct {
+	Nodes []NVDNode `json:"nodes"`
+}
+
+// NVDCVE is the "cve" object of an NVD CVE API 2.0 record, limited to the fields the knowledge base keeps.
+type NVDCVE struct {
+	ID             string             `json:"id"`
+	Published      string             `json:"published"`
+	LastModified   string             `json:"lastModified"`
+	VulnStatus     string             `json:"vulnStatus"`
+	Descriptions   []NVDText          `json:"descriptions"`
+	Weaknesses     []NVDWeakness      `json:"weaknesses"`
+	Configurations []NVDConfiguration `json:"configurations"`
+	Metrics        struct {
+		CvssMetricV31 []NVDMetric `json:"cvssMetricV31"`
+		CvssMetricV30 []NVDMetric `json:"cvssMetricV30"`
+	} `json:"metrics"`
+}
+
+// ParseNVDTime reads NVD timestamps, which are UTC and usually written without a zone ("2021-12-10T10:15:09.143").
+func ParseNVDTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse("2006-01-02T15:04:05.999999999", value)
+}
+
+// Description returns the English description.
+func (c *NVDCVE) Description() string {
+	for _, description := range c.Descriptions {
+		if description.Lang == "en" {
+			return strings.TrimSpace(description.Value)
+		}
+	}
+
+	return ""
+}
+
+// CVSS returns the CVSS v3.1 metric, preferring the primary (NVD) score over those of other sources. When the
+// record only has v3.0 metrics that one is used instead.
+func (c *NVDCVE) CVSS() *NVDCVSSData {
+	for _, metrics := range [][]NVDMetric{c.Metrics.CvssMetricV31, c.Metrics.CvssMetricV30} {
+		for _, metric := range metrics {
+			if metric.Type == "Primary" {
+				data := metric.CvssData
+				return &data
+			}
+		}
+		if len(metrics) > 0 {
+			data := metrics[0].CvssData
+			return &data
+		}
+	}
+
+	return nil
+}
+
+// CWE returns the distinct CWE identifiers of the weaknesses, skipping placeholders such as "NVD-CWE-noinfo".
+func (c *NVDCVE) CWE() []string {
+	seen := map[string]bool{}
+	items := []string{}
+	for _, weakness := range c.Weaknesses {
+		for _, description := range weakness.Description {
+			value := strings.TrimSpace(description.Value)
+			if strings.HasPrefix(value, "CWE-") && !seen[value] {
+				seen[value] = true
+				items = append(items, value)
+			}
+		}
+	}
+
+	return items
+}
+
+// CPEMatches returns the distinct CPE match criteria of every non-negated configuration node.
+func (c *NVDCVE) CPEMatches() []NVDCPEMatch {
+	seen := map[NVDCPEMatch]bool{}
+	items := []NVDCPEMatch{}
+	for _, configuration := range c.Configurations {
+		for _, node := range configuration.Nodes {
+			if node.Negate {
+				continue
+			}
+			for _, match := range node.CpeMatch {
+				if match.Criteria != "" && !seen[match] {
+					seen[match] = true
+					items = append(items, match)
+				}
+			}
+		}
+	}
+
+	return items
+}
+
+// ReadNVDFeed streams the records of an NVD JSON 2.0 feed file, plain or gzip compressed, calling handle for
+// each one so that multi-hundred-megabyte yearly feeds are never held in memory.
+func ReadNVDFeed(r io.Reader, handle func(*NVDCVE) error) error {
+	buffered := bufio.NewReader(r)
+	var source io.Reader = buffered
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		source = gz
+	}
+
+	decoder := json.NewDecoder(source)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return errors.New("feed is not a JSON object")
+	}
+
+	found := false
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch key, _ := token.(string); key {
+		case "format", "version":
+			var value string
+			if err := decoder.Decode(&value); err != nil {
+				return err
+			}
+			if (key == "format" && value != "NVD_CVE") || (key == "version" && value != "2.0") {
+				return fmt.Errorf("unsupported feed %s %q, expected NVD_CVE 2.0", key, value)
+			}
+		case "CVE_Items":
+			return errors.New("NVD JSON 1.1 feeds are not supported, use the 2.0 feeds")
+		case "vulnerabilities":
+			found = true
+			if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+				return errors.New("vulnerabilities is not an array")
+			}
+			for decoder.More() {
+				var item struct {
+					Cve NVDCVE `json:"cve"`
+				}
+				if err := decoder.Decode(&item); err != nil {
+					return err
+				}
+				if err := handle(&item.Cve); err != nil {
+					return err
+				}
+			}
+			if _, err := decoder.Token(); err != nil {
+				return err
+			}
+		default:
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !found {
+		return errors.New("feed has no vulnerabilities")
+	}
+
+	return nil
+}
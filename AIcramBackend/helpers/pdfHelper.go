@@ -0,0 +1,424 @@
+package helper
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A4 in points, with content laid out top-down between the margins.
+const (
+	PageWidth    = 595.28
+	PageHeight   = 841.89
+	PageMargin   = 50.0
+	headerHeight = 40.0
+	footerHeight = 30.0
+)
+
+type Color [3]float64
+
+var (
+	colorText  = Color{0.13, 0.13, 0.13}
+	colorMuted = Color{0.45, 0.45, 0.45}
+	colorWhite = Color{1, 1, 1}
+	colorGrid  = Color{0.8, 0.8, 0.8}
+)
+
+// ParseColor reads a "#rrggbb" hex color, returning fallback when it is malformed.
+func ParseColor(hex string, fallback Color) Color {
+	hex = strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(hex) != 6 {
+		return fallback
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return fallback
+	}
+
+	return Color{float64(value>>16&0xff) / 255, float64(value>>8&0xff) / 255, float64(value&0xff) / 255}
+}
+
+// PDF writes a simple flowing document with the standard Helvetica fonts, so no font files are embedded.
+// Text is encoded as WinAnsi; characters outside it are replaced with "?".
+type PDF struct {
+	title string
+	brand string
+	color Color
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64
+}
+
+func NewPDF(title string, brand string, color Color) *PDF {
+	pdf := &PDF{title: title, brand: brand, color: color}
+	pdf.AddPage()
+
+	return pdf
+}
+
+func (p *PDF) AddPage() {
+	p.page = &bytes.Buffer{}
+	p.pages = append(p.pages, p.page)
+	p.y = PageMargin + headerHeight
+}
+
+// ensure starts a new page unless height points still fit above the footer.
+func (p *PDF) ensure(height float64) {
+	if p.y+height > PageHeight-PageMargin-footerHeight {
+		p.AddPage()
+	}
+}
+
+func (p *PDF) Space(height float64) {
+	p.y += height
+}
+
+func (p *PDF) contentWidth() float64 {
+	return PageWidth - 2*PageMargin
+}
+
+func (p *PDF) fillRect(x, y, w, h float64, color Color) {
+	fmt.Fprintf(p.page, "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n", color[0], color[1], color[2], x, PageHeight-y-h, w, h)
+}
+
+func (p *PDF) strokeRect(x, y, w, h float64, color Color) {
+	fmt.Fprintf(p.page, "%.3f %.3f %.3f RG 0.5 w %.2f %.2f %.2f %.2f re S\n", color[0], color[1], color[2], x, PageHeight-y-h, w, h)
+}
+
+// text draws a single line with its baseline at y.
+func (p *PDF) text(x, y, size float64, bold bool, color Color, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.page, "BT %.3f %.3f %.3f rg /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", color[0], color[1], color[2], font, size, x, PageHeight-y, pdfString(s))
+}
+
+// lines draws wrapped text at x within width and advances the cursor, breaking pages between lines.
+func (p *PDF) lines(x, width, size float64, bold bool, color Color, s string) {
+	leading := size * 1.35
+	for _, line := range WrapText(s, width, size, bold) {
+		p.ensure(leading)
+		p.y += leading
+		p.text(x, p.y-size*0.3, size, bold, color, line)
+	}
+}
+
+func (p *PDF) Heading(s string) {
+	p.ensure(60)
+	p.Space(10)
+	p.lines(PageMargin, p.contentWidth(), 15, true, p.color, s)
+	p.fillRect(PageMargin, p.y+3, p.contentWidth(), 1.2, p.color)
+	p.Space(8)
+}
+
+func (p *PDF) Subheading(s string) {
+	p.ensure(40)
+	p.Space(6)
+	p.lines(PageMargin, p.contentWidth(), 11.5, true, colorText, s)
+	p.Space(2)
+}
+
+func (p *PDF) Paragraph(s string) {
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(paragraph) == "" {
+			p.Space(5)
+			continue
+		}
+		p.lines(PageMargin, p.contentWidth(), 10, false, colorText, paragraph)
+	}
+	p.Space(4)
+}
+
+// Field writes a bold label followed by its value indented in a second column.
+func (p *PDF) Field(label string, value string) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+
+	const labelWidth = 130.0
+	wrapped := WrapText(value, p.contentWidth()-labelWidth, 10, false)
+	p.ensure(13.5)
+	top := p.y
+	p.text(PageMargin, top+13.5-3, 10, true, colorMuted, label)
+	for i, line := range wrapped {
+		if i > 0 {
+			p.ensure(13.5)
+		}
+		p.y += 13.5
+		p.text(PageMargin+labelWidth, p.y-3, 10, false, colorText, line)
+	}
+}
+
+func (p *PDF) Bullet(s string) {
+	wrapped := WrapText(s, p.contentWidth()-14, 10, false)
+	for i, line := range wrapped {
+		p.ensure(13.5)
+		p.y += 13.5
+		if i == 0 {
+			p.text(PageMargin+2, p.y-3, 10, false, p.color, "•")
+		}
+		p.text(PageMargin+14, p.y-3, 10, false, colorText, line)
+	}
+}
+
+// Heatmap draws a grid with rows (likelihood, highest first) against columns (impact, lowest first), coloring
+// cells from green to red by their position and printing the count of items in each cell.
+func (p *PDF) Heatmap(title string, rows []string, columns []string, counts [][]int) {
+	if len(rows) == 0 || len(columns) == 0 {
+		return
+	}
+
+	const labelWidth = 95.0
+	const cellHeight = 24.0
+	cellWidth := (p.contentWidth() - labelWidth) / float64(len(columns))
+	p.ensure(cellHeight*float64(len(rows)) + 70)
+	p.Subheading(title)
+
+	top := p.y + 4
+	for i, row := range rows {
+		y := top + float64(i)*cellHeight
+		p.text(PageMargin, y+cellHeight/2+3, 8, false, colorText, truncateText(row, labelWidth-6, 8))
+		for j := range columns {
+			x := PageMargin + labelWidth + float64(j)*cellWidth
+			severity := float64((len(rows)-i)*(j+1)) / float64(len(rows)*len(columns))
+			p.fillRect(x, y, cellWidth, cellHeight, heatColor(severity))
+			p.strokeRect(x, y, cellWidth, cellHeight, colorWhite)
+
+			count := 0
+			if i < len(counts) && j < len(counts[i]) {
+				count = counts[i][j]
+			}
+			if count > 0 {
+				label := strconv.Itoa(count)
+				p.text(x+cellWidth/2-TextWidth(label, 11, true)/2, y+cellHeight/2+4, 11, true, colorText, label)
+			}
+		}
+	}
+
+	bottom := top + float64(len(rows))*cellHeight
+	for j, column := range columns {
+		x := PageMargin + labelWidth + float64(j)*cellWidth
+		label := truncateText(column, cellWidth-4, 8)
+		p.text(x+cellWidth/2-TextWidth(label, 8, false)/2, bottom+11, 8, false, colorText, label)
+	}
+	p.text(PageMargin, bottom+11, 8, true, colorMuted, "Likelihood / Impact")
+	p.y = bottom + 22
+}
+
+// heatColor blends green, amber and red for a severity between 0 and 1.
+func heatColor(severity float64) Color {
+	green := Color{0.55, 0.80, 0.45}
+	amber := Color{1.00, 0.84, 0.40}
+	red := Color{0.93, 0.42, 0.38}
+	from, to, t := green, amber, severity*2
+	if severity > 0.5 {
+		from, to, t = amber, red, (severity-0.5)*2
+	}
+
+	return Color{from[0] + (to[0]-from[0])*t, from[1] + (to[1]-from[1])*t, from[2] + (to[2]-from[2])*t}
+}
+
+func (p *PDF) decorate(number int, total int) []byte {
+	page := &bytes.Buffer{}
+	current := p.page
+	p.page = page
+
+	p.fillRect(0, 0, PageWidth, headerHeight, p.color)
+	p.text(PageMargin, 25, 12, true, colorWhite, p.brand)
+	title := truncateText(p.title, PageWidth/2, 10)
+	p.text(PageWidth-PageMargin-TextWidth(title, 10, false), 25, 10, false, colorWhite, title)
+
+	footer := PageHeight - PageMargin/2
+	p.fillRect(PageMargin, footer-14, p.contentWidth(), 0.6, colorGrid)
+	p.text(PageMargin, footer, 8, false, colorMuted, p.brand+" – Confidential")
+	pageLabel := fmt.Sprintf("Page %d of %d", number, total)
+	p.text(PageWidth-PageMargin-TextWidth(pageLabel, 8, false), footer, 8, false, colorMuted, pageLabel)
+
+	p.page = current
+
+	return page.Bytes()
+}
+
+// Bytes renders the document, adding the branded header and numbered footer to every page.
+func (p *PDF) Bytes() []byte {
+	out := &bytes.Buffer{}
+	offsets := []int{}
+	object := func(body string, stream []byte) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(out, "%d 0 obj\n%s", len(offsets), body)
+		if stream != nil {
+			fmt.Fprintf(out, "\nstream\n")
+			out.Write(stream)
+			fmt.Fprintf(out, "\nendstream")
+		}
+		fmt.Fprintf(out, "\nendobj\n")
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are the catalog, page tree and fonts; each page then takes a page and a content object.
+	kids := []string{}
+	for i := range p.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 6+2*i))
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>", nil)
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)), nil)
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>", nil)
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>", nil)
+	object(fmt.Sprintf("<< /Title (%s) /Producer (%s) /CreationDate (D:%s) >>",
+		pdfString(p.title), pdfString(p.brand), time.Now().UTC().Format("20060102150405Z")), nil)
+
+	for i, page := range p.pages {
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		writer.Write(p.decorate(i+1, len(p.pages)))
+		writer.Write(page.Bytes())
+		writer.Close()
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, 7+2*i), nil)
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", compressed.Len()), compressed.Bytes())
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+func winAnsi(r rune) byte {
+	switch {
+	case r == '\t':
+		return ' '
+	case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+		return byte(r)
+	}
+	if b, ok := winAnsiExtra[r]; ok {
+		return b
+	}
+
+	return '?'
+}
+
+func pdfString(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		b := winAnsi(r)
+		switch b {
+		case '(', ')', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(b)
+		default:
+			if b < 0x80 {
+				sb.WriteByte(b)
+			} else {
+				fmt.Fprintf(&sb, "\\%03o", b)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// Helvetica and Helvetica-Bold advance widths for characters 32-126, in thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+func TextWidth(s string, size float64, bold bool) float64 {
+	widths := helveticaWidths
+	if bold {
+		widths = helveticaBoldWidths
+	}
+
+	total := 0
+	for _, r := range s {
+		b := winAnsi(r)
+		if b >= 32 && b <= 126 {
+			total += widths[b-32]
+		} else {
+			total += 556
+		}
+	}
+
+	return float64(total) * size / 1000
+}
+
+// WrapText breaks s into lines no wider than width, splitting words that do not fit on a line of their own.
+func WrapText(s string, width float64, size float64, bold bool) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for TextWidth(word, size, bold) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			cut := len(runes) - 1
+			for cut > 1 && TextWidth(string(runes[:cut]), size, bold) > width {
+				cut--
+			}
+			lines = append(lines, string(runes[:cut]))
+			word = string(runes[cut:])
+		}
+
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if TextWidth(candidate, size, bold) > width {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func truncateText(s string, width float64, size float64) string {
+	if TextWidth(s, size, false) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 && TextWidth(string(runes)+"…", size, false) > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "…"
+}
@@ -0,0 +1,152 @@
+package helper
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const DefaultSimulationIterations = 10000
+
+// Above this many events in a year the per-event losses are summed with a normal approximation instead of one draw per event.
+const poissonNormalThreshold = 30
+
+type LossRange struct {
+	Min float64
+	Max float64
+}
+
+// LossScenario describes one loss event type: how often it happens per year and how much a single occurrence costs.
+type LossScenario struct {
+	Frequency LossRange
+	Magnitude LossRange
+}
+
+type LossDistribution struct {
+	Iterations int
+	Mean       float64
+	P10        float64
+	P50        float64
+	P90        float64
+	P95        float64
+	P99        float64
+}
+
+// SimulateLoss runs a FAIR-style Monte Carlo simulation of annualized loss for each scenario.
+// Frequencies and magnitudes are drawn from PERT distributions with the mode at the midpoint of their range,
+// the number of events per year is Poisson distributed and the total is the sum of all scenarios per iteration.
+func SimulateLoss(scenarios []LossScenario, iterations int, seed int64) ([]LossDistribution, LossDistribution) {
+	if iterations < 1 {
+		iterations = DefaultSimulationIterations
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	samples := make([][]float64, len(scenarios))
+	for i := range samples {
+		samples[i] = make([]float64, iterations)
+	}
+	totals := make([]float64, iterations)
+
+	for n := 0; n < iterations; n++ {
+		for i, scenario := range scenarios {
+			frequency := samplePert(random, scenario.Frequency)
+			events := samplePoisson(random, frequency)
+
+			loss := 0.0
+			if events > poissonNormalThreshold {
+				mean := (scenario.Magnitude.Min + scenario.Magnitude.Max) / 2
+				spread := (scenario.Magnitude.Max - scenario.Magnitude.Min) / math.Sqrt(28)
+				loss = math.Max(0, float64(events)*mean+random.NormFloat64()*math.Sqrt(float64(events))*spread)
+			} else {
+				for e := 0; e < events; e++ {
+					loss += samplePert(random, scenario.Magnitude)
+				}
+			}
+
+			samples[i][n] = loss
+			totals[n] += loss
+		}
+	}
+
+	distributions := make([]LossDistribution, len(scenarios))
+	for i := range samples {
+		distributions[i] = summarizeLoss(samples[i])
+	}
+
+	return distributions, summarizeLoss(totals)
+}
+
+// samplePert draws from a PERT distribution whose mode is the midpoint of r, i.e. min + (max-min) * Beta(3, 3).
+func samplePert(random *rand.Rand, r LossRange) float64 {
+	if r.Max <= r.Min {
+		return r.Min
+	}
+
+	x := sampleGamma3(random)
+	y := sampleGamma3(random)
+
+	return r.Min + (r.Max-r.Min)*x/(x+y)
+}
+
+// sampleGamma3 draws from Gamma(3, 1) as the sum of three unit exponentials.
+func sampleGamma3(random *rand.Rand) float64 {
+	return random.ExpFloat64() + random.ExpFloat64() + random.ExpFloat64()
+}
+
+func samplePoisson(random *rand.Rand, lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+
+	if lambda > poissonNormalThreshold {
+		return int(math.Max(0, math.Round(lambda+random.NormFloat64()*math.Sqrt(lambda))))
+	}
+
+	limit := math.Exp(-lambda)
+	events := 0
+	product := random.Float64()
+	for product > limit {
+		events++
+		product *= random.Float64()
+	}
+
+	return events
+}
+
+func summarizeLoss(samples []float64) LossDistribution {
+	distribution := LossDistribution{Iterations: len(samples)}
+	if len(samples) == 0 {
+		return distribution
+	}
+
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, sample := range sorted {
+		sum += sample
+	}
+
+	distribution.Mean = sum / float64(len(sorted))
+	distribution.P10 = percentile(sorted, 10)
+	distribution.P50 = percentile(sorted, 50)
+	distribution.P90 = percentile(sorted, 90)
+	distribution.P95 = percentile(sorted, 95)
+	distribution.P99 = percentile(sorted, 99)
+
+	return distribution
+}
+
+// percentile interpolates linearly between the closest ranks of an ascending slice.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
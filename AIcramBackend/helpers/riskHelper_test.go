@@ -0,0 +1,170 @@
+package helper
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{[]float64{7}, 90, 7},
+		{[]float64{1, 2, 3, 4, 5}, 0, 1},
+		{[]float64{1, 2, 3, 4, 5}, 50, 3},
+		{[]float64{1, 2, 3, 4, 5}, 100, 5},
+		{[]float64{1, 2, 3, 4, 5}, 10, 1.4},
+		{[]float64{1, 2, 3, 4, 5}, 95, 4.8},
+		{[]float64{0, 10}, 25, 2.5},
+	}
+
+	for _, test := range tests {
+		if got := percentile(test.sorted, test.p); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("percentile(%v, %v) = %v, want %v", test.sorted, test.p, got, test.want)
+		}
+	}
+}
+
+func TestSummarizeLoss(t *testing.T) {
+	distribution := summarizeLoss([]float64{5, 1, 4, 2, 3})
+	want := LossDistribution{Iterations: 5, Mean: 3, P10: 1.4, P50: 3, P90: 4.6, P95: 4.8, P99: 4.96}
+	for _, field := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"Mean", distribution.Mean, want.Mean},
+		{"P10", distribution.P10, want.P10},
+		{"P50", distribution.P50, want.P50},
+		{"P90", distribution.P90, want.P90},
+		{"P95", distribution.P95, want.P95},
+		{"P99", distribution.P99, want.P99},
+	} {
+		if math.Abs(field.got-field.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", field.name, field.got, field.want)
+		}
+	}
+	if distribution.Iterations != want.Iterations {
+		t.Errorf("Iterations = %d, want %d", distribution.Iterations, want.Iterations)
+	}
+
+	if empty := summarizeLoss(nil); empty != (LossDistribution{}) {
+		t.Errorf("summarizeLoss(nil) = %+v, want zero", empty)
+	}
+}
+
+func TestSamplePert(t *testing.T) {
+	tests := []struct {
+		r    LossRange
+		mean float64
+	}{
+		{LossRange{Min: 0, Max: 1}, 0.5},
+		{LossRange{Min: 10, Max: 30}, 20},
+		{LossRange{Min: 0.001, Max: 0.01}, 0.0055},
+	}
+
+	random := rand.New(rand.NewSource(1))
+	const draws = 100000
+	for _, test := range tests {
+		sum := 0.0
+		for i := 0; i < draws; i++ {
+			value := samplePert(random, test.r)
+			if value < test.r.Min || value > test.r.Max {
+				t.Fatalf("samplePert(%+v) = %v, outside the range", test.r, value)
+			}
+			sum += value
+		}
+		// Beta(3, 3) has a standard deviation of 1/sqrt(28) of the range; allow five standard errors.
+		tolerance := 5 * (test.r.Max - test.r.Min) / math.Sqrt(28) / math.Sqrt(draws)
+		if mean := sum / draws; math.Abs(mean-test.mean) > tolerance {
+			t.Errorf("samplePert(%+v) mean = %v, want %v", test.r, mean, test.mean)
+		}
+	}
+
+	if got := samplePert(random, LossRange{Min: 4, Max: 4}); got != 4 {
+		t.Errorf("samplePert of an empty range = %v, want 4", got)
+	}
+	if got := samplePert(random, LossRange{Min: 4, Max: 2}); got != 4 {
+		t.Errorf("samplePert of an inverted range = %v, want 4", got)
+	}
+}
+
+func TestSamplePoisson(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	const draws = 100000
+	for _, lambda := range []float64{0.1, 1, 5, 25, 50, 300} {
+		sum, squares := 0.0, 0.0
+		for i := 0; i < draws; i++ {
+			events := float64(samplePoisson(random, lambda))
+			if events < 0 {
+				t.Fatalf("samplePoisson(%v) = %v", lambda, events)
+			}
+			sum += events
+			squares += events * events
+		}
+
+		mean := sum / draws
+		variance := squares/draws - mean*mean
+		if tolerance := 5 * math.Sqrt(lambda/draws); math.Abs(mean-lambda) > tolerance {
+			t.Errorf("samplePoisson(%v) mean = %v", lambda, mean)
+		}
+		if math.Abs(variance-lambda) > 0.05*lambda+0.01 {
+			t.Errorf("samplePoisson(%v) variance = %v", lambda, variance)
+		}
+	}
+
+	for _, lambda := range []float64{0, -1} {
+		if got := samplePoisson(random, lambda); got != 0 {
+			t.Errorf("samplePoisson(%v) = %d, want 0", lambda, got)
+		}
+	}
+}
+
+func TestSimulateLoss(t *testing.T) {
+	scenarios := []LossScenario{
+		{Frequency: LossRange{Min: 2, Max: 2}, Magnitude: LossRange{Min: 1000, Max: 1000}},
+		{Frequency: LossRange{Min: 0, Max: 0}, Magnitude: LossRange{Min: 500, Max: 900}},
+		{Frequency: LossRange{Min: 0.1, Max: 1}, Magnitude: LossRange{Min: 100, Max: 300}},
+	}
+
+	distributions, total := SimulateLoss(scenarios, 20000, 7)
+	if len(distributions) != len(scenarios) {
+		t.Fatalf("got %d distributions, want %d", len(distributions), len(scenarios))
+	}
+
+	// Two events a year of exactly 1000 each.
+	if mean := distributions[0].Mean; math.Abs(mean-2000) > 50 {
+		t.Errorf("fixed scenario mean = %v, want about 2000", mean)
+	}
+	if distributions[1] != (LossDistribution{Iterations: 20000}) {
+		t.Errorf("scenario that never happens = %+v, want no loss", distributions[1])
+	}
+	// Mean frequency 0.55 a year times a mean magnitude of 200.
+	if mean := distributions[2].Mean; math.Abs(mean-110) > 10 {
+		t.Errorf("ranged scenario mean = %v, want about 110", mean)
+	}
+
+	sum := 0.0
+	for _, distribution := range distributions {
+		sum += distribution.Mean
+	}
+	if math.Abs(total.Mean-sum) > 1e-6 {
+		t.Errorf("total mean = %v, want the sum of the scenario means %v", total.Mean, sum)
+	}
+	for _, distribution := range append(distributions, total) {
+		if !(distribution.P10 <= distribution.P50 && distribution.P50 <= distribution.P90 &&
+			distribution.P90 <= distribution.P95 && distribution.P95 <= distribution.P99) {
+			t.Errorf("percentiles out of order: %+v", distribution)
+		}
+	}
+
+	_, again := SimulateLoss(scenarios, 20000, 7)
+	if again != total {
+		t.Errorf("same seed gave %+v, then %+v", total, again)
+	}
+	if _, defaulted := SimulateLoss(scenarios, 0, 7); defaulted.Iterations != DefaultSimulationIterations {
+		t.Errorf("iterations = %d, want the default %d", defaulted.Iterations, DefaultSimulationIterations)
+	}
+}
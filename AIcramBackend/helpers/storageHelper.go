@@ -0,0 +1,67 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+type StoredFile struct {
+	URL string
+	ID  string
+}
+
+type Storage interface {
+	Put(ctx context.Context, name string, file io.Reader) (*StoredFile, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type cloudinaryStorage struct {
+	cld    *cloudinary.Cloudinary
+	folder string
+}
+
+func NewStorage() (Storage, error) {
+	cloudinaryURL := os.Getenv("CLOUDINARY_URL")
+	if cloudinaryURL == "" {
+		return nil, errors.New("CLOUDINARY_URL is not configured")
+	}
+
+	cld, err := cloudinary.NewFromURL(cloudinaryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cloudinaryStorage{cld: cld, folder: "aicram"}, nil
+}
+
+func (s *cloudinaryStorage) Put(ctx context.Context, name string, file io.Reader) (*StoredFile, error) {
+	// *os.File is passed through untouched so the SDK can switch to chunked uploads for large files.
+	result, err := s.cld.Upload.Upload(ctx, file, uploader.UploadParams{
+		Folder: s.folder,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Error.Message != "" {
+		return nil, errors.New(result.Error.Message)
+	}
+
+	return &StoredFile{URL: result.SecureURL, ID: result.PublicID}, nil
+}
+
+func (s *cloudinaryStorage) Delete(ctx context.Context, id string) error {
+	result, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: id})
+	if err != nil {
+		return err
+	}
+	if result.Error.Message != "" {
+		return errors.New(result.Error.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,485 @@
+package helper
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ListSeparator splits list cells such as "GDPR; HIPAA" in tabular files.
+const ListSeparator = ";"
+
+var ErrUnsupportedTable = errors.New("unsupported table type")
+
+// TableRow is a data row keyed by lower-cased header, with Row the 1-based line in the file.
+type TableRow struct {
+	Row    int
+	Values map[string]string
+}
+
+func TableKind(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return "csv"
+	case ".xlsx":
+		return "xlsx"
+	case ".json":
+		return "json"
+	}
+
+	return ""
+}
+
+// ReadTable reads a CSV file or the first worksheet of an XLSX workbook, using the first row as header
+// and skipping blank rows.
+func ReadTable(data []byte, fileName string) ([]TableRow, error) {
+	var records [][]string
+	var err error
+	switch TableKind(fileName) {
+	case "csv":
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		records, err = reader.ReadAll()
+	case "xlsx":
+		records, err = readWorksheet(data)
+	default:
+		return nil, ErrUnsupportedTable
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("table has no header row")
+	}
+
+	header := make([]string, len(records[0]))
+	for i, name := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	rows := []TableRow{}
+	for i, record := range records[1:] {
+		values := map[string]string{}
+		for j, value := range record {
+			value = strings.TrimSpace(value)
+			if j < len(header) && header[j] != "" && value != "" {
+				values[header[j]] = value
+			}
+		}
+		if len(values) > 0 {
+			rows = append(rows, TableRow{Row: i + 2, Values: values})
+		}
+	}
+
+	return rows, nil
+}
+
+func readZipFile(archive *zip.Reader, name string) ([]byte, error) {
+	for _, f := range archive.File {
+		if f.Name != name {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+
+		return io.ReadAll(rc)
+	}
+
+	return nil, nil
+}
+
+// firstWorksheet returns the zip path of the first sheet listed in xl/workbook.xml, following its
+// relationship in xl/_rels/workbook.xml.rels. Targets are relative to xl/ unless they start with a slash.
+func firstWorksheet(archive *zip.Reader) (string, error) {
+	workbookXML, err := readZipFile(archive, "xl/workbook.xml")
+	if err != nil {
+		return "", err
+	}
+	relsXML, err := readZipFile(archive, "xl/_rels/workbook.xml.rels")
+	if err != nil {
+		return "", err
+	}
+	if workbookXML == nil || relsXML == nil {
+		return "", errors.New("xlsx workbook not found")
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Attrs []xml.Attr `xml:",any,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(workbookXML, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("xlsx worksheet not found")
+	}
+
+	id := ""
+	for _, attr := range workbook.Sheets[0].Attrs {
+		if attr.Name.Local == "id" && attr.Name.Space != "" {
+			id = attr.Value
+		}
+	}
+
+	var rels struct {
+		Items []struct {
+			Id     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(relsXML, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Items {
+		if rel.Id != id {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Clean("xl/" + rel.Target), nil
+	}
+
+	return "", errors.New("xlsx worksheet not found")
+}
+
+// readWorksheet returns the cells of the first worksheet, resolving shared and inline strings.
+func readWorksheet(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	shared := []string{}
+	sharedXML, err := readZipFile(archive, "xl/sharedStrings.xml")
+	if err != nil {
+		return nil, err
+	}
+	if sharedXML != nil {
+		var table struct {
+			Items []struct {
+				Text string `xml:"t"`
+				Runs []struct {
+					Text string `xml:"t"`
+				} `xml:"r"`
+			} `xml:"si"`
+		}
+		if err := xml.Unmarshal(sharedXML, &table); err != nil {
+			return nil, err
+		}
+		for _, item := range table.Items {
+			text := item.Text
+			for _, run := range item.Runs {
+				text += run.Text
+			}
+			shared = append(shared, text)
+		}
+	}
+
+	sheetName, err := firstWorksheet(archive)
+	if err != nil {
+		return nil, err
+	}
+	sheetXML, err := readZipFile(archive, sheetName)
+	if err != nil {
+		return nil, err
+	}
+	if sheetXML == nil {
+		return nil, errors.New("xlsx worksheet not found")
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Number int `xml:"r,attr"`
+			Cells  []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline struct {
+					Text string `xml:"t"`
+				} `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(sheetXML, &sheet); err != nil {
+		return nil, err
+	}
+
+	records := [][]string{}
+	for _, row := range sheet.Rows {
+		// Rows without cells are omitted from the sheet, so pad to keep row numbers aligned.
+		for row.Number > len(records)+1 {
+			records = append(records, []string{})
+		}
+
+		record := []string{}
+		for i, cell := range row.Cells {
+			column := cellColumn(cell.Ref)
+			if column < 0 {
+				column = i
+			}
+			for len(record) <= column {
+				record = append(record, "")
+			}
+
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(shared) {
+					return nil, fmt.Errorf("invalid shared string in cell %s", cell.Ref)
+				}
+				value = shared[index]
+			case "inlineStr":
+				value = cell.Inline.Text
+			}
+			record[column] = value
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// cellColumn converts the letters of a cell reference such as "AB12" to a zero-based column index.
+func cellColumn(ref string) int {
+	column := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+	}
+
+	return column - 1
+}
+
+// DecodeRow fills the *string, *int, *float64 and []*string fields of target from the values keyed by
+// each field's json name. Lists are split on ListSeparator.
+func DecodeRow(values map[string]string, target interface{}) error {
+	value := reflect.ValueOf(target).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		cell, ok := values[name]
+		if name == "" || !ok {
+			continue
+		}
+
+		switch field.Type.String() {
+		case "*string":
+			text := cell
+			value.Field(i).Set(reflect.ValueOf(&text))
+		case "*int":
+			number, err := strconv.Atoi(cell)
+			if err != nil {
+				return fmt.Errorf("%s must be a whole number", name)
+			}
+			value.Field(i).Set(reflect.ValueOf(&number))
+		case "*float64":
+			number, err := strconv.ParseFloat(strings.ReplaceAll(cell, ",", ""), 64)
+			if err != nil {
+				return fmt.Errorf("%s must be a number", name)
+			}
+			value.Field(i).Set(reflect.ValueOf(&number))
+		case "[]*string":
+			list := []*string{}
+			for _, item := range strings.Split(cell, ListSeparator) {
+				item := strings.TrimSpace(item)
+				if item != "" {
+					list = append(list, &item)
+				}
+			}
+			value.Field(i).Set(reflect.ValueOf(list))
+		}
+	}
+
+	return nil
+}
+
+// TableWriter streams rows to a spreadsheet; the first row written is treated as the header.
+type TableWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+func cellText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type csvTableWriter struct {
+	writer *csv.Writer
+	rows   int
+}
+
+func NewCSVWriter(w io.Writer) TableWriter {
+	return &csvTableWriter{writer: csv.NewWriter(w)}
+}
+
+// csvText is cellText for CSV, where spreadsheets run text starting with =, +, - or @ as a formula. Such text
+// is prefixed with an apostrophe; numbers are written as they are.
+func csvText(value interface{}) string {
+	text := cellText(value)
+	switch value.(type) {
+	case int, int32, int64, float64:
+		return text
+	}
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+
+	return text
+}
+
+func (t *csvTableWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = csvText(value)
+	}
+	if err := t.writer.Write(record); err != nil {
+		return err
+	}
+
+	t.rows++
+	if t.rows%500 == 0 {
+		t.writer.Flush()
+	}
+
+	return t.writer.Error()
+}
+
+func (t *csvTableWriter) Close() error {
+	t.writer.Flush()
+	return t.writer.Error()
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs></styleSheet>`
+
+// xlsxTableWriter writes a single-sheet workbook. The small parts are written first so the worksheet can be
+// streamed row by row into the zip with inline strings.
+type xlsxTableWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	rows    int
+}
+
+func NewXLSXWriter(w io.Writer, sheetName string) (TableWriter, error) {
+	archive := zip.NewWriter(w)
+	workbook := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`, xmlText(sheetName))
+
+	parts := []struct {
+		Name string
+		Body string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		pw, err := archive.Create(part.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(pw, part.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxTableWriter{archive: archive, sheet: sheet}, nil
+}
+
+// columnName converts a zero-based column index to its letters, e.g. 27 to "AB".
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+
+	return name
+}
+
+func (t *xlsxTableWriter) WriteRow(values []interface{}) error {
+	t.rows++
+	style := ""
+	if t.rows == 1 {
+		style = ` s="1"`
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<row r="%d">`, t.rows)
+	for i, value := range values {
+		ref := columnName(i) + strconv.Itoa(t.rows)
+		switch v := value.(type) {
+		case nil:
+			continue
+		case int, int32, int64, float64:
+			fmt.Fprintf(&sb, `<c r="%s"%s><v>%s</v></c>`, ref, style, cellText(v))
+		default:
+			text := cellText(v)
+			if text == "" {
+				continue
+			}
+			fmt.Fprintf(&sb, `<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">%s</t></is></c>`, ref, style, xmlText(text))
+		}
+	}
+	sb.WriteString("</row>")
+
+	_, err := io.WriteString(t.sheet, sb.String())
+
+	return err
+}
+
+func (t *xlsxTableWriter) Close() error {
+	if _, err := io.WriteString(t.sheet, "</sheetData></worksheet>"); err != nil {
+		return err
+	}
+
+	return t.archive.Close()
+}
@@ -0,0 +1,119 @@
+package helper
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRowList(t *testing.T) {
+	var row struct {
+		Name     *string   `json:"name"`
+		Standard []*string `json:"standard"`
+	}
+
+	values := map[string]string{
+		"name":     "Access control",
+		"standard": "A.5.15" + ListSeparator + " A.8.2 " + ListSeparator + ListSeparator + "A.8.3",
+	}
+	if err := DecodeRow(values, &row); err != nil {
+		t.Fatalf("DecodeRow: %v", err)
+	}
+
+	want := []string{"A.5.15", "A.8.2", "A.8.3"}
+	if len(row.Standard) != len(want) {
+		t.Fatalf("got %d list items, want %d", len(row.Standard), len(want))
+	}
+	for i, item := range row.Standard {
+		if *item != want[i] {
+			t.Errorf("item %d = %q, want %q", i, *item, want[i])
+		}
+	}
+}
+
+func TestReadTableFirstWorksheet(t *testing.T) {
+	parts := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Assets" sheetId="2" r:id="rId7"/><sheet name="Notes" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId7" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>notes</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml":   `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>Name</t></is></c></row><row r="2"><c r="A2" t="inlineStr"><is><t>Mail server</t></is></c></row></sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, body := range parts {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := ReadTable(buf.Bytes(), "assets.xlsx")
+	if err != nil {
+		t.Fatalf("ReadTable: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Values["name"] != "Mail server" {
+		t.Fatalf("got rows %v, want the Assets sheet", rows)
+	}
+}
+
+func TestCSVWriterEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewCSVWriter(&buf)
+	if err := writer.WriteRow([]interface{}{"=HYPERLINK(\"http://x\")", "+1", "-SUM(A1)", "@cmd", "plain", -5, -1.5, nil}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `"'=HYPERLINK(""http://x"")",'+1,'-SUM(A1),'@cmd,plain,-5,-1.5,` + "\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestXLSXWriterWritesInlineStrings(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewXLSXWriter(&buf, "vulnerabilities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteRow([]interface{}{"name", "score"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteRow([]interface{}{"=1+1", 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := readZipFile(archive, "xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sheet), "<f>") {
+		t.Errorf("worksheet contains a formula: %s", sheet)
+	}
+	if !strings.Contains(string(sheet), `t="inlineStr"`) || !strings.Contains(string(sheet), "=1+1</t>") {
+		t.Errorf("text was not written as an inline string: %s", sheet)
+	}
+
+	rows, err := ReadTable(buf.Bytes(), "export.xlsx")
+	if err != nil {
+		t.Fatalf("ReadTable: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Values["name"] != "=1+1" || rows[0].Values["score"] != "3" {
+		t.Errorf("got rows %v", rows)
+	}
+}
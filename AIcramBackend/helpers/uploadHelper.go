@@ -0,0 +1,153 @@
+package helper
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const DefaultMaxUploadSize int64 = 2 << 30
+
+var ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+
+// uploadLock serializes the requests on one upload. It stays in uploadLocks while anyone holds or waits for it,
+// so every concurrent request for an upload shares the same mutex.
+type uploadLock struct {
+	sync.Mutex
+	holders int
+}
+
+var uploadLocksMutex sync.Mutex
+var uploadLocks = map[string]*uploadLock{}
+
+func UploadDir() string {
+	dir := os.Getenv("UPLOAD_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "aicram-uploads")
+	}
+
+	return dir
+}
+
+func MaxUploadSize() int64 {
+	size, err := strconv.ParseInt(os.Getenv("UPLOAD_MAX_SIZE"), 10, 64)
+	if err != nil || size < 1 {
+		return DefaultMaxUploadSize
+	}
+
+	return size
+}
+
+func UploadPath(uploadId string) string {
+	return filepath.Join(UploadDir(), filepath.Base(uploadId)+".part")
+}
+
+func LockUpload(uploadId string) func() {
+	uploadLocksMutex.Lock()
+	lock := uploadLocks[uploadId]
+	if lock == nil {
+		lock = &uploadLock{}
+		uploadLocks[uploadId] = lock
+	}
+	lock.holders++
+	uploadLocksMutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		uploadLocksMutex.Lock()
+		if lock.holders--; lock.holders == 0 {
+			delete(uploadLocks, uploadId)
+		}
+		uploadLocksMutex.Unlock()
+	}
+}
+
+func CreateUploadPart(uploadId string) error {
+	if err := os.MkdirAll(UploadDir(), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(UploadPath(uploadId), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// AppendUploadPart writes at most limit bytes from r at offset, which must match the bytes already staged.
+func AppendUploadPart(uploadId string, offset int64, r io.Reader, limit int64) (int64, error) {
+	f, err := os.OpenFile(UploadPath(uploadId), os.O_WRONLY, 0o600)
+	if err != nil {
+		return offset, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return offset, err
+	}
+	if info.Size() != offset {
+		return info.Size(), ErrUploadOffsetMismatch
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	written, err := io.Copy(f, io.LimitReader(r, limit))
+	if syncErr := f.Sync(); err == nil {
+		err = syncErr
+	}
+
+	// Bytes that reached the disk still count, so an interrupted chunk can be resumed from its end.
+	return offset + written, err
+}
+
+func OpenUploadPart(uploadId string) (*os.File, error) {
+	return os.Open(UploadPath(uploadId))
+}
+
+func RemoveUploadPart(uploadId string) error {
+	err := os.Remove(UploadPath(uploadId))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func RemoveStaleUploadParts(olderThan time.Duration) (int, error) {
+	entries, err := os.ReadDir(UploadDir())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-olderThan)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".part" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(UploadDir(), entry.Name())); err == nil {
+			removed++
+		}
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,38 @@
+package helper
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// STIXNamespace is the UUIDv5 namespace STIX 2.1 defines for deterministic identifiers.
+	STIXNamespace = "00abedb4-aa42-466c-9c01-fed23315a9b7"
+	// URLNamespace is the RFC 4122 namespace for names that are URLs or URNs.
+	URLNamespace = "6ba7b811-9dad-11d1-80b4-00c04fd430c8"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsUUID reports whether value is a UUID in the canonical 8-4-4-4-12 form.
+func IsUUID(value string) bool {
+	return uuidPattern.MatchString(value)
+}
+
+// NameUUID returns the RFC 4122 version 5 UUID of name within namespace, so the same input always yields the
+// same identifier and exported documents stay stable across downloads.
+func NameUUID(namespace string, name string) string {
+	space, _ := hex.DecodeString(strings.ReplaceAll(namespace, "-", ""))
+
+	hash := sha1.New()
+	hash.Write(space)
+	hash.Write([]byte(name))
+	sum := hash.Sum(nil)[:16]
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"os"
+	"time"
+	"user-athentication-golang/controllers"
 	"user-athentication-golang/routes"
 
 	"github.com/gin-contrib/cors"
@@ -22,12 +24,26 @@ func main() {
 
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:4440"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "token"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "HEAD", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "token", "Upload-Offset"},
+		ExposeHeaders:    []string{"Content-Length", "Location", "Upload-Offset", "Upload-Length", "Upload-Expires"},
 		AllowCredentials: true,
 	}))
 
+	controllers.StartUploadCleanup(10 * time.Minute)
+	controllers.EnsureMatrixVersionIndex()
+	controllers.EnsureCVEIndex()
+	controllers.EnsureAttackIndex()
+	controllers.MigrateMatrixLevels()
+	controllers.RecordMatrixVersions()
+	controllers.MigrateOrganizationAssets()
+	controllers.SeedMatrixTemplates()
+	controllers.SeedRegulations()
+	controllers.SeedControlCatalog()
+	controllers.SeedReportTemplates()
+	controllers.EnsureRiskSnapshotIndex()
+	controllers.StartRiskSnapshots(time.Hour)
+
 	routes.AuthRoutes(router)
 	routes.UserRoutes(router)
 
@@ -13,10 +13,13 @@ type Assessment struct {
 	Name            *string            `json:"name" validate:"required,min=2,max=100"`
 	Status          *int               `json:"status" validate:"required,eq=1|eq=2"`
 	Matrix_id       *string            `json:"matrix_id"`
+	Matrix_version  *int               `json:"matrix_version"`
 	Organization_id *string            `json:"organization_id"`
 	Situation       *string            `json:"situation" validate:"required"`
 	Asset           []*string          `json:"asset"`
+	Asset_id        []*string          `json:"asset_id"`
 	Threat          []*string          `json:"threat"`
+	File            []*string          `json:"file"`
 	Constraint      *string            `json:"constraint"`
 	Created_at      time.Time          `json:"created_at"`
 	Updated_at      time.Time          `json:"updated_at"`
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Asset struct {
+	ID              primitive.ObjectID `bson:"_id"`
+	Asset_id        string             `json:"asset_id"`
+	User_id         *string            `json:"user_id"`
+	Organization_id *string            `json:"organization_id" validate:"required"`
+	Name            *string            `json:"name" validate:"required,min=2,max=100"`
+	Status          *int               `json:"status" validate:"required,eq=1|eq=2"`
+	Type            *string            `json:"type" validate:"required,oneof=data system process people"`
+	Description     *string            `json:"description" validate:"max=1000"`
+	Owner           *string            `json:"owner" validate:"max=100"`
+	Location        *string            `json:"location" validate:"max=200"`
+	Value           *float64           `json:"value" validate:"omitempty,min=0"`
+	Criticality     *int               `json:"criticality" validate:"omitempty,eq=1|eq=2|eq=3|eq=4|eq=5"`
+	Confidentiality *int               `json:"confidentiality" validate:"omitempty,eq=1|eq=2|eq=3"`
+	Integrity       *int               `json:"integrity" validate:"omitempty,eq=1|eq=2|eq=3"`
+	Availability    *int               `json:"availability" validate:"omitempty,eq=1|eq=2|eq=3"`
+	Dependency      []*string          `json:"dependency" validate:"max=100"`
+	Tag             []*string          `json:"tag" validate:"max=50,dive,required,max=50"`
+	CPE             []*string          `json:"cpe" validate:"max=50,dive,required,max=500"`
+	Version         *string            `json:"version" validate:"max=100"`
+	Created_at      time.Time          `json:"created_at"`
+	Updated_at      time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Attack struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Attack_id   string             `json:"attack_id"`
+	Type        *string            `json:"type"`
+	Name        *string            `json:"name"`
+	Description *string            `json:"description"`
+	Url         *string            `json:"url"`
+	Shortname   *string            `json:"shortname"`
+	Parent      *string            `json:"parent"`
+	Tactic      []*string          `json:"tactic"`
+	Mitigation  []*string          `json:"mitigation"`
+	Platform    []*string          `json:"platform"`
+	Deprecated  *bool              `json:"deprecated"`
+	Version     *string            `json:"version"`
+	Created_at  time.Time          `json:"created_at"`
+	Updated_at  time.Time          `json:"updated_at"`
+}
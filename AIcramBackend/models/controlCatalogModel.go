@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CatalogControl struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Control_id  string             `json:"control_id"`
+	Framework   *string            `json:"framework"`
+	Code        *string            `json:"code"`
+	Title       *string            `json:"title"`
+	Family      *string            `json:"family"`
+	Parent      *string            `json:"parent"`
+	Description *string            `json:"description"`
+	Withdrawn   *bool              `json:"withdrawn"`
+	Mapping     []*string          `json:"mapping"`
+	Version     *string            `json:"version"`
+	Created_at  time.Time          `json:"created_at"`
+	Updated_at  time.Time          `json:"updated_at"`
+}
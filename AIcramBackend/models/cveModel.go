@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CVSS struct {
+	Version  *string  `json:"version"`
+	Vector   *string  `json:"vector"`
+	Score    *float64 `json:"score"`
+	Severity *string  `json:"severity"`
+}
+
+type CPEMatch struct {
+	Criteria                *string `json:"criteria"`
+	Vulnerable              *bool   `json:"vulnerable"`
+	Version_start_including *string `json:"version_start_including"`
+	Version_start_excluding *string `json:"version_start_excluding"`
+	Version_end_including   *string `json:"version_end_including"`
+	Version_end_excluding   *string `json:"version_end_excluding"`
+}
+
+type CVE struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Cve_id        string             `json:"cve_id"`
+	Status        *string            `json:"status"`
+	Description   *string            `json:"description"`
+	CVSS          *CVSS              `json:"cvss"`
+	CWE           []*string          `json:"cwe"`
+	CPE           []*CPEMatch        `json:"cpe"`
+	Published     time.Time          `json:"published"`
+	Last_modified time.Time          `json:"last_modified"`
+	Created_at    time.Time          `json:"created_at"`
+	Updated_at    time.Time          `json:"updated_at"`
+}
@@ -6,14 +6,24 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type FileVariant struct {
+	Size      int    `json:"size"`
+	Cloud_url string `json:"cloud_url"`
+	Cloud_id  string `json:"cloud_id"`
+	Bytes     int64  `json:"bytes"`
+}
+
 type File struct {
 	ID            primitive.ObjectID `bson:"_id"`
 	File_id       string             `json:"file_id"`
+	User_id       *string            `json:"user_id"`
 	Original_name string             `json:"original_name"`
 	Cloud_url     string             `json:"cloud_url"`
 	Cloud_id      string             `json:"cloud_id"`
 	File_type     string             `json:"file_type"`
 	Size          int64              `json:"size"`
+	Text          *string            `json:"text"`
+	Variant       []*FileVariant     `json:"variant"`
 	Created_at    time.Time          `json:"created_at"`
 	Updated_at    time.Time          `json:"updated_at"`
 }
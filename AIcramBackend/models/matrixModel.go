@@ -6,24 +6,32 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Level is one step of a matrix axis. Value is the score stored on vulnerabilities; Min and Max are optional
+// numeric bounds, which quantitative matrices use for annual frequency (likelihood) and loss fraction (impact).
+type Level struct {
+	Value       *int     `json:"value" validate:"omitempty,min=1,max=100"`
+	Label       *string  `json:"label" validate:"required,min=1,max=100"`
+	Description *string  `json:"description" validate:"max=1000"`
+	Min         *float64 `json:"min" validate:"omitempty,min=0"`
+	Max         *float64 `json:"max" validate:"omitempty,min=0"`
+}
+
+// Matrix is a risk matrix of any N x M size given by its Impact and Likelihood levels. Type 4 marks a quantitative
+// matrix; otherwise Type is derived from the levels for clients that still expect it: 1, 2 and 3 for square 3x3,
+// 4x4 and 5x5 matrices and empty for any other size.
 type Matrix struct {
-	ID           primitive.ObjectID `bson:"_id"`
-	Matrix_id    string             `json:"matrix_id"`
-	User_id      *string            `json:"user_id"`
-	Name         *string            `json:"name" validate:"required,min=2,max=100"`
-	Status       *int               `json:"status" validate:"required,eq=1|eq=2"`
-	Type         *int               `json:"type" validate:"required,eq=1|eq=2|eq=3"`
-	Description  *string            `json:"description" validate:"max=1000"`
-	Impact_1     *string            `json:"impact_1" validate:"max=1000"`
-	Impact_2     *string            `json:"impact_2" validate:"max=1000"`
-	Impact_3     *string            `json:"impact_3" validate:"max=1000"`
-	Impact_4     *string            `json:"impact_4" validate:"max=1000"`
-	Impact_5     *string            `json:"impact_5" validate:"max=1000"`
-	Likelihood_1 *string            `json:"likelihood_1" validate:"max=1000"`
-	Likelihood_2 *string            `json:"likelihood_2" validate:"max=1000"`
-	Likelihood_3 *string            `json:"likelihood_3" validate:"max=1000"`
-	Likelihood_4 *string            `json:"likelihood_4" validate:"max=1000"`
-	Likelihood_5 *string            `json:"likelihood_5" validate:"max=1000"`
-	Created_at   time.Time          `json:"created_at"`
-	Updated_at   time.Time          `json:"updated_at"`
+	ID          primitive.ObjectID `bson:"_id"`
+	Matrix_id   string             `json:"matrix_id"`
+	User_id     *string            `json:"user_id"`
+	Name        *string            `json:"name" validate:"required,min=2,max=100"`
+	Status      *int               `json:"status" validate:"required,eq=1|eq=2"`
+	Type        *int               `json:"type" validate:"omitempty,eq=1|eq=2|eq=3|eq=4"`
+	Description *string            `json:"description" validate:"max=1000"`
+	Impact      []*Level           `json:"impact" validate:"required,min=2,max=10,dive,required"`
+	Likelihood  []*Level           `json:"likelihood" validate:"required,min=2,max=10,dive,required"`
+	Template    *string            `json:"template"`
+	Cloned_from *string            `json:"cloned_from"`
+	Version     *int               `json:"version"`
+	Created_at  time.Time          `json:"created_at"`
+	Updated_at  time.Time          `json:"updated_at"`
 }
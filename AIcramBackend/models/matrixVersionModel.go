@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MatrixVersion struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Version_id string             `json:"version_id"`
+	Matrix_id  string             `json:"matrix_id"`
+	Version    int                `json:"version"`
+	User_id    *string            `json:"user_id"`
+	Matrix     *Matrix            `json:"matrix"`
+	Created_at time.Time          `json:"created_at"`
+}
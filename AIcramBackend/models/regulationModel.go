@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Requirement struct {
+	Requirement_id *string   `json:"requirement_id"`
+	Code           *string   `json:"code"`
+	Title          *string   `json:"title"`
+	Description    *string   `json:"description"`
+	NIST           []*string `json:"nist"`
+	ISO            []*string `json:"iso"`
+}
+
+type Regulation struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Regulation_id string             `json:"regulation_id"`
+	Name          *string            `json:"name"`
+	Version       *string            `json:"version"`
+	Description   *string            `json:"description"`
+	Requirement   []*Requirement     `json:"requirement"`
+	Created_at    time.Time          `json:"created_at"`
+	Updated_at    time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportTemplate is a Go text/template rendering a result as Markdown; DOCX exports are converted from that
+// Markdown. Built-in templates have a Key and no User_id.
+type ReportTemplate struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Template_id string             `json:"template_id"`
+	User_id     *string            `json:"user_id"`
+	Name        *string            `json:"name" validate:"required,min=2,max=100"`
+	Status      *int               `json:"status" validate:"required,eq=1|eq=2"`
+	Description *string            `json:"description" validate:"max=1000"`
+	Body        *string            `json:"body" validate:"required,max=100000"`
+	Key         *string            `json:"key"`
+	Created_at  time.Time          `json:"created_at"`
+	Updated_at  time.Time          `json:"updated_at"`
+}
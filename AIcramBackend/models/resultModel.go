@@ -11,6 +11,20 @@ type Content struct {
 	Vulnerability []*Vulnerability `json:"vulnerability"`
 	Summary       *string          `json:"summary"`
 	Message       *string          `json:"message"`
+	Loss          *Loss            `json:"loss"`
+	New_loss      *Loss            `json:"new_loss"`
+	Component     []*Component     `json:"component"`
+}
+
+type Component struct {
+	Asset_id    *string  `json:"asset_id"`
+	Name        *string  `json:"name"`
+	CPE         *string  `json:"cpe"`
+	Criteria    *string  `json:"criteria"`
+	Cve_id      *string  `json:"cve_id"`
+	Score       *float64 `json:"score"`
+	Severity    *string  `json:"severity"`
+	Description *string  `json:"description"`
 }
 
 type Vulnerability struct {
@@ -18,27 +32,53 @@ type Vulnerability struct {
 	Description    *string    `json:"description"`
 	CVE            []*string  `json:"cve"`
 	MITRE          []*string  `json:"mitre"`
-	Impact         *int       `json:"impact" validate:"eq=0|eq=1|eq=2|eq=3|eq=4|eq=5"`
-	Likelihood     *int       `json:"likelihood" validate:"eq=0|eq=1|eq=2|eq=3|eq=4|eq=5"`
-	New_impact     *int       `json:"new_impact" validate:"eq=0|eq=1|eq=2|eq=3|eq=4|eq=5"`
-	New_likelihood *int       `json:"new_likelihood" validate:"eq=0|eq=1|eq=2|eq=3|eq=4|eq=5"`
+	Asset_id       []*string  `json:"asset_id"`
+	Impact         *int       `json:"impact" validate:"min=0,max=100"`
+	Likelihood     *int       `json:"likelihood" validate:"min=0,max=100"`
+	New_impact     *int       `json:"new_impact" validate:"min=0,max=100"`
+	New_likelihood *int       `json:"new_likelihood" validate:"min=0,max=100"`
 	Control        []*Control `json:"control"`
+	Loss           *Loss      `json:"loss"`
+	New_loss       *Loss      `json:"new_loss"`
+}
+
+type Loss struct {
+	Iterations *int     `json:"iterations"`
+	Exposure   *float64 `json:"exposure"`
+	Mean       *float64 `json:"mean"`
+	P10        *float64 `json:"p10"`
+	P50        *float64 `json:"p50"`
+	P90        *float64 `json:"p90"`
+	P95        *float64 `json:"p95"`
+	P99        *float64 `json:"p99"`
 }
 
 type Control struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
-	NIST        *string `json:"nist"`
-	ISO         *string `json:"iso"`
+	Name        *string             `json:"name"`
+	Description *string             `json:"description"`
+	NIST        *string             `json:"nist"`
+	ISO         *string             `json:"iso"`
+	Requirement []*string           `json:"requirement"`
+	Reference   []*ControlReference `json:"reference"`
+}
+
+type ControlReference struct {
+	Control_id *string `json:"control_id"`
+	Framework  *string `json:"framework"`
+	Code       *string `json:"code"`
+	Title      *string `json:"title"`
+	Verified   *bool   `json:"verified"`
 }
 
 type Result struct {
-	ID            primitive.ObjectID `bson:"_id"`
-	Result_id     string             `json:"result_id"`
-	User_id       *string            `json:"user_id"`
-	Status        *int               `json:"status" validate:"required,eq=1|eq=2|eq=3"`
-	Assessment_id *string            `json:"assessment_id"`
-	Content       *Content           `json:"content"`
-	Created_at    time.Time          `json:"created_at"`
-	Updated_at    time.Time          `json:"updated_at"`
+	ID             primitive.ObjectID `bson:"_id"`
+	Result_id      string             `json:"result_id"`
+	User_id        *string            `json:"user_id"`
+	Status         *int               `json:"status" validate:"required,eq=1|eq=2|eq=3"`
+	Assessment_id  *string            `json:"assessment_id"`
+	Matrix_id      *string            `json:"matrix_id"`
+	Matrix_version *int               `json:"matrix_version"`
+	Content        *Content           `json:"content"`
+	Created_at     time.Time          `json:"created_at"`
+	Updated_at     time.Time          `json:"updated_at"`
 }
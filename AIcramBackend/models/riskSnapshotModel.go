@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RiskLevelCount struct {
+	Low      int `json:"low"`
+	Medium   int `json:"medium"`
+	High     int `json:"high"`
+	Critical int `json:"critical"`
+	Unrated  int `json:"unrated"`
+}
+
+type TopRisk struct {
+	Result_id      string  `json:"result_id"`
+	Assessment_id  *string `json:"assessment_id"`
+	Name           *string `json:"name"`
+	Inherent_score *int    `json:"inherent_score"`
+	Residual_score *int    `json:"residual_score"`
+	Inherent_level string  `json:"inherent_level"`
+	Residual_level string  `json:"residual_level"`
+}
+
+// RiskSnapshot is the risk of an organization, or of one of its assessments when Assessment_id is set, on Date
+// (YYYY-MM-DD, UTC).
+type RiskSnapshot struct {
+	ID                  primitive.ObjectID `bson:"_id"`
+	Snapshot_id         string             `json:"snapshot_id"`
+	Organization_id     string             `json:"organization_id"`
+	Assessment_id       *string            `json:"assessment_id"`
+	Date                string             `json:"date"`
+	Result_id           []*string          `json:"result_id"`
+	Vulnerability_count int                `json:"vulnerability_count"`
+	Inherent_score      int                `json:"inherent_score"`
+	Residual_score      int                `json:"residual_score"`
+	Inherent_level      *RiskLevelCount    `json:"inherent_level"`
+	Residual_level      *RiskLevelCount    `json:"residual_level"`
+	Top_risk            []*TopRisk         `json:"top_risk"`
+	Created_at          time.Time          `json:"created_at"`
+	Updated_at          time.Time          `json:"updated_at"`
+}
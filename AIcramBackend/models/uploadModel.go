@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Upload struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Upload_id     string             `json:"upload_id"`
+	User_id       *string            `json:"user_id"`
+	Original_name *string            `json:"original_name" validate:"required,min=1,max=255"`
+	File_type     *string            `json:"file_type" validate:"max=255"`
+	Size          *int64             `json:"size" validate:"required,min=1"`
+	Offset        int64              `json:"offset"`
+	Status        *int               `json:"status" validate:"omitempty,eq=1|eq=2"`
+	File_id       *string            `json:"file_id"`
+	Expires_at    time.Time          `json:"expires_at"`
+	Created_at    time.Time          `json:"created_at"`
+	Updated_at    time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Usage struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	User_id    *string            `json:"user_id"`
+	Bytes      int64              `json:"bytes"`
+	File_count int64              `json:"file_count"`
+	Quota      *int64             `json:"quota" validate:"omitempty,min=0"`
+	Created_at time.Time          `json:"created_at"`
+	Updated_at time.Time          `json:"updated_at"`
+}
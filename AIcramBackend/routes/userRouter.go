@@ -22,11 +22,27 @@ func UserRoutes(incomingRoutes *gin.Engine) {
 	incomingRoutes.DELETE("/users/:user_id", controller.DeleteUser())
 	incomingRoutes.GET("/users/username", controller.GetUsernameByID())
 	incomingRoutes.PUT("/users/:user_id/password", controllers.UpdatePassword())
+	incomingRoutes.PUT("/users/:user_id/avatar", controller.UpdateAvatar())
+	incomingRoutes.DELETE("/users/:user_id/avatar", controller.DeleteAvatar())
+	incomingRoutes.GET("/users/:user_id/usage", controller.GetUsage())
+	incomingRoutes.PUT("/users/:user_id/quota", controller.UpdateQuota())
 
 	incomingRoutes.POST("/upload", controllers.UploadFile())
 	incomingRoutes.GET("/files", controller.GetFiles())
 	incomingRoutes.GET("/files/:file_id", controllers.GetFile())
 	incomingRoutes.DELETE("/files/:file_id", controller.DeleteFile())
+	incomingRoutes.POST("/files/:file_id/extract", controller.ExtractFile())
+
+	incomingRoutes.GET("/usage", controller.GetUsageReport())
+	incomingRoutes.POST("/usage/recalculate", controller.RecalculateUsage())
+	incomingRoutes.GET("/integrity", controller.CheckIntegrity())
+
+	incomingRoutes.POST("/uploads", controller.CreateUpload())
+	incomingRoutes.HEAD("/uploads/:upload_id", controller.GetUploadOffset())
+	incomingRoutes.GET("/uploads/:upload_id", controller.GetUpload())
+	incomingRoutes.PATCH("/uploads/:upload_id", controller.PatchUpload())
+	incomingRoutes.POST("/uploads/:upload_id/finalize", controller.FinalizeUpload())
+	incomingRoutes.DELETE("/uploads/:upload_id", controller.DeleteUpload())
 
 	incomingRoutes.GET("/matrices", controller.GetMatrices())
 	incomingRoutes.GET("/matrices/:matrix_id", controller.GetMatrix())
@@ -34,9 +50,14 @@ func UserRoutes(incomingRoutes *gin.Engine) {
 	incomingRoutes.PUT("/matrices/:matrix_id", controller.UpdateMatrix())
 	incomingRoutes.DELETE("/matrices/:matrix_id", controller.DeleteMatrix())
 	incomingRoutes.POST("/matrices/remove/:matrix_id", controller.RemoveMatrix())
+	incomingRoutes.POST("/matrices/:matrix_id/clone", controller.CloneMatrix())
+	incomingRoutes.GET("/matrices/:matrix_id/versions", controller.GetMatrixVersions())
+	incomingRoutes.GET("/matrices/:matrix_id/versions/:version", controller.GetMatrixVersion())
+	incomingRoutes.GET("/matrices/:matrix_id/diff", controller.DiffMatrixVersions())
 
 	incomingRoutes.GET("/assessments", controller.GetAssessments())
 	incomingRoutes.GET("/assessments/:assessment_id", controller.GetAssessment())
+	incomingRoutes.GET("/assessments/:assessment_id/risk-trend", controller.GetAssessmentRiskTrend())
 	incomingRoutes.POST("/assessments", controller.CreateAssessment())
 	incomingRoutes.PUT("/assessments/:assessment_id", controller.UpdateAssessment())
 	incomingRoutes.DELETE("/assessments/:assessment_id", controller.DeleteAssessment())
@@ -44,13 +65,53 @@ func UserRoutes(incomingRoutes *gin.Engine) {
 
 	incomingRoutes.GET("/organizations", controller.GetOrganizations())
 	incomingRoutes.GET("/organizations/:organization_id", controller.GetOrganization())
+	incomingRoutes.GET("/organizations/:organization_id/graph", controller.GetOrganizationGraph())
+	incomingRoutes.GET("/organizations/:organization_id/compliance", controller.GetOrganizationCompliance())
+	incomingRoutes.GET("/organizations/:organization_id/attack-layer", controller.GetOrganizationAttackLayer())
+	incomingRoutes.GET("/organizations/:organization_id/risk-trend", controller.GetOrganizationRiskTrend())
 	incomingRoutes.POST("/organizations", controller.CreateOrganization())
+	incomingRoutes.POST("/organizations/import", controller.ImportOrganizations())
 	incomingRoutes.PUT("/organizations/:organization_id", controller.UpdateOrganization())
 	incomingRoutes.DELETE("/organizations/:organization_id", controller.DeleteOrganization())
 	incomingRoutes.POST("/organizations/remove/:organization_id", controller.RemoveOrganization())
 
+	incomingRoutes.GET("/regulations", controller.GetRegulations())
+	incomingRoutes.GET("/regulations/:regulation_id", controller.GetRegulation())
+	incomingRoutes.GET("/controls", controller.GetControls())
+	incomingRoutes.GET("/cves/:cve_id", controller.GetCVE())
+
+	incomingRoutes.GET("/report-templates", controller.GetReportTemplates())
+	incomingRoutes.GET("/report-templates/:template_id", controller.GetReportTemplate())
+	incomingRoutes.POST("/report-templates", controller.CreateReportTemplate())
+	incomingRoutes.PUT("/report-templates/:template_id", controller.UpdateReportTemplate())
+	incomingRoutes.DELETE("/report-templates/:template_id", controller.DeleteReportTemplate())
+	incomingRoutes.POST("/report-templates/remove/:template_id", controller.RemoveReportTemplate())
+
+	incomingRoutes.GET("/assets", controller.GetAssets())
+	incomingRoutes.GET("/assets/:asset_id", controller.GetAsset())
+	incomingRoutes.GET("/assets/:asset_id/impact", controller.GetAssetImpact())
+	incomingRoutes.POST("/assets", controller.CreateAsset())
+	incomingRoutes.POST("/assets/import", controller.ImportAssets())
+	incomingRoutes.PUT("/assets/:asset_id", controller.UpdateAsset())
+	incomingRoutes.DELETE("/assets/:asset_id", controller.DeleteAsset())
+	incomingRoutes.POST("/assets/remove/:asset_id", controller.RemoveAsset())
+
 	incomingRoutes.GET("/results", controller.GetResults())
 	incomingRoutes.GET("/results/:result_id", controller.GetResult())
+	incomingRoutes.GET("/results/:result_id/matrix", controller.GetResultMatrix())
+	incomingRoutes.GET("/results/:result_id/impact", controller.GetResultImpact())
+	incomingRoutes.GET("/results/:result_id/requirements", controller.GetResultRequirements())
+	incomingRoutes.GET("/results/:result_id/cves", controller.GetResultCVEs())
+	incomingRoutes.GET("/results/:result_id/attack-layer", controller.GetResultAttackLayer())
+	incomingRoutes.GET("/results/:result_id/report/pdf", controller.GetResultPDF())
+	incomingRoutes.GET("/results/:result_id/export/:kind", controller.ExportResult())
+	incomingRoutes.GET("/results/:result_id/stix", controller.GetResultSTIX())
+	incomingRoutes.GET("/results/:result_id/oscal", controller.GetResultOSCAL())
+	incomingRoutes.GET("/results/export/:kind", controller.ExportResults())
+	incomingRoutes.GET("/results/compare", controller.CompareResults())
+	incomingRoutes.GET("/results/:result_id/report/docx", controller.GetResultDOCX())
+	incomingRoutes.GET("/results/:result_id/report/markdown", controller.GetResultMarkdown())
+	incomingRoutes.POST("/results/:result_id/quantify", controller.QuantifyResult())
 	incomingRoutes.POST("/results", controller.CreateResult())
 	incomingRoutes.PUT("/results/:result_id", controller.UpdateResult())
 	incomingRoutes.DELETE("/results/:result_id", controller.DeleteResult())
@@ -3,23 +3,37 @@ import { useNavigate, useParams } from 'react-router-dom';
 import { toast } from 'react-toastify';
 import config from '../../../config';
 
+interface MatrixLevel {
+  value?: number;
+  label: string;
+  description: string;
+  min?: number;
+  max?: number;
+}
+
+type Axis = 'impact' | 'likelihood';
+
 interface MatrixFormData {
   name: string;
   status: 1 | 2;
-  type: 1 | 2 | 3;
   description: string;
-  impact_1: string;
-  impact_2: string;
-  impact_3: string;
-  impact_4: string;
-  impact_5: string;
-  likelihood_1: string;
-  likelihood_2: string;
-  likelihood_3: string;
-  likelihood_4: string;
-  likelihood_5: string;
+  impact: MatrixLevel[];
+  likelihood: MatrixLevel[];
 }
 
+const toLevels = (levels: any[] | null | undefined): MatrixLevel[] =>
+  (levels || []).map(level => ({ ...level, label: level.label || '', description: level.description || '' }));
+
+// Levels added after existing ones continue their numbering so stored scores keep pointing at the same level.
+const numberLevels = (levels: MatrixLevel[]) => {
+  let previous = 0;
+  return levels.map(level => {
+    const value = level.value !== undefined && level.value !== null && level.value > previous ? level.value : previous + 1;
+    previous = value;
+    return { ...level, value };
+  });
+};
+
 const MatrixEdit = () => {
   const { matrix_id } = useParams<{ matrix_id: string }>();
   const navigate = useNavigate();
@@ -29,18 +43,9 @@ const MatrixEdit = () => {
   const [formData, setFormData] = React.useState<MatrixFormData>({
     name: '',
     status: 1,
-    type: 1,
     description: '',
-    impact_1: '',
-    impact_2: '',
-    impact_3: '',
-    impact_4: '',
-    impact_5: '',
-    likelihood_1: '',
-    likelihood_2: '',
-    likelihood_3: '',
-    likelihood_4: '',
-    likelihood_5: ''
+    impact: [],
+    likelihood: []
   });
 
   const [errors, setErrors] = useState<{ [key: string]: string }>({});
@@ -57,9 +62,14 @@ const MatrixEdit = () => {
       newErrors.status = 'Status must be Active or Inactive';
     }
 
-    if (!formData.type || (formData.type !== 1 && formData.type !== 2 && formData.type !== 3)) {
-      newErrors.type = 'Type must be New or Used';
-    }
+    (['impact', 'likelihood'] as Axis[]).forEach(axis => {
+      const levels = formData[axis];
+      if (levels.length < 2 || levels.length > 10) {
+        newErrors[axis] = 'A matrix needs 2-10 levels per axis';
+      } else if (levels.some(level => !level.label.trim())) {
+        newErrors[axis] = 'Every level needs a label';
+      }
+    });
 
     setErrors(newErrors);
     return Object.keys(newErrors).length === 0;
@@ -81,18 +91,9 @@ const MatrixEdit = () => {
           setFormData({
             name: data.name,
             status: data.status,
-            type: data.type,
             description: data.description,
-            impact_1: data.impact_1,
-            impact_2: data.impact_2,
-            impact_3: data.impact_3,
-            impact_4: data.impact_4,
-            impact_5: data.impact_5,
-            likelihood_1: data.likelihood_1,
-            likelihood_2: data.likelihood_2,
-            likelihood_3: data.likelihood_3,
-            likelihood_4: data.likelihood_4,
-            likelihood_5: data.likelihood_5
+            impact: toLevels(data.impact),
+            likelihood: toLevels(data.likelihood)
           });
 
         } catch (err) {
@@ -109,34 +110,10 @@ const MatrixEdit = () => {
 
     if (!validateForm()) return;
 
-    var dataImpact1;
-    var dataImpact5;
-    var dataLikelihood1;
-    var dataLikelihood5;
-
-    if (formData.type === 1) {
-      dataImpact1 = '';
-      dataImpact5 = '';
-      dataLikelihood1 = '';
-      dataLikelihood5 = '';
-    } else if (formData.type === 2) {
-      dataImpact1 = '';
-      dataImpact5 = formData.impact_5;
-      dataLikelihood1 = '';
-      dataLikelihood5 = formData.likelihood_5;
-    } else {
-      dataImpact1 = formData.impact_1;
-      dataImpact5 = formData.impact_5;
-      dataLikelihood1 = formData.likelihood_1;
-      dataLikelihood5 = formData.likelihood_5;
-    }
-
     const dataToSubmit = {
       ...formData,
-      impact_1: dataImpact1,
-      impact_5: dataImpact5,
-      likelihood_1: dataLikelihood1,
-      likelihood_5: dataLikelihood5
+      impact: numberLevels(formData.impact),
+      likelihood: numberLevels(formData.likelihood)
     };
 
     try {
@@ -171,6 +148,60 @@ const MatrixEdit = () => {
     return <div className="p-6">Loading...</div>;
   }
 
+  const updateLevel = (axis: Axis, index: number, changes: Partial<MatrixLevel>) => {
+    setFormData({
+      ...formData,
+      [axis]: formData[axis].map((level, i) => (i === index ? { ...level, ...changes } : level))
+    });
+  };
+
+  const renderLevels = (axis: Axis) => {
+    const title = axis === 'impact' ? 'Impact' : 'Likelihood';
+    return (
+      <div key={axis}>
+        <label className="block mb-2">{title} levels (lowest first)</label>
+        <div className="space-y-3">
+          {formData[axis].map((level, index) => (
+            <div key={index} className="border p-3 rounded space-y-2">
+              <div className="flex gap-2">
+                <input
+                  type="text"
+                  value={level.label}
+                  placeholder="Label"
+                  onChange={e => updateLevel(axis, index, { label: e.target.value })}
+                  className="w-full border p-2 rounded"
+                />
+                <button
+                  type="button"
+                  disabled={formData[axis].length <= 2}
+                  onClick={() => setFormData({ ...formData, [axis]: formData[axis].filter((_, i) => i !== index) })}
+                  className="bg-red-500 text-white px-4 py-2 rounded hover:bg-red-600 disabled:opacity-50"
+                >
+                  Remove
+                </button>
+              </div>
+              <textarea
+                value={level.description}
+                placeholder="Description"
+                onChange={e => updateLevel(axis, index, { description: e.target.value })}
+                className="w-full border p-2 rounded h-20 resize-none"
+              />
+            </div>
+          ))}
+        </div>
+        <button
+          type="button"
+          disabled={formData[axis].length >= 10}
+          onClick={() => setFormData({ ...formData, [axis]: [...formData[axis], { label: '', description: '' }] })}
+          className="bg-blue-500 text-white px-4 py-2 mt-3 rounded hover:bg-blue-600 disabled:opacity-50"
+        >
+          Add {title} Level
+        </button>
+        {errors[axis] && <p className="text-red-500 text-sm mt-2">{errors[axis]}</p>}
+      </div>
+    );
+  };
+
   return (
     <>
       <div className="rounded-sm border border-stroke bg-white px-8 py-6 max-w-full mx-auto">
@@ -203,20 +234,6 @@ const MatrixEdit = () => {
             {errors.status && <p className="text-red-500 text-sm mt-2">{errors.status}</p>}
           </div>
 
-          <div>
-            <label className="block mb-2">Type</label>
-            <select
-                value={formData.type}
-                onChange={e => setFormData({ ...formData, type: Number(e.target.value) as 1 | 2 | 3 })}
-                className="w-full border p-2 rounded"
-            >
-                <option value="1">3x3</option>
-                <option value="2">4x4</option>
-                <option value="3">5x5</option>
-            </select>
-            {errors.type && <p className="text-red-500 text-sm mt-2">{errors.type}</p>}
-          </div>
-
           <div>
             <label className="block mb-2">Description</label>
             <textarea
@@ -227,113 +244,7 @@ const MatrixEdit = () => {
             {errors.description && <p className="text-red-500 text-sm mt-2">{errors.description}</p>}
           </div>
           
-          {formData.type === 3 && (
-          <div>
-            <label className="block mb-2">Impact - Very Low</label>
-            <textarea
-              value={formData.impact_1}
-              onChange={e => setFormData({ ...formData, impact_1: e.target.value })}
-              className="w-full border p-2 rounded h-32 resize-none"
-            />
-            {errors.impact_1 && <p className="text-red-500 text-sm mt-2">{errors.impact_1}</p>}
-          </div>
-          )}
-
-          <div>
-            <label className="block mb-2">Impact - Low</label>
-            <textarea
-              value={formData.impact_2}
-              onChange={e => setFormData({ ...formData, impact_2: e.target.value })}
-              className="w-full border p-2 rounded h-32 resize-none"
-            />
-            {errors.impact_2 && <p className="text-red-500 text-sm mt-2">{errors.impact_2}</p>}
-          </div>
-
-          <div>
-            <label className="block mb-2">Impact - Medium</label>
-            <textarea
-              value={formData.impact_3}
-              onChange={e => setFormData({ ...formData, impact_3: e.target.value })}
-              className="w-full border p-2 rounded h-32 resize-none"
-            />
-            {errors.impact_3 && <p className="text-red-500 text-sm mt-2">{errors.impact_3}</p>}
-          </div>
-
-          <div>
-            <label className="block mb-2">Impact - High</label>
-            <textarea
-              value={formData.impact_4}
-              onChange={e => setFormData({ ...formData, impact_4: e.target.value })}
-              className="w-full border p-2 rounded h-32 resize-none"
-            />
-            {errors.impact_4 && <p className="text-red-500 text-sm mt-2">{errors.impact_4}</p>}
-          </div>
-
-          {(formData.type === 2 || formData.type === 3) && (
-          <div>
-            <label className="block mb-2">Impact - Extreme</label>
-            <textarea
-              value={formData.impact_5}
-              onChange={e => setFormData({ ...formData, impact_5: e.target.value })}
-              className="w-full border p-2 rounded h-32 resize-none"
-            />
-            {errors.impact_5 && <p className="text-red-500 text-sm mt-2">{errors.impact_5}</p>}
-          </div>
-          )}
-
-          {formData.type === 3 && (
-          <div>
-            <label className="block mb-2">Likelihood - Very Low</label>
-            <textarea
-              value={formData.likelihood_1}
-              onChange={e => setFormData({ ...formData, likelihood_1: e.target.value })}
-              className="w-full border p-2 rounded h-32 resize-none"
-            />
-            {errors.likelihood_1 && <p className="text-red-500 text-sm mt-2">{errors.likelihood_1}</p>}
-          </div>
-          )}
-
-          <div>
-            <label className="block mb-2">Likelihood - Low</label>
-            <textarea
-              value={formData.likelihood_2}
-              onChange={e => setFormData({ ...formData, likelihood_2: e.target.value })}
-              className="w-full border p-2 rounded h-32 resize-none"
-            />
-            {errors.likelihood_2 && <p className="text-red-500 text-sm mt-2">{errors.likelihood_2}</p>}
-          </div>
-
-          <div>
-            <label className="block mb-2">Likelihood - Medium</label>
-            <textarea
-              value={formData.likelihood_3}
-              onChange={e => setFormData({ ...formData, likelihood_3: e.target.value })}
-              className="w-full border p-2 rounded h-32 resize-none"
-            />
-            {errors.likelihood_3 && <p className="text-red-500 text-sm mt-2">{errors.likelihood_3}</p>}
-          </div>
-
-          <div>
-            <label className="block mb-2">Likelihood - High</label>
-            <textarea
-              value={formData.likelihood_4}
-              onChange={e => setFormData({ ...formData, likelihood_4: e.target.value })}
-              className="w-full border p-2 rounded h-32 resize-none"
-            />
-            {errors.likelihood_4 && <p className="text-red-500 text-sm mt-2">{errors.likelihood_4}</p>}
-          </div>
-
-          {(formData.type === 2 || formData.type === 3) && (
-          <div>
-            <label className="block mb-2">Likelihood - Extreme</label>
-            <textarea
-              value={formData.likelihood_5}
-              onChange={e => setFormData({ ...formData, likelihood_5: e.target.value })}
-              className="w-full border p-2 rounded h-32 resize-none"
-            />
-            {errors.likelihood_5 && <p className="text-red-500 text-sm mt-2">{errors.likelihood_5}</p>}
-          </div>
-          )}
+          {(['impact', 'likelihood'] as Axis[]).map(axis => renderLevels(axis))}
 
           <div className="flex gap-4">
             <button
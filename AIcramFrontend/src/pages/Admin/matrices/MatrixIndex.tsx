@@ -8,7 +8,9 @@ interface Matrix {
   user_id: string;
   name: string;
   status: 1 | 2;
-  type: 1 | 2 | 3;
+  type: number | null;
+  impact: object[] | null;
+  likelihood: object[] | null;
 }
 
 interface PaginatedResponse {
@@ -219,13 +221,8 @@ const MatrixIndex = () => {
                   ) : null}
                 </td>
                 <td className="border-b border-[#eee] py-5 px-4">
-                  {matrix.type === 1 ? (
-                    <span>3x3</span>
-                  ) : matrix.type === 2 ? (
-                    <span>4x4</span>
-                  ) : matrix.type === 3 ? (
-                    <span>5x5</span>
-                  ) : null}
+                  <span>{matrix.likelihood?.length || 0}x{matrix.impact?.length || 0}</span>
+                  {matrix.type === 4 && <span> (Quantitative)</span>}
                 </td>
                 <td className="border-b border-[#eee] py-5 px-4">
                   <div className="flex gap-2">
@@ -4,23 +4,23 @@ import { format, formatDistanceToNow } from "date-fns";
 import { enUS } from "date-fns/locale";
 import config from '../../../config';
 
+interface MatrixLevel {
+  value: number;
+  label: string;
+  description: string | null;
+  min?: number | null;
+  max?: number | null;
+}
+
 interface Matrix {
   matrix_id: string;
   user_id: string;
   name: string;
   status: 1 | 2;
-  type: 1 | 2 | 3;
+  type: number | null;
   description: string;
-  impact_1: string;
-  impact_2: string;
-  impact_3: string;
-  impact_4: string;
-  impact_5: string;
-  likelihood_1: string;
-  likelihood_2: string;
-  likelihood_3: string;
-  likelihood_4: string;
-  likelihood_5: string;
+  impact: MatrixLevel[];
+  likelihood: MatrixLevel[];
   created_at: string;
   updated_at: string;
 }
@@ -142,59 +142,24 @@ const MatrixView = () => {
           <div>
             <p className="font-medium text-gray-600 mb-1">Type</p>
             <p>
-              {matrix.type === 1 ? (
-                <span>3x3</span>
-              ) : matrix.type === 2 ? (
-                <span>4x4</span>
-              ) : matrix.type === 3 ? (
-                <span>5x5</span>
-              ) : null}
+              {matrix.likelihood?.length || 0}x{matrix.impact?.length || 0}
+              {matrix.type === 4 && <span> (Quantitative)</span>}
             </p>
           </div>
           <div>
             <p className="font-medium text-gray-600 mb-1">Description</p>
             <p className="whitespace-pre-wrap">{matrix.description}</p>
           </div>
-          <div>
-            <p className="font-medium text-gray-600 mb-1">Impact - Very Low</p>
-            <p className="whitespace-pre-wrap">{matrix.impact_1}</p>
-          </div>
-          <div>
-            <p className="font-medium text-gray-600 mb-1">Impact - Low</p>
-            <p className="whitespace-pre-wrap">{matrix.impact_2}</p>
-          </div>
-          <div>
-            <p className="font-medium text-gray-600 mb-1">Impact - Medium</p>
-            <p className="whitespace-pre-wrap">{matrix.impact_3}</p>
-          </div>
-          <div>
-            <p className="font-medium text-gray-600 mb-1">Impact - High</p>
-            <p className="whitespace-pre-wrap">{matrix.impact_4}</p>
-          </div>
-          <div>
-            <p className="font-medium text-gray-600 mb-1">Impact - Extreme</p>
-            <p className="whitespace-pre-wrap">{matrix.impact_5}</p>
-          </div>
-          <div>
-            <p className="font-medium text-gray-600 mb-1">Likelihood - Very Low</p>
-            <p className="whitespace-pre-wrap">{matrix.likelihood_1}</p>
-          </div>
-          <div>
-            <p className="font-medium text-gray-600 mb-1">Likelihood - Low</p>
-            <p className="whitespace-pre-wrap">{matrix.likelihood_2}</p>
-          </div>
-          <div>
-            <p className="font-medium text-gray-600 mb-1">Likelihood - Medium</p>
-            <p className="whitespace-pre-wrap">{matrix.likelihood_3}</p>
-          </div>
-          <div>
-            <p className="font-medium text-gray-600 mb-1">Likelihood - High</p>
-            <p className="whitespace-pre-wrap">{matrix.likelihood_4}</p>
-          </div>
-          <div>
-            <p className="font-medium text-gray-600 mb-1">Likelihood - Extreme</p>
-            <p className="whitespace-pre-wrap">{matrix.likelihood_5}</p>
-          </div>
+          {(['impact', 'likelihood'] as const).map(axis =>
+            (matrix[axis] || []).map(level => (
+              <div key={`${axis}-${level.value}`}>
+                <p className="font-medium text-gray-600 mb-1">
+                  {axis === 'impact' ? 'Impact' : 'Likelihood'} - {level.label} ({level.value})
+                </p>
+                <p className="whitespace-pre-wrap">{level.description}</p>
+              </div>
+            ))
+          )}
           <div>
             <p className="font-medium text-gray-600 mb-1">Created</p>
             <p>{formatDate(matrix.created_at)}</p>
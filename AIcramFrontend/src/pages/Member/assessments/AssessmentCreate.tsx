@@ -14,21 +14,19 @@ interface AssessmentFormData {
   constraint: string;
 }
 
+interface MatrixLevel {
+  value: number;
+  label: string;
+  description: string | null;
+}
+
 interface Matrix {
   matrix_id: string;
   name: string;
-  type: 1 | 2 | 3;
+  type: number | null;
   description: string;
-  impact_1: string;
-  impact_2: string;
-  impact_3: string;
-  impact_4: string;
-  impact_5: string;
-  likelihood_1: string;
-  likelihood_2: string;
-  likelihood_3: string;
-  likelihood_4: string;
-  likelihood_5: string;
+  impact: MatrixLevel[];
+  likelihood: MatrixLevel[];
 }
 
 interface Organization {
@@ -219,9 +217,10 @@ const AssessmentCreate = () => {
     fetchOrganizations();
   }, []);
 
-  const contentData = async () => {
+  const contentData = async (assessmentId: string) => {
     try {
       const contentData: any = {
+        assessment_id: assessmentId,
         situation: formData.situation,
         asset: formData.asset,
         threat: formData.threat,
@@ -232,46 +231,16 @@ const AssessmentCreate = () => {
         const selectedMatrix = matrices.find(m => m.matrix_id === formData.matrix_id);
         if (selectedMatrix) {
 
-          let matrixType;
-
-          if(selectedMatrix.type === 1) {
-            matrixType = "3x3";
-          } else if (selectedMatrix.type === 2) {
-            matrixType = "4x4";
-          } else if (selectedMatrix.type === 3) {
-            matrixType = "5x5";
-          }
+          const levels = (items: MatrixLevel[] | null) =>
+            (items || []).map(level => ({ value: level.value, label: level.label, description: level.description }));
 
           contentData.matrix = [{
             name: selectedMatrix.name,
             description: selectedMatrix.description,
-            type: matrixType
+            type: `${selectedMatrix.likelihood?.length || 0}x${selectedMatrix.impact?.length || 0}`,
+            impact: levels(selectedMatrix.impact),
+            likelihood: levels(selectedMatrix.likelihood)
           }];
-
-          if (selectedMatrix.type === 3) {
-            contentData.matrix[0].impact_very_low = selectedMatrix.impact_1;
-          }
-
-          contentData.matrix[0].impact_low = selectedMatrix.impact_2;
-          contentData.matrix[0].impact_medium = selectedMatrix.impact_3;
-          contentData.matrix[0].impact_high = selectedMatrix.impact_4;
-          
-          if (selectedMatrix.type === 2 || selectedMatrix.type === 3) {
-            contentData.matrix[0].impact_extreme = selectedMatrix.impact_5;
-          }
-
-          if (selectedMatrix.type === 3) {
-            contentData.matrix[0].likelihood_very_low = selectedMatrix.likelihood_1;
-          }
-
-          contentData.matrix[0].likelihood_low = selectedMatrix.likelihood_2;
-          contentData.matrix[0].likelihood_medium = selectedMatrix.likelihood_3;
-          contentData.matrix[0].likelihood_high = selectedMatrix.likelihood_4;
-
-          if (selectedMatrix.type === 2 || selectedMatrix.type === 3) {
-            contentData.matrix[0].likelihood_extreme = selectedMatrix.likelihood_5;
-          }
-
         }
       }
       
@@ -338,7 +307,7 @@ const AssessmentCreate = () => {
     try {
       const token = localStorage.getItem('token');
       
-      const contentResponse = await contentData();
+      const contentResponse = await contentData(assessmentId);
       
       let resultContent: ResultContent;
       
@@ -395,6 +364,11 @@ const AssessmentCreate = () => {
   };
 
   const validateResultContent = (content: any): content is ResultContent => {
+    // Scores are level values of the selected matrix; 0 means not rated.
+    const selectedMatrix = matrices.find(m => m.matrix_id === formData.matrix_id);
+    const levelValues = (items: MatrixLevel[] | null | undefined) => [0, ...(items || []).map(level => level.value)];
+    const impactValues = levelValues(selectedMatrix?.impact);
+    const likelihoodValues = levelValues(selectedMatrix?.likelihood);
 
     if (content.success === undefined || content.success === null || ![1, 2].includes(content.success)) {
       return false;
@@ -432,22 +406,22 @@ const AssessmentCreate = () => {
       }
       
       if (vul.impact === undefined || vul.impact === null || 
-          ![0, 1, 2, 3, 4, 5].includes(vul.impact)) {
+          !impactValues.includes(vul.impact)) {
         return false;
       }
       
       if (vul.likelihood === undefined || vul.likelihood === null || 
-          ![0, 1, 2, 3, 4, 5].includes(vul.likelihood)) {
+          !likelihoodValues.includes(vul.likelihood)) {
         return false;
       }
       
       if (vul.new_impact === undefined || vul.new_impact === null || 
-          ![0, 1, 2, 3, 4, 5].includes(vul.new_impact)) {
+          !impactValues.includes(vul.new_impact)) {
         return false;
       }
       
       if (vul.new_likelihood === undefined || vul.new_likelihood === null || 
-          ![0, 1, 2, 3, 4, 5].includes(vul.new_likelihood)) {
+          !likelihoodValues.includes(vul.new_likelihood)) {
         return false;
       }
       
@@ -896,13 +870,7 @@ const AssessmentCreate = () => {
                         <div>
                           <h3 className="font-medium text-white mb-1.5">{matrix.name}</h3>
                           <p className="text-sm text-gray-400">
-                            {matrix.type === 1 ? (
-                              <span>3x3</span>
-                            ) : matrix.type === 2 ? (
-                              <span>4x4</span>
-                            ) : matrix.type === 3 ? (
-                              <span>5x5</span>
-                            ) : null}
+                            <span>{matrix.likelihood?.length || 0}x{matrix.impact?.length || 0}</span>
                           </p>
                         </div>
                       </div>
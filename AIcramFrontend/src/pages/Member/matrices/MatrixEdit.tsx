@@ -2,24 +2,37 @@ import React, { useState } from 'react';
 import { useNavigate, useParams } from 'react-router-dom';
 import { toast } from 'react-toastify';
 import config from '../../../config';
-import { ChevronDown } from "lucide-react";
+
+interface MatrixLevel {
+  value?: number;
+  label: string;
+  description: string;
+  min?: number;
+  max?: number;
+}
+
+type Axis = 'impact' | 'likelihood';
 
 interface MatrixFormData {
   name: string;
-  type: 1 | 2 | 3;
   description: string;
-  impact_1: string;
-  impact_2: string;
-  impact_3: string;
-  impact_4: string;
-  impact_5: string;
-  likelihood_1: string;
-  likelihood_2: string;
-  likelihood_3: string;
-  likelihood_4: string;
-  likelihood_5: string;
+  impact: MatrixLevel[];
+  likelihood: MatrixLevel[];
 }
 
+const toLevels = (levels: any[] | null | undefined): MatrixLevel[] =>
+  (levels || []).map(level => ({ ...level, label: level.label || '', description: level.description || '' }));
+
+// Levels added after existing ones continue their numbering so stored scores keep pointing at the same level.
+const numberLevels = (levels: MatrixLevel[]) => {
+  let previous = 0;
+  return levels.map(level => {
+    const value = level.value !== undefined && level.value !== null && level.value > previous ? level.value : previous + 1;
+    previous = value;
+    return { ...level, value };
+  });
+};
+
 interface Assessment {
   assessment_id: string;
   matrix_id: string;
@@ -33,18 +46,9 @@ const MatrixEdit = () => {
 
   const [formData, setFormData] = React.useState<MatrixFormData>({
     name: '',
-    type: 1,
     description: '',
-    impact_1: '',
-    impact_2: '',
-    impact_3: '',
-    impact_4: '',
-    impact_5: '',
-    likelihood_1: '',
-    likelihood_2: '',
-    likelihood_3: '',
-    likelihood_4: '',
-    likelihood_5: ''
+    impact: [],
+    likelihood: []
   });
 
   const [errors, setErrors] = useState<{ [key: string]: string }>({});
@@ -57,43 +61,14 @@ const MatrixEdit = () => {
       newErrors.name = 'Name must be 2-100 characters';
     }
 
-    if (!formData.type || (formData.type !== 1 && formData.type !== 2 && formData.type !== 3)) {
-      newErrors.type = 'Type must be 3x3, 4x4, or 5x5';
-    }
-
-    if (formData.type === 1) {
-      if (!formData.impact_2) newErrors.impact_2 = 'Low impact description is required';
-      if (!formData.impact_3) newErrors.impact_3 = 'Medium impact description is required';
-      if (!formData.impact_4) newErrors.impact_4 = 'High impact description is required';
-    } else if (formData.type === 2) {
-      if (!formData.impact_2) newErrors.impact_2 = 'Low impact description is required';
-      if (!formData.impact_3) newErrors.impact_3 = 'Medium impact description is required';
-      if (!formData.impact_4) newErrors.impact_4 = 'High impact description is required';
-      if (!formData.impact_5) newErrors.impact_5 = 'Extreme impact description is required';
-    } else if (formData.type === 3) {
-      if (!formData.impact_1) newErrors.impact_1 = 'Very Low impact description is required';
-      if (!formData.impact_2) newErrors.impact_2 = 'Low impact description is required';
-      if (!formData.impact_3) newErrors.impact_3 = 'Medium impact description is required';
-      if (!formData.impact_4) newErrors.impact_4 = 'High impact description is required';
-      if (!formData.impact_5) newErrors.impact_5 = 'Extreme impact description is required';
-    }
-    
-    if (formData.type === 1) {
-      if (!formData.likelihood_2) newErrors.likelihood_2 = 'Low likelihood description is required';
-      if (!formData.likelihood_3) newErrors.likelihood_3 = 'Medium likelihood description is required';
-      if (!formData.likelihood_4) newErrors.likelihood_4 = 'High likelihood description is required';
-    } else if (formData.type === 2) {
-      if (!formData.likelihood_2) newErrors.likelihood_2 = 'Low likelihood description is required';
-      if (!formData.likelihood_3) newErrors.likelihood_3 = 'Medium likelihood description is required';
-      if (!formData.likelihood_4) newErrors.likelihood_4 = 'High likelihood description is required';
-      if (!formData.likelihood_5) newErrors.likelihood_5 = 'Extreme likelihood description is required';
-    } else if (formData.type === 3) {
-      if (!formData.likelihood_1) newErrors.likelihood_1 = 'Very Low likelihood description is required';
-      if (!formData.likelihood_2) newErrors.likelihood_2 = 'Low likelihood description is required';
-      if (!formData.likelihood_3) newErrors.likelihood_3 = 'Medium likelihood description is required';
-      if (!formData.likelihood_4) newErrors.likelihood_4 = 'High likelihood description is required';
-      if (!formData.likelihood_5) newErrors.likelihood_5 = 'Extreme likelihood description is required';
-    }
+    (['impact', 'likelihood'] as Axis[]).forEach(axis => {
+      const levels = formData[axis];
+      if (levels.length < 2 || levels.length > 10) {
+        newErrors[axis] = 'A matrix needs 2-10 levels per axis';
+      } else if (levels.some(level => !level.label.trim())) {
+        newErrors[axis] = 'Every level needs a label';
+      }
+    });
 
     setErrors(newErrors);
     return Object.keys(newErrors).length === 0;
@@ -144,18 +119,9 @@ const MatrixEdit = () => {
           
           setFormData({
             name: data.name,
-            type: data.type,
             description: data.description,
-            impact_1: data.impact_1,
-            impact_2: data.impact_2,
-            impact_3: data.impact_3,
-            impact_4: data.impact_4,
-            impact_5: data.impact_5,
-            likelihood_1: data.likelihood_1,
-            likelihood_2: data.likelihood_2,
-            likelihood_3: data.likelihood_3,
-            likelihood_4: data.likelihood_4,
-            likelihood_5: data.likelihood_5
+            impact: toLevels(data.impact),
+            likelihood: toLevels(data.likelihood)
           });
 
           await checkAssessments(matrix_id || '');
@@ -174,35 +140,10 @@ const MatrixEdit = () => {
 
     if (!validateForm()) return;
 
-    var dataImpact1;
-    var dataImpact5;
-    var dataLikelihood1;
-    var dataLikelihood5;
-
-    if (formData.type === 1) {
-      dataImpact1 = '';
-      dataImpact5 = '';
-      dataLikelihood1 = '';
-      dataLikelihood5 = '';
-    } else if (formData.type === 2) {
-      dataImpact1 = '';
-      dataImpact5 = formData.impact_5;
-      dataLikelihood1 = '';
-      dataLikelihood5 = formData.likelihood_5;
-    } else {
-      dataImpact1 = formData.impact_1;
-      dataImpact5 = formData.impact_5;
-      dataLikelihood1 = formData.likelihood_1;
-      dataLikelihood5 = formData.likelihood_5;
-    }
-
     const dataToSubmit = {
       ...formData,
-      status: 1,
-      impact_1: dataImpact1,
-      impact_5: dataImpact5,
-      likelihood_1: dataLikelihood1,
-      likelihood_5: dataLikelihood5
+      impact: numberLevels(formData.impact),
+      likelihood: numberLevels(formData.likelihood)
     };
 
     try {
@@ -229,75 +170,24 @@ const MatrixEdit = () => {
     }
   };
 
+  // Bands a cell by its position in the matrix, as the API does: the product of the impact and likelihood ranks
+  // divided by the largest product.
   const getRiskLevelAndColor = (impact: number, likelihood: number) => {
-    type RiskLevel = 'veryLow' | 'low' | 'medium' | 'high' | 'critical';
-    type RiskDefinition = { level: string; class: string };
-    
-    const riskDefinitions: Record<RiskLevel, RiskDefinition> = {
-      veryLow: { level: "Very Low", class: "bg-blue-900/50 text-blue-300" },
-      low: { level: "Low", class: "bg-green-900/50 text-green-300" },
-      medium: { level: "Medium", class: "bg-yellow-900/50 text-yellow-300" },
-      high: { level: "High", class: "bg-orange-900/50 text-orange-300" },
-      critical: { level: "Critical", class: "bg-red-900/50 text-red-300" }
-    };
-  
-    const key = `${impact},${likelihood}`;
-    
-    if (formData.type === 1) {
-      const matrix3x3: Record<string, RiskLevel> = {
-        "1,1": "low", "1,2": "low", "2,1": "low",
-        "1,3": "medium", "2,2": "medium", "3,1": "medium",
-        "2,3": "high", "3,2": "high", "3,3": "high"
-      };
-      return riskDefinitions[matrix3x3[key] || "medium"];
-    }
-    
-    else if (formData.type === 2) {
-      const matrix4x4: Record<string, RiskLevel> = {
-        "1,1": "low", "1,2": "low", "2,1": "low",
-        "1,3": "medium", "1,4": "medium", "2,2": "medium", "3,1": "medium", "4,1": "medium",
-        "2,3": "high", "2,4": "high", "3,2": "high", "3,3": "high", "4,2": "high",
-        "3,4": "critical", "4,3": "critical", "4,4": "critical"
-      };
-      return riskDefinitions[matrix4x4[key] || "medium"];
-    }
-    
-    else {
-      const matrix5x5: Record<string, RiskLevel> = {
-        "1,1": "veryLow", "1,2": "veryLow", "2,1": "veryLow",
-        "1,3": "low", "2,2": "low", "3,1": "low",
-        "1,4": "medium", "1,5": "medium", "2,3": "medium", "2,4": "medium",
-        "3,2": "medium", "3,3": "medium", "4,1": "medium", "4,2": "medium", "5,1": "medium",
-        "2,5": "high", "3,4": "high", "3,5": "high", "4,3": "high",
-        "4,4": "high", "5,2": "high", "5,3": "high",
-        "4,5": "critical", "5,4": "critical", "5,5": "critical"
-      };
-      return riskDefinitions[matrix5x5[key] || "medium"];
-    }
+    const position = (impact * likelihood) / Math.max(formData.impact.length * formData.likelihood.length, 1);
+    if (position < 0.2) return { level: "Low", class: "bg-green-900/50 text-green-300" };
+    if (position < 0.4) return { level: "Medium", class: "bg-yellow-900/50 text-yellow-300" };
+    if (position < 0.64) return { level: "High", class: "bg-orange-900/50 text-orange-300" };
+    return { level: "Critical", class: "bg-red-900/50 text-red-300" };
   };
 
-  const getMatrixLabels = () => {
-    if (formData.type === 1) {
-      return {
-        impact: ['Low', 'Medium', 'High'],
-        likelihood: ['High', 'Medium', 'Low']
-      };
-    } else if (formData.type === 2) {
-      return {
-        impact: ['Low', 'Medium', 'High', 'Extreme'],
-        likelihood: ['Extreme', 'High', 'Medium', 'Low']
-      };
-    } else {
-      return {
-        impact: ['Very Low', 'Low', 'Medium', 'High', 'Extreme'],
-        likelihood: ['Extreme', 'High', 'Medium', 'Low', 'Very Low']
-      };
-    }
-  };
+  const getMatrixLabels = () => ({
+    impact: formData.impact.map(level => level.label),
+    likelihood: formData.likelihood.map(level => level.label).reverse()
+  });
 
   const renderRiskMatrix = () => {
     const { impact, likelihood } = getMatrixLabels();
-    const size = formData.type === 1 ? 3 : formData.type === 2 ? 4 : 5;
+    const size = likelihood.length;
 
     return (
       <div>
@@ -358,12 +248,71 @@ const MatrixEdit = () => {
     );
   };
 
+  const updateLevel = (axis: Axis, index: number, changes: Partial<MatrixLevel>) => {
+    setFormData({
+      ...formData,
+      [axis]: formData[axis].map((level, i) => (i === index ? { ...level, ...changes } : level))
+    });
+  };
+
   const inputClass = "w-full text-white bg-gray-900 border border-gray-700 px-4 py-2.5 rounded-md focus:outline-none focus:ring-0 focus:ring-white focus:border-gray-400 transition duration-150";
 
   if (loading) {
     return <div className="p-6">Loading...</div>;
   }
 
+  const renderLevels = (axis: Axis) => {
+    const title = axis === 'impact' ? 'Impact' : 'Likelihood';
+    return (
+      <div key={axis}>
+        <div className="text-white font-medium text-lg mb-4">
+          {title} Definitions
+        </div>
+        <div className="grid grid-cols-1 gap-6 pb-10 border-b border-gray-700">
+          <p className="text-gray-300">Levels are listed from lowest to highest.</p>
+          {formData[axis].map((level, index) => (
+            <div key={index} className="space-y-3">
+              <div className="flex gap-3">
+                <input
+                  type="text"
+                  value={level.label}
+                  onChange={e => updateLevel(axis, index, { label: e.target.value })}
+                  className={inputClass}
+                  placeholder={`${title} level label`}
+                />
+                <button
+                  type="button"
+                  disabled={formData[axis].length <= 2}
+                  onClick={() => setFormData({ ...formData, [axis]: formData[axis].filter((_, i) => i !== index) })}
+                  className="bg-gray-800 hover:bg-gray-700 text-white px-4 py-2 rounded-md border border-gray-600 transition-all duration-200 disabled:opacity-50"
+                >
+                  Remove
+                </button>
+              </div>
+              <textarea
+                value={level.description}
+                onChange={e => updateLevel(axis, index, { description: e.target.value })}
+                className={`${inputClass} h-32 resize-none`}
+                placeholder={`Describe the ${level.label || 'new'} ${axis} level`}
+              />
+            </div>
+          ))}
+          <div>
+            <button
+              type="button"
+              disabled={formData[axis].length >= 10}
+              onClick={() => setFormData({ ...formData, [axis]: [...formData[axis], { label: '', description: '' }] })}
+              className="bg-gray-800 hover:bg-gray-700 text-white px-6 py-2 rounded-md border border-gray-600 transition-all duration-200 disabled:opacity-50"
+            >
+              Add {title} Level
+            </button>
+            {errors[axis] && <p className="text-red-400 text-sm mt-2">{errors[axis]}</p>}
+          </div>
+        </div>
+      </div>
+    );
+  };
+
   return (
     <div className="rounded-lg border border-gray-700 bg-gray-800 shadow-lg">
       <div className="border-b border-gray-700 px-6 py-4 lg:px-8 lg:py-6">
@@ -397,25 +346,6 @@ const MatrixEdit = () => {
                 {errors.name && <p className="text-red-400 text-sm mt-2">{errors.name}</p>}
               </div>
 
-              <div>
-                <label className="block text-gray-300 mb-2 text-sm">Type <span className="text-red-400">*</span></label>
-                <div className="relative">
-                  <select
-                    value={formData.type}
-                    onChange={e => setFormData({ ...formData, type: Number(e.target.value) as 1 | 2 | 3 })}
-                    className={inputClass + " appearance-none pr-10"}
-                  >
-                    <option value="1">3x3 Matrix</option>
-                    <option value="2">4x4 Matrix</option>
-                    <option value="3">5x5 Matrix</option>
-                  </select>
-
-                  <ChevronDown className="absolute right-3 top-1/2 transform -translate-y-1/2 text-gray-400 pointer-events-none" size={18} />
-                </div>
-
-                {errors.type && <p className="text-red-400 text-sm mt-2">{errors.type}</p>}
-              </div>
-              
               <div className="md:col-span-2">
                 <label className="block text-gray-300 mb-2 text-sm">Description</label>
                 <textarea
@@ -443,137 +373,7 @@ const MatrixEdit = () => {
             </div>
           </div>
 
-          <div>
-            <div className="text-white font-medium text-lg mb-4">
-              Impact Definitions
-            </div>
-            <div className="grid grid-cols-1 gap-6 pb-10 border-b border-gray-700">
-              {formData.type === 3 && (
-                <div>
-                  <label className="block text-gray-300 mb-2 text-sm">Impact - Very Low</label>
-                  <textarea
-                    value={formData.impact_1}
-                    onChange={e => setFormData({ ...formData, impact_1: e.target.value })}
-                    className={`${inputClass} h-32 resize-none`}
-                    placeholder="Describe the very low impact level"
-                  />
-                  {errors.impact_1 && <p className="text-red-400 text-sm mt-1">{errors.impact_1}</p>}
-                </div>
-              )}
-
-              <div>
-                <label className="block text-gray-300 mb-2 text-sm">Impact - Low</label>
-                <textarea
-                  value={formData.impact_2}
-                  onChange={e => setFormData({ ...formData, impact_2: e.target.value })}
-                  className={`${inputClass} h-32 resize-none`}
-                  placeholder="Describe the low impact level"
-                />
-                {errors.impact_2 && <p className="text-red-400 text-sm mt-1">{errors.impact_2}</p>}
-              </div>
-
-              <div>
-                <label className="block text-gray-300 mb-2 text-sm">Impact - Medium</label>
-                <textarea
-                  value={formData.impact_3}
-                  onChange={e => setFormData({ ...formData, impact_3: e.target.value })}
-                  className={`${inputClass} h-32 resize-none`}
-                  placeholder="Describe the medium impact level"
-                />
-                {errors.impact_3 && <p className="text-red-400 text-sm mt-1">{errors.impact_3}</p>}
-              </div>
-
-              <div>
-                <label className="block text-gray-300 mb-2 text-sm">Impact - High</label>
-                <textarea
-                  value={formData.impact_4}
-                  onChange={e => setFormData({ ...formData, impact_4: e.target.value })}
-                  className={`${inputClass} h-32 resize-none`}
-                  placeholder="Describe the high impact level"
-                />
-                {errors.impact_4 && <p className="text-red-400 text-sm mt-1">{errors.impact_4}</p>}
-              </div>
-
-              {(formData.type === 2 || formData.type === 3) && (
-                <div>
-                  <label className="block text-gray-300 mb-2 text-sm">Impact - Extreme</label>
-                  <textarea
-                    value={formData.impact_5}
-                    onChange={e => setFormData({ ...formData, impact_5: e.target.value })}
-                    className={`${inputClass} h-32 resize-none`}
-                    placeholder="Describe the extreme impact level"
-                  />
-                  {errors.impact_5 && <p className="text-red-400 text-sm mt-1">{errors.impact_5}</p>}
-                </div>
-              )}
-            </div>
-          </div>
-
-          <div>
-            <div className="text-white font-medium text-lg mb-4">
-              Likelihood Definitions
-            </div>
-            <div className="grid grid-cols-1 gap-6 pb-10 border-b border-gray-700">
-              {formData.type === 3 && (
-                <div>
-                  <label className="block text-gray-300 mb-2 text-sm">Likelihood - Very Low</label>
-                  <textarea
-                    value={formData.likelihood_1}
-                    onChange={e => setFormData({ ...formData, likelihood_1: e.target.value })}
-                    className={`${inputClass} h-32 resize-none`}
-                    placeholder="Describe the very low likelihood level"
-                  />
-                  {errors.likelihood_1 && <p className="text-red-400 text-sm mt-1">{errors.likelihood_1}</p>}
-                </div>
-              )}
-
-              <div>
-                <label className="block text-gray-300 mb-2 text-sm">Likelihood - Low</label>
-                <textarea
-                  value={formData.likelihood_2}
-                  onChange={e => setFormData({ ...formData, likelihood_2: e.target.value })}
-                  className={`${inputClass} h-32 resize-none`}
-                  placeholder="Describe the low likelihood level"
-                />
-                {errors.likelihood_2 && <p className="text-red-400 text-sm mt-1">{errors.likelihood_2}</p>}
-              </div>
-
-              <div>
-                <label className="block text-gray-300 mb-2 text-sm">Likelihood - Medium</label>
-                <textarea
-                  value={formData.likelihood_3}
-                  onChange={e => setFormData({ ...formData, likelihood_3: e.target.value })}
-                  className={`${inputClass} h-32 resize-none`}
-                  placeholder="Describe the medium likelihood level"
-                />
-                {errors.likelihood_3 && <p className="text-red-400 text-sm mt-1">{errors.likelihood_3}</p>}
-              </div>
-
-              <div>
-                <label className="block text-gray-300 mb-2 text-sm">Likelihood - High</label>
-                <textarea
-                  value={formData.likelihood_4}
-                  onChange={e => setFormData({ ...formData, likelihood_4: e.target.value })}
-                  className={`${inputClass} h-32 resize-none`}
-                  placeholder="Describe the high likelihood level"
-                />
-                {errors.likelihood_4 && <p className="text-red-400 text-sm mt-1">{errors.likelihood_4}</p>}
-              </div>
-
-              {(formData.type === 2 || formData.type === 3) && (
-                <div>
-                  <label className="block text-gray-300 mb-2 text-sm">Likelihood - Extreme</label>
-                  <textarea
-                    value={formData.likelihood_5}
-                    onChange={e => setFormData({ ...formData, likelihood_5: e.target.value })}
-                    className={`${inputClass} h-32 resize-none`}
-                    placeholder="Describe the extreme likelihood level"
-                  />
-                  {errors.likelihood_5 && <p className="text-red-400 text-sm mt-1">{errors.likelihood_5}</p>}
-                </div>
-              )}
-            </div>
-          </div>
+          {(['impact', 'likelihood'] as Axis[]).map(axis => renderLevels(axis))}
 
           <div className="flex gap-4">
             <button
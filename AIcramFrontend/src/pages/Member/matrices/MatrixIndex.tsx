@@ -6,7 +6,9 @@ import config from '../../../config';
 interface Matrix {
   matrix_id: string;
   name: string;
-  type: 1 | 2 | 3;
+  type: number | null;
+  impact: object[] | null;
+  likelihood: object[] | null;
 }
 
 interface Assessment {
@@ -208,13 +210,8 @@ const MatrixIndex = () => {
               <tr key={matrix.matrix_id} className="hover:bg-gray-900/50 transition-colors">
                 <td className="py-4 px-6 text-white">{matrix.name}</td>
                 <td className="py-4 px-6 text-gray-300">
-                  {matrix.type === 1 ? (
-                    <span>3x3</span>
-                  ) : matrix.type === 2 ? (
-                    <span>4x4</span>
-                  ) : matrix.type === 3 ? (
-                    <span>5x5</span>
-                  ) : null}
+                  <span>{matrix.likelihood?.length || 0}x{matrix.impact?.length || 0}</span>
+                  {matrix.type === 4 && <span> (Quantitative)</span>}
                 </td>
                 <td className="py-4 px-6">
                   <span className="bg-gray-900 text-gray-300 text-sm px-2 py-1 rounded">
@@ -4,21 +4,19 @@ import { format, formatDistanceToNow } from "date-fns";
 import { enUS } from "date-fns/locale";
 import config from '../../../config';
 
+interface MatrixLevel {
+  value: number;
+  label: string;
+  description: string | null;
+}
+
 interface Matrix {
   matrix_id: string;
   name: string;
-  type: 1 | 2 | 3;
+  type: number | null;
   description: string;
-  impact_1: string;
-  impact_2: string;
-  impact_3: string;
-  impact_4: string;
-  impact_5: string;
-  likelihood_1: string;
-  likelihood_2: string;
-  likelihood_3: string;
-  likelihood_4: string;
-  likelihood_5: string;
+  impact: MatrixLevel[];
+  likelihood: MatrixLevel[];
   created_at: string;
   updated_at: string;
 }
@@ -42,7 +40,7 @@ const MatrixView = () => {
 
         if (!response.ok) throw new Error('Failed to fetch matrix');
         const data = await response.json();
-        setMatrix(data);
+        setMatrix({ ...data, impact: data.impact || [], likelihood: data.likelihood || [] });
       } catch (err) {
         setError(err instanceof Error ? err.message : 'Failed to load matrix');
       } finally {
@@ -62,75 +60,24 @@ const MatrixView = () => {
   if (error) return <div>Error: {error}</div>;
   if (!matrix) return <div>Matrix not found</div>;
 
+  // Bands a cell by its position in the matrix, as the API does: the product of the impact and likelihood ranks
+  // divided by the largest product.
   const getRiskLevelAndColor = (impact: number, likelihood: number) => {
-    type RiskLevel = 'veryLow' | 'low' | 'medium' | 'high' | 'critical';
-    type RiskDefinition = { level: string; class: string };
-    
-    const riskDefinitions: Record<RiskLevel, RiskDefinition> = {
-      veryLow: { level: "Very Low", class: "bg-blue-900/50 text-blue-300" },
-      low: { level: "Low", class: "bg-green-900/50 text-green-300" },
-      medium: { level: "Medium", class: "bg-yellow-900/50 text-yellow-300" },
-      high: { level: "High", class: "bg-orange-900/50 text-orange-300" },
-      critical: { level: "Critical", class: "bg-red-900/50 text-red-300" }
-    };
-  
-    const key = `${impact},${likelihood}`;
-    
-    if (matrix.type === 1) {
-      const matrix3x3: Record<string, RiskLevel> = {
-        "1,1": "low", "1,2": "low", "2,1": "low",
-        "1,3": "medium", "2,2": "medium", "3,1": "medium",
-        "2,3": "high", "3,2": "high", "3,3": "high"
-      };
-      return riskDefinitions[matrix3x3[key] || "medium"];
-    }
-    
-    else if (matrix.type === 2) {
-      const matrix4x4: Record<string, RiskLevel> = {
-        "1,1": "low", "1,2": "low", "2,1": "low",
-        "1,3": "medium", "1,4": "medium", "2,2": "medium", "3,1": "medium", "4,1": "medium",
-        "2,3": "high", "2,4": "high", "3,2": "high", "3,3": "high", "4,2": "high",
-        "3,4": "critical", "4,3": "critical", "4,4": "critical"
-      };
-      return riskDefinitions[matrix4x4[key] || "medium"];
-    }
-    
-    else {
-      const matrix5x5: Record<string, RiskLevel> = {
-        "1,1": "veryLow", "1,2": "veryLow", "2,1": "veryLow",
-        "1,3": "low", "2,2": "low", "3,1": "low",
-        "1,4": "medium", "1,5": "medium", "2,3": "medium", "2,4": "medium",
-        "3,2": "medium", "3,3": "medium", "4,1": "medium", "4,2": "medium", "5,1": "medium",
-        "2,5": "high", "3,4": "high", "3,5": "high", "4,3": "high",
-        "4,4": "high", "5,2": "high", "5,3": "high",
-        "4,5": "critical", "5,4": "critical", "5,5": "critical"
-      };
-      return riskDefinitions[matrix5x5[key] || "medium"];
-    }
+    const position = (impact * likelihood) / Math.max(matrix.impact.length * matrix.likelihood.length, 1);
+    if (position < 0.2) return { level: "Low", class: "bg-green-900/50 text-green-300" };
+    if (position < 0.4) return { level: "Medium", class: "bg-yellow-900/50 text-yellow-300" };
+    if (position < 0.64) return { level: "High", class: "bg-orange-900/50 text-orange-300" };
+    return { level: "Critical", class: "bg-red-900/50 text-red-300" };
   };
 
-  const getMatrixLabels = () => {
-    if (matrix.type === 1) {
-      return {
-        impact: ['Low', 'Medium', 'High'],
-        likelihood: ['High', 'Medium', 'Low']
-      };
-    } else if (matrix.type === 2) {
-      return {
-        impact: ['Low', 'Medium', 'High', 'Extreme'],
-        likelihood: ['Extreme', 'High', 'Medium', 'Low']
-      };
-    } else {
-      return {
-        impact: ['Very Low', 'Low', 'Medium', 'High', 'Extreme'],
-        likelihood: ['Extreme', 'High', 'Medium', 'Low', 'Very Low']
-      };
-    }
-  };
+  const getMatrixLabels = () => ({
+    impact: matrix.impact.map(level => level.label),
+    likelihood: matrix.likelihood.map(level => level.label).reverse()
+  });
 
   const renderRiskMatrix = () => {
     const { impact, likelihood } = getMatrixLabels();
-    const size = matrix.type === 1 ? 3 : matrix.type === 2 ? 4 : 5;
+    const size = likelihood.length;
 
     return (
       <div>
@@ -203,13 +150,8 @@ const MatrixView = () => {
             <div>
               <p className="text-gray-400 text-sm mb-1">Type</p>
               <p className="text-white">
-                {matrix.type === 1 ? (
-                  <span>3x3</span>
-                ) : matrix.type === 2 ? (
-                  <span>4x4</span>
-                ) : matrix.type === 3 ? (
-                  <span>5x5</span>
-                ) : null}</p>
+                {matrix.likelihood.length}x{matrix.impact.length}
+                {matrix.type === 4 && <span> (Quantitative)</span>}</p>
             </div>
             <div>
               <p className="text-gray-400 text-sm mb-1">Description</p>
@@ -239,65 +181,21 @@ const MatrixView = () => {
           </div>
         </div>
 
-        <div className="rounded-lg border border-gray-700">
-          <h2 className="text-xl font-semibold text-white p-5 bg-gray-900 rounded-t-lg border-b border-gray-700">Impact Definitions</h2>
+        {(['impact', 'likelihood'] as const).map(axis => (
+        <div key={axis} className="rounded-lg border border-gray-700">
+          <h2 className="text-xl font-semibold text-white p-5 bg-gray-900 rounded-t-lg border-b border-gray-700">
+            {axis === 'impact' ? 'Impact' : 'Likelihood'} Definitions
+          </h2>
           <div className="p-5 bg-gray-900 rounded-b-lg space-y-5">
-            {matrix.type === 3 && (
-            <div>
-              <p className="text-gray-400 text-sm mb-1.5">Impact - Very Low</p>
-              <p className="text-gray-300 whitespace-pre-wrap">{matrix.impact_1}</p>
-            </div>
-            )}
-            <div>
-              <p className="text-gray-400 text-sm mb-1.5">Impact - Low</p>
-              <p className="text-gray-300 whitespace-pre-wrap">{matrix.impact_2}</p>
-            </div>
-            <div>
-              <p className="text-gray-400 text-sm mb-1.5">Impact - Medium</p>
-              <p className="text-gray-300 whitespace-pre-wrap">{matrix.impact_3}</p>
-            </div>
-            <div>
-              <p className="text-gray-400 text-sm mb-1.5">Impact - High</p>
-              <p className="text-gray-300 whitespace-pre-wrap">{matrix.impact_4}</p>
-            </div>
-            {(matrix.type === 2 || matrix.type === 3) && (
-            <div>
-              <p className="text-gray-400 text-sm mb-1.5">Impact - Extreme</p>
-              <p className="text-gray-300 whitespace-pre-wrap">{matrix.impact_5}</p>
-            </div>
-            )}
-          </div>
-        </div>
-
-        <div className="rounded-lg border border-gray-700">
-          <h2 className="text-xl font-semibold text-white p-5 bg-gray-900 rounded-t-lg border-b border-gray-700">Likelihood Definitions</h2>
-          <div className="p-5 bg-gray-900 rounded-b-lg space-y-5">
-            {matrix.type === 3 && (
-            <div>
-              <p className="text-gray-400 text-sm mb-1.5">Likelihood - Very Low</p>
-              <p className="text-gray-300 whitespace-pre-wrap">{matrix.likelihood_1}</p>
-            </div>
-            )}
-            <div>
-              <p className="text-gray-400 text-sm mb-1.5">Likelihood - Low</p>
-              <p className="text-gray-300 whitespace-pre-wrap">{matrix.likelihood_2}</p>
-            </div>
-            <div>
-              <p className="text-gray-400 text-sm mb-1.5">Likelihood - Medium</p>
-              <p className="text-gray-300 whitespace-pre-wrap">{matrix.likelihood_3}</p>
-            </div>
-            <div>
-              <p className="text-gray-400 text-sm mb-1.5">Likelihood - High</p>
-              <p className="text-gray-300 whitespace-pre-wrap">{matrix.likelihood_4}</p>
-            </div>
-            {(matrix.type === 2 || matrix.type === 3) && (
-            <div>
-              <p className="text-gray-400 text-sm mb-1.5">Likelihood - Extreme</p>
-              <p className="text-gray-300 whitespace-pre-wrap">{matrix.likelihood_5}</p>
+            {matrix[axis].map(level => (
+            <div key={level.value}>
+              <p className="text-gray-400 text-sm mb-1.5">{axis === 'impact' ? 'Impact' : 'Likelihood'} - {level.label}</p>
+              <p className="text-gray-300 whitespace-pre-wrap">{level.description}</p>
             </div>
-            )}
+            ))}
           </div>
         </div>
+        ))}
 
       </div>
     </div>